@@ -1,140 +1,365 @@
 package arb
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	arbExt     = ".arb"
-	localeAttr = "@@locale"
 	metaPrefix = "@"
+
+	// AttrPrefix is a prefix of global attributes of arb file
+	AttrPrefix       = "@@"
+	AttrLocale       = "@@locale"
+	AttrLastModified = "@@last_modified"
+
+	// metaState is a key of translation state in item metadata, it is written to arb files of all cultures.
+	metaState = "x-state"
 )
 
 var (
 	ErrArbFile = errors.New("invalid arb file error")
 )
 
-func SaveArb(logger *logrus.Logger,
-	arbData *Data,
-	arbFolderPath,
-	arbFileTemplate,
-	defaultCulture string) error {
-	if err := os.RemoveAll(arbFolderPath); err != nil {
-		return err
+type SaveParams struct {
+	FolderPath     string
+	FileTemplate   string
+	DefaultCulture string
+	Order          Order
+	// Prune removes files matching FileTemplate for cultures which are absent in data.
+	Prune bool
+	// StampLastModified writes current time to @@last_modified of changed files.
+	StampLastModified bool
+	// LocaleStyle defines how cultures are written to file names and @@locale.
+	LocaleStyle locale.Style
+}
+
+// SaveArb writes one arb file per culture. Only files matching FileTemplate are touched,
+// files with unchanged content are not rewritten. Keys absent in item cultures are not written.
+func SaveArb(logger *logrus.Logger, arbData *Data, saveParams SaveParams) error {
+	defaultCulture, err := locale.Canonical(saveParams.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
 	}
+	saveParams.DefaultCulture = defaultCulture
 
-	if err := os.MkdirAll(arbFolderPath, 0777); err != nil {
+	if err := os.MkdirAll(saveParams.FolderPath, 0777); err != nil {
 		return err
 	}
 
+	keys := arbData.OrderedKeys(saveParams.Order)
 	for _, cn := range arbData.Cultures {
-		m := make(map[string]interface{})
+		fileName := fileNameForCulture(saveParams.FileTemplate, locale.Format(cn, saveParams.LocaleStyle))
+		filePath := filepath.Join(saveParams.FolderPath, filepath.FromSlash(fileName))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+			return err
+		}
 
-		for name, item := range arbData.Items {
-			m[name] = item.Cultures[cn]
-			if cn != defaultCulture {
-				continue
-			}
+		lastModified := arbData.Attributes[cn][AttrLastModified]
+		if saveParams.StampLastModified {
+			// keep previous stamp if nothing else is changed
+			lastModified = readLastModified(filePath)
+		}
+
+		buf, err := marshalCulture(arbData, keys, cn, lastModified, saveParams)
+		if err != nil {
+			return err
+		}
 
-			meta := map[string]interface{}{
-				"description": item.Description,
+		if saveParams.StampLastModified {
+			if existing, err := ioutil.ReadFile(filePath); err != nil || !bytes.Equal(existing, buf) {
+				buf, err = marshalCulture(arbData, keys, cn, time.Now().UTC().Format(time.RFC3339), saveParams)
+				if err != nil {
+					return err
+				}
 			}
+		}
+
+		if err := WriteFileIfChanged(logger, filePath, buf); err != nil {
+			return err
+		}
+	}
+
+	if saveParams.Prune {
+		return pruneArb(logger, arbData, saveParams)
+	}
+	return nil
+}
+
+func marshalCulture(arbData *Data, keys []string, cn, lastModified string, saveParams SaveParams) ([]byte, error) {
+	entries := []jsonEntry{{key: AttrLocale, value: locale.Format(cn, saveParams.LocaleStyle)}}
+
+	attributes := arbData.Attributes[cn]
+	attrNames := make([]string, 0, len(attributes))
+	for name := range attributes {
+		if name != AttrLocale && name != AttrLastModified {
+			attrNames = append(attrNames, name)
+		}
+	}
+	sort.Strings(attrNames)
+	for _, name := range attrNames {
+		var value interface{} = attributes[name]
+		if arbData.RawAttributes[cn][name] && json.Valid([]byte(attributes[name])) {
+			value = json.RawMessage(attributes[name])
+		}
+		entries = append(entries, jsonEntry{key: name, value: value})
+	}
+	if lastModified != "" {
+		entries = append(entries, jsonEntry{key: AttrLastModified, value: lastModified})
+	}
+
+	for _, name := range keys {
+		item := arbData.Items[name]
+		value, ok := item.Cultures[cn]
+		if !ok {
+			// absent translations are omitted, see ApplyMissingPolicy
+			continue
+		}
+		entries = append(entries, jsonEntry{key: name, value: value})
+
+		meta := make(map[string]interface{})
+		if cn == saveParams.DefaultCulture {
+			meta["description"] = item.Description
 			if len(item.Parameters) > 0 {
-				placeholders := make(map[string]interface{})
-				for pn, _ := range item.Parameters {
-					placeholders[pn] = map[string]interface{}{
-						"type": "dynamic",
-					}
-				}
-				meta["placeholders"] = placeholders
+				meta["placeholders"] = item.Parameters
 			}
-			m[fmt.Sprintf("@%s", name)] = meta
 		}
+		if state := item.States[cn]; state != "" {
+			meta[metaState] = state
+		}
+		if len(meta) > 0 {
+			entries = append(entries, jsonEntry{key: metaPrefix + name, value: meta})
+		}
+	}
+
+	return marshalOrderedJSON(entries)
+}
+
+func readLastModified(filePath string) string {
+	rawData, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return ""
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(rawData, &data); err != nil {
+		return ""
+	}
+	return getStrByKey(AttrLastModified, data)
+}
 
-		buf, err := json.MarshalIndent(m, "", "  ")
+// pruneArb removes files matching template for cultures absent in data.
+func pruneArb(logger *logrus.Logger, arbData *Data, saveParams SaveParams) error {
+	files, err := findArbFiles(logger, os.DirFS(saveParams.FolderPath), saveParams.FileTemplate)
+	if err != nil {
+		return err
+	}
+
+	cultures := make(map[string]struct{}, len(arbData.Cultures))
+	for _, cn := range arbData.Cultures {
+		cultures[cn] = struct{}{}
+	}
+
+	for _, file := range files {
+		culture, err := locale.Canonical(file.culture)
 		if err != nil {
+			logger.Tracef("skip %s (%v)", file.relPath, err)
+			continue
+		}
+		if _, ok := cultures[culture]; ok {
+			continue
+		}
+		logger.Debugf("remove stale file %s", file.relPath)
+		if err := os.Remove(filepath.Join(saveParams.FolderPath, filepath.FromSlash(file.relPath))); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		if err := ioutil.WriteFile(path.Join(arbFolderPath, strings.ReplaceAll(arbFileTemplate, "{culture}", cn)), buf, 0666); err != nil {
-			return err
+// WriteFileIfChanged skips writing when file already has the same content, so its modification time is kept,
+// otherwise writes data to temp file and renames it to the target file, so interrupted write does not truncate it.
+func WriteFileIfChanged(logger *logrus.Logger, filePath string, data []byte) error {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(filePath); err == nil {
+		mode = fi.Mode().Perm()
+		existing, err := ioutil.ReadFile(filePath)
+		if err == nil && bytes.Equal(existing, data) {
+			logger.Tracef("file %s is unchanged", filePath)
+			return nil
 		}
 	}
 
-	return nil
+	tmp, err := ioutil.TempFile(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		// after successful rename there is nothing to remove
+		_ = os.Remove(tmpName)
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		return err
+	}
+
+	logger.Debugf("write file %s", filePath)
+	return os.Rename(tmpName, filePath)
 }
 
+// LoadArb loads arb files matching arbFileTemplate from arb folder,
+// empty template means all arb files of the folder.
 func LoadArb(logger *logrus.Logger,
 	arbFolderPath,
+	arbFileTemplate,
+	defaultCulture string) (*Data, error) {
+	return LoadArbFS(logger, os.DirFS(arbFolderPath), arbFileTemplate, defaultCulture)
+}
+
+// LoadArbFS loads arb files matching arbFileTemplate from root of fsys (use fs.Sub for subfolders).
+func LoadArbFS(logger *logrus.Logger,
+	fsys fs.FS,
+	arbFileTemplate,
 	defaultCulture string) (*Data, error) {
 
-	files, err := ioutil.ReadDir(arbFolderPath)
+	defaultCulture, err := locale.Canonical(defaultCulture)
+	if err != nil {
+		return nil, fmt.Errorf("default culture: %w", err)
+	}
+
+	files, err := findArbFiles(logger, fsys, arbFileTemplate)
 	if err != nil {
 		return nil, err
 	}
 
 	cultures := make(map[string]string)
+	culturesKeys := make(map[string][]string)
 	arbItems := make(map[string]*Item)
+	// attributes keeps only global attributes of files
+	attributes := &Data{Attributes: make(map[string]map[string]string)}
+	var arbOrphans map[string][]string
 
 	for _, file := range files {
-		if file.IsDir() || strings.ToLower(path.Ext(file.Name())) != arbExt {
-			logger.Tracef("skip %s", file.Name())
-			continue
-		}
-
-		logger.Tracef("process file %s", file.Name())
-		rawData, err := ioutil.ReadFile(path.Join(arbFolderPath, file.Name()))
+		fileName := file.relPath
+		logger.Tracef("process file %s", fileName)
+		rawData, err := fs.ReadFile(fsys, fileName)
 		if err != nil {
-			return nil, fmt.Errorf("file read error [%s]: %w", file.Name(), ErrArbFile)
+			return nil, fmt.Errorf("file read error [%s]: %w", fileName, ErrArbFile)
 		}
 
 		var data map[string]interface{}
 		if err := json.Unmarshal(rawData, &data); err != nil {
-			return nil, fmt.Errorf("file unmarshal error [%s]: %w", file.Name(), ErrArbFile)
+			return nil, fmt.Errorf("file unmarshal error [%s]: %w", fileName, ErrArbFile)
 		}
-		culture := getStrByKey(localeAttr, data)
-		if culture == "" {
-			culture = getCultureFromFileName(file.Name())
-		}
-		if culture == "" {
-			return nil, fmt.Errorf("can not detect culture for [%s]: %w", file.Name(), ErrArbFile)
+		culture, err := fileCulture(file, getStrByKey(AttrLocale, data), arbFileTemplate != "")
+		if err != nil {
+			return nil, fmt.Errorf("%v [%s]: %w", err, fileName, ErrArbFile)
 		}
-		culture = strings.ToLower(culture)
 
 		if f, ok := cultures[culture]; ok {
-			return nil, fmt.Errorf("same cultures in [%s] and [%s]: %w", f, file.Name(), ErrArbFile)
+			return nil, fmt.Errorf("same cultures in [%s] and [%s]: %w", f, fileName, ErrArbFile)
+		}
+		cultures[culture] = fileName
+		if culturesKeys[culture], err = readOrderedKeys(rawData); err != nil {
+			return nil, fmt.Errorf("file unmarshal error [%s]: %w", fileName, ErrArbFile)
+		}
+		if err := processCulture(culture, culture == defaultCulture, data, arbItems); err != nil {
+			return nil, fmt.Errorf("file [%s]: %w", fileName, err)
+		}
+		setAttributes(attributes, culture, data)
+		if orphans := orphanMeta(data); len(orphans) > 0 {
+			if arbOrphans == nil {
+				arbOrphans = make(map[string][]string)
+			}
+			arbOrphans[culture] = orphans
 		}
-		cultures[culture] = file.Name()
-		processCulture(culture, culture == strings.ToLower(defaultCulture), data, arbItems)
 	}
 
 	arbData := &Data{
-		Cultures: nil,
-		Items:    arbItems,
+		Cultures:      nil,
+		Items:         arbItems,
+		Attributes:    attributes.Attributes,
+		RawAttributes: attributes.RawAttributes,
+		OrphanMeta:    arbOrphans,
 	}
 	for c := range cultures {
 		arbData.Cultures = append(arbData.Cultures, c)
 	}
-	return arbData, err
+	arbData.Cultures = arbData.OrderedCultures(defaultCulture)
+
+	// keys of default culture go first, then keys which exist only in other cultures
+	for _, c := range arbData.Cultures {
+		for _, k := range culturesKeys[c] {
+			if !strings.HasPrefix(k, metaPrefix) {
+				arbData.Keys = append(arbData.Keys, k)
+			}
+		}
+	}
+	arbData.Keys = uniqueStrings(arbData.Keys)
+	return arbData, nil
+}
+
+// fileCulture returns canonical culture of arb file, @@locale takes precedence over culture from file name.
+// When file is found by template, @@locale must match culture from file name.
+func fileCulture(file arbFile, attrLocale string, byTemplate bool) (string, error) {
+	var fromName string
+	if file.culture != "" {
+		c, err := locale.Canonical(file.culture)
+		if err != nil && (attrLocale == "" || byTemplate) {
+			return "", fmt.Errorf("invalid culture %s in file name (%v)", file.culture, err)
+		}
+		fromName = c
+	}
+	if attrLocale == "" {
+		if fromName == "" {
+			return "", fmt.Errorf("can not detect culture")
+		}
+		return fromName, nil
+	}
+
+	culture, err := locale.Canonical(attrLocale)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %s (%v)", AttrLocale, attrLocale, err)
+	}
+	if byTemplate && culture != fromName {
+		return "", fmt.Errorf("%s %s does not match culture %s from file name", AttrLocale, attrLocale, file.culture)
+	}
+	return culture, nil
 }
 
 func processCulture(culture string,
 	isDefaultCulture bool,
 	data map[string]interface{},
-	arbItems map[string]*Item) {
+	arbItems map[string]*Item) error {
 	for k := range data {
 		if strings.HasPrefix(k, metaPrefix) {
 			continue
 		}
+		value := getStrByKey(k, data)
+		if err := icu.Validate(value); err != nil {
+			return fmt.Errorf("invalid message %s for culture %s (%v): %w", k, culture, err, ErrArbFile)
+		}
 		item, ok := arbItems[k]
 		if !ok {
 			item = &Item{
@@ -143,13 +368,98 @@ func processCulture(culture string,
 			}
 			arbItems[k] = item
 		}
-		item.Cultures[culture] = getStrByKey(k, data)
+		item.Cultures[culture] = value
+		item.SetState(culture, getStrByKey(metaState, getMapByKey(metaPrefix+k, data)))
 
 		if !isDefaultCulture {
 			continue
 		}
 		setMeta(k, item, data)
 	}
+	return nil
+}
+
+// orphanMeta returns sorted keys of @key metadata without message.
+func orphanMeta(data map[string]interface{}) []string {
+	var orphans []string
+	for k := range data {
+		if !strings.HasPrefix(k, metaPrefix) || strings.HasPrefix(k, AttrPrefix) {
+			continue
+		}
+		if _, ok := data[strings.TrimPrefix(k, metaPrefix)]; !ok {
+			orphans = append(orphans, strings.TrimPrefix(k, metaPrefix))
+		}
+	}
+	sort.Strings(orphans)
+	return orphans
+}
+
+// RemoveOrphanMeta removes @key metadata without message from arb files matching arbFileTemplate,
+// other entries are kept in the source order. It returns count of removed entries.
+func RemoveOrphanMeta(logger *logrus.Logger, arbFolderPath, arbFileTemplate string) (int, error) {
+	files, err := findArbFiles(logger, os.DirFS(arbFolderPath), arbFileTemplate)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, file := range files {
+		filePath := filepath.Join(arbFolderPath, filepath.FromSlash(file.relPath))
+		rawData, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return removed, err
+		}
+		var data map[string]json.RawMessage
+		if err := json.Unmarshal(rawData, &data); err != nil {
+			return removed, fmt.Errorf("file unmarshal error [%s]: %w", file.relPath, ErrArbFile)
+		}
+		keys, err := readOrderedKeys(rawData)
+		if err != nil {
+			return removed, fmt.Errorf("file unmarshal error [%s]: %w", file.relPath, ErrArbFile)
+		}
+
+		var entries []jsonEntry
+		for _, k := range keys {
+			if strings.HasPrefix(k, metaPrefix) && !strings.HasPrefix(k, AttrPrefix) {
+				if _, ok := data[strings.TrimPrefix(k, metaPrefix)]; !ok {
+					logger.Debugf("remove %s from %s", k, file.relPath)
+					removed++
+					continue
+				}
+			}
+			entries = append(entries, jsonEntry{key: k, value: data[k]})
+		}
+		if len(entries) == len(keys) {
+			continue
+		}
+
+		buf, err := marshalOrderedJSON(entries)
+		if err != nil {
+			return removed, err
+		}
+		if err := WriteFileIfChanged(logger, filePath, buf); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// setAttributes sets global attributes of culture except @@locale, non string values are set as raw json.
+func setAttributes(arbData *Data, culture string, data map[string]interface{}) {
+	for k, v := range data {
+		if !strings.HasPrefix(k, AttrPrefix) || k == AttrLocale {
+			continue
+		}
+		if s, ok := v.(string); ok {
+			arbData.SetAttribute(culture, k, s, false)
+			continue
+		}
+		buf, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		arbData.SetAttribute(culture, k, string(buf), true)
+	}
 }
 
 func setMeta(itemName string, item *Item, data map[string]interface{}) {
@@ -157,22 +467,13 @@ func setMeta(itemName string, item *Item, data map[string]interface{}) {
 	item.Description = getStrByKey("description", meta)
 	placeholders := getMapByKey("placeholders", meta)
 	if len(placeholders) > 0 {
-		item.Parameters = make(map[string]struct{})
+		item.Parameters = make(map[string]*Placeholder)
 		for k := range placeholders {
-			item.Parameters[k] = struct{}{}
+			item.Parameters[k] = placeholderFromMeta(getMapByKey(k, placeholders))
 		}
 	}
 }
 
-func getCultureFromFileName(name string) string {
-	nameWithoutExt := name[:len(name)-len(filepath.Ext(name))]
-	parts := strings.Split(nameWithoutExt, "_")
-	if len(parts) == 0 {
-		return ""
-	}
-	return parts[len(parts)-1]
-}
-
 func getStrByKey(k string, m map[string]interface{}) string {
 	v, ok := m[k]
 	if !ok {
@@ -190,3 +491,16 @@ func getMapByKey(k string, m map[string]interface{}) map[string]interface{} {
 	res, _ := v.(map[string]interface{})
 	return res
 }
+
+func uniqueStrings(src []string) []string {
+	res := make([]string, 0, len(src))
+	found := make(map[string]struct{}, len(src))
+	for _, s := range src {
+		if _, ok := found[s]; ok {
+			continue
+		}
+		found[s] = struct{}{}
+		res = append(res, s)
+	}
+	return res
+}
@@ -1,21 +1,29 @@
 package arb
 
 import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/evg1605/csv_arb/arb/locale"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 )
 
 func TestLoadArb(t *testing.T) {
-	arbData, err := LoadArb(createLogger(), "test_data", "en")
+	arbData, err := LoadArb(createLogger(), "test_data", "", "en")
 
 	require.NoError(t, err)
 	require.NotNil(t, arbData)
 
-	require.Len(t, arbData.Cultures, 2)
-	require.Contains(t, arbData.Cultures, "ru")
-	require.Contains(t, arbData.Cultures, "en")
+	require.Equal(t, []string{"en", "ru"}, arbData.Cultures)
+	require.Equal(t, []string{"aa1", "aa2", "aa3", "myName", "price", "aa4"}, arbData.Keys)
 
 	require.Contains(t, arbData.Items, "aa1")
 	require.Contains(t, arbData.Items, "aa2")
@@ -32,6 +40,292 @@ func TestLoadArb(t *testing.T) {
 	require.Len(t, arbData.Items["aa4"].Cultures, 1)
 	require.Empty(t, arbData.Items["aa3"].Parameters)
 
+	checkPricePlaceholders(t, arbData.Items["price"])
+
+	require.Equal(t, map[string]map[string]string{"en": {"@@author": "me", "@@x-count": "3", "@@x-generator": `{"name":"tool"}`}}, arbData.Attributes)
+	require.Equal(t, map[string]map[string]bool{"en": {"@@x-count": true, "@@x-generator": true}}, arbData.RawAttributes)
+}
+
+func TestSaveArb(t *testing.T) {
+	arbData, err := LoadArb(createLogger(), "test_data", "", "en")
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "arb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	saveParams := SaveParams{
+		FolderPath:     dir,
+		FileTemplate:   "app_{culture}.arb",
+		DefaultCulture: "en",
+		Order:          OrderSource,
+	}
+	arbData.Items["myName"].SetState("ru", StateFuzzy)
+	require.NoError(t, SaveArb(createLogger(), arbData, saveParams))
+	enData, err := ioutil.ReadFile(path.Join(dir, "app_en.arb"))
+	require.NoError(t, err)
+
+	// output must not depend on maps iteration order
+	require.NoError(t, SaveArb(createLogger(), arbData, saveParams))
+	enDataNext, err := ioutil.ReadFile(path.Join(dir, "app_en.arb"))
+	require.NoError(t, err)
+	require.Equal(t, string(enData), string(enDataNext))
+	require.Contains(t, string(enData), `"@@x-count": 3,`)
+	require.Contains(t, string(enData), `"@@x-generator": {
+    "name": "tool"
+  },`)
+
+	savedData, err := LoadArb(createLogger(), dir, "app_{culture}.arb", "en")
+	require.NoError(t, err)
+	require.Len(t, savedData.Cultures, 2)
+	require.Len(t, savedData.Items, len(arbData.Items))
+	require.Equal(t, arbData.Keys, savedData.Keys)
+	require.Equal(t, arbData.Attributes, savedData.Attributes)
+	require.Equal(t, arbData.RawAttributes, savedData.RawAttributes)
+	require.Equal(t, arbData.Items["myName"].Cultures, savedData.Items["myName"].Cultures)
+	require.Equal(t, map[string]string{"ru": StateFuzzy}, savedData.Items["myName"].States)
+	checkPricePlaceholders(t, savedData.Items["price"])
+}
+
+func TestSaveArbIncremental(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	otherFile := path.Join(dir, "l10n.yaml")
+	staleFile := path.Join(dir, "app_de.arb")
+	require.NoError(t, ioutil.WriteFile(otherFile, []byte("arb-dir: lib/l10n"), 0666))
+	require.NoError(t, ioutil.WriteFile(staleFile, []byte("{}"), 0666))
+
+	arbData := &Data{
+		Cultures: []string{"en", "ru"},
+		Items: map[string]*Item{
+			"a": {Cultures: map[string]string{"en": "en a", "ru": "ru a"}},
+		},
+	}
+	saveParams := SaveParams{
+		FolderPath:     dir,
+		FileTemplate:   "app_{culture}.arb",
+		DefaultCulture: "en",
+	}
+	require.NoError(t, SaveArb(createLogger(), arbData, saveParams))
+	require.FileExists(t, otherFile)
+	require.FileExists(t, staleFile)
+
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	enFile := path.Join(dir, "app_en.arb")
+	ruFile := path.Join(dir, "app_ru.arb")
+	require.NoError(t, os.Chtimes(enFile, old, old))
+	require.NoError(t, os.Chtimes(ruFile, old, old))
+
+	arbData.Items["a"].Cultures["ru"] = "new ru a"
+	saveParams.Prune = true
+	require.NoError(t, SaveArb(createLogger(), arbData, saveParams))
+
+	fi, err := os.Stat(enFile)
+	require.NoError(t, err)
+	require.Equal(t, old, fi.ModTime())
+	fi, err = os.Stat(ruFile)
+	require.NoError(t, err)
+	require.NotEqual(t, old, fi.ModTime())
+
+	require.FileExists(t, otherFile)
+	require.NoFileExists(t, staleFile)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	ruData, err := ioutil.ReadFile(ruFile)
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "@@locale": "ru",
+  "a": "new ru a"
+}
+`, string(ruData))
+
+	// last modified is stamped only in changed files
+	saveParams.StampLastModified = true
+	arbData.Items["a"].Cultures["ru"] = "ru a"
+	require.NoError(t, SaveArb(createLogger(), arbData, saveParams))
+	require.Empty(t, readLastModified(enFile))
+	stamped := readLastModified(ruFile)
+	require.NotEmpty(t, stamped)
+	require.NoError(t, os.Chtimes(ruFile, old, old))
+
+	require.NoError(t, SaveArb(createLogger(), arbData, saveParams))
+	require.Equal(t, stamped, readLastModified(ruFile))
+	fi, err = os.Stat(ruFile)
+	require.NoError(t, err)
+	require.Equal(t, old, fi.ModTime())
+}
+
+func TestLoadArbByTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	arbData := &Data{
+		Cultures: []string{"en", "pt-BR"},
+		Items: map[string]*Item{
+			"a": {Cultures: map[string]string{"en": "en a", "pt-BR": "pt a"}},
+		},
+	}
+	for _, template := range []string{"app_{culture}.arb", "{culture}/strings.arb"} {
+		t.Run(template, func(t *testing.T) {
+			saveParams := SaveParams{
+				FolderPath:     dir,
+				FileTemplate:   template,
+				DefaultCulture: "en",
+			}
+			require.NoError(t, SaveArb(createLogger(), arbData, saveParams))
+
+			loaded, err := LoadArb(createLogger(), dir, template, "en")
+			require.NoError(t, err)
+			require.Equal(t, arbData.Cultures, loaded.Cultures)
+			require.Equal(t, arbData.Items["a"].Cultures, loaded.Items["a"].Cultures)
+		})
+	}
+	require.FileExists(t, path.Join(dir, "pt_BR", "strings.arb"))
+
+	// cultures from file names and @@locale are normalized
+	loaded, err := LoadArb(createLogger(), dir, "", "EN")
+	require.NoError(t, err)
+	require.Equal(t, arbData.Cultures, loaded.Cultures)
+
+	require.NoError(t, SaveArb(createLogger(), arbData, SaveParams{
+		FolderPath:     dir,
+		FileTemplate:   "intl_{culture}.arb",
+		DefaultCulture: "en",
+		LocaleStyle:    locale.StyleBCP47,
+	}))
+	ptData, err := ioutil.ReadFile(path.Join(dir, "intl_pt-BR.arb"))
+	require.NoError(t, err)
+	require.Contains(t, string(ptData), `"@@locale": "pt-BR"`)
+
+	require.NoError(t, ioutil.WriteFile(path.Join(dir, "app_de.arb"), []byte(`{"@@locale": "fr"}`), 0666))
+	_, err = LoadArb(createLogger(), dir, "app_{culture}.arb", "en")
+	require.ErrorIs(t, err, ErrArbFile)
+
+	require.NoError(t, ioutil.WriteFile(path.Join(dir, "app_de.arb"), []byte(`{"@@locale": "xx"}`), 0666))
+	_, err = LoadArb(createLogger(), dir, "app_{culture}.arb", "en")
+	require.ErrorIs(t, err, ErrArbFile)
+}
+
+// unreadableFS fails to open folder, other files are read from FS.
+type unreadableFS struct {
+	fs.FS
+	folder string
+}
+
+func (f unreadableFS) Open(name string) (fs.File, error) {
+	if name == f.folder {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("permission denied")}
+	}
+	return f.FS.Open(name)
+}
+
+func TestLoadArbSkipsDeepFolders(t *testing.T) {
+	files := fstest.MapFS{
+		"app_en.arb":          {Data: []byte(`{"a": "A"}`)},
+		"app_ru.arb":          {Data: []byte(`{"a": "А"}`)},
+		"build/app_de.arb":    {Data: []byte(`{"a": "A"}`)},
+		"en/strings.arb":      {Data: []byte(`{"b": "B"}`)},
+		"en/cache/app_fr.arb": {Data: []byte(`{"b": "B"}`)},
+	}
+	loaded, err := LoadArbFS(createLogger(), unreadableFS{FS: files, folder: "build"}, "app_{culture}.arb", "en")
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "ru"}, loaded.Cultures)
+
+	loaded, err = LoadArbFS(createLogger(), unreadableFS{FS: files, folder: "en/cache"}, "{culture}/strings.arb", "en")
+	require.NoError(t, err)
+	require.Equal(t, []string{"en"}, loaded.Cultures)
+	require.Equal(t, []string{"b"}, loaded.Keys)
+}
+
+func TestGetCultureFromFileName(t *testing.T) {
+	require.Equal(t, "en", getCultureFromFileName("en.arb"))
+	require.Equal(t, "en", getCultureFromFileName("app_en.arb"))
+	require.Equal(t, "pt_BR", getCultureFromFileName("app_pt_BR.arb"))
+	require.Equal(t, "zh_Hant_TW", getCultureFromFileName("intl_messages_zh_Hant_TW.arb"))
+	require.Equal(t, "strings", getCultureFromFileName("app_strings.arb"))
+}
+
+func checkPricePlaceholders(t *testing.T, item *Item) {
+	require.NotNil(t, item)
+	require.Len(t, item.Parameters, 2)
+	require.Equal(t, &Placeholder{
+		Type:               "double",
+		Format:             "compactCurrency",
+		Example:            "$1.2M",
+		OptionalParameters: map[string]interface{}{"decimalDigits": float64(2)},
+	}, item.Parameters["value"])
+	require.Equal(t, &Placeholder{
+		Type:               "DateTime",
+		Format:             "EEE, M/d/y",
+		IsCustomDateFormat: true,
+	}, item.Parameters["date"])
+}
+
+func TestPlaceholderExample(t *testing.T) {
+	var parameters map[string]*Placeholder
+	require.NoError(t, json.Unmarshal([]byte(`{"n":{"example":1000000},"f":{"example":0.25},"s":{"example":"1000000"}}`), &parameters))
+	require.Equal(t, "1000000", parameters["n"].Example)
+	require.Equal(t, "0.25", parameters["f"].Example)
+	require.Equal(t, "1000000", parameters["s"].Example)
+
+	buf, err := json.Marshal(parameters)
+	require.NoError(t, err)
+	require.Equal(t, `{"f":{"example":0.25},"n":{"example":1000000},"s":{"example":"1000000"}}`, string(buf))
+}
+
+func TestRemoveOrphanMeta(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	enFile := path.Join(dir, "app_en.arb")
+	ruFile := path.Join(dir, "app_ru.arb")
+	require.NoError(t, ioutil.WriteFile(enFile, []byte(`{
+  "@@locale": "en",
+  "b": "B",
+  "@b": {"description": "b", "placeholders": {"z": {}, "a": {}}},
+  "@removed": {"description": "removed key"},
+  "a": "A"
+}`), 0666))
+	ruContent := []byte(`{"@@locale": "ru", "b": "Б"}`)
+	require.NoError(t, ioutil.WriteFile(ruFile, ruContent, 0666))
+
+	arbData, err := LoadArb(createLogger(), dir, "app_{culture}.arb", "en")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{"en": {"removed"}}, arbData.OrphanMeta)
+
+	removed, err := RemoveOrphanMeta(createLogger(), dir, "app_{culture}.arb")
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	buf, err := ioutil.ReadFile(enFile)
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "@@locale": "en",
+  "b": "B",
+  "@b": {
+    "description": "b",
+    "placeholders": {
+      "z": {},
+      "a": {}
+    }
+  },
+  "a": "A"
+}
+`, string(buf))
+	buf, err = ioutil.ReadFile(ruFile)
+	require.NoError(t, err)
+	require.Equal(t, ruContent, buf)
+
+	arbData, err = LoadArb(createLogger(), dir, "app_{culture}.arb", "en")
+	require.NoError(t, err)
+	require.Nil(t, arbData.OrphanMeta)
 }
 
 func createLogger() *logrus.Logger {
@@ -0,0 +1,150 @@
+package arb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/sirupsen/logrus"
+)
+
+// CheckMode defines what to do with check findings.
+type CheckMode string
+
+const (
+	CheckOff     CheckMode = "off"
+	CheckWarning CheckMode = "warning"
+	CheckError   CheckMode = "error"
+)
+
+// Kinds of placeholder issues.
+const (
+	// IssueUndeclared - placeholder is used in message, but not declared in item parameters.
+	IssueUndeclared = "undeclared"
+	// IssueMissing - declared placeholder is not used in message of culture.
+	IssueMissing = "missing"
+	// IssuePartial - undeclared placeholder is used only by some cultures.
+	IssuePartial = "partial"
+)
+
+var (
+	ErrInvalidCheckMode = errors.New("invalid check mode")
+	ErrPlaceholders     = errors.New("placeholders check failed")
+)
+
+type PlaceholderIssue struct {
+	Kind        string
+	Key         string
+	Culture     string
+	Placeholder string
+}
+
+func (i *PlaceholderIssue) String() string {
+	switch i.Kind {
+	case IssueUndeclared:
+		return fmt.Sprintf("key %s, culture %s: placeholder {%s} is not declared", i.Key, i.Culture, i.Placeholder)
+	case IssueMissing:
+		return fmt.Sprintf("key %s, culture %s: declared placeholder {%s} is not used", i.Key, i.Culture, i.Placeholder)
+	default:
+		return fmt.Sprintf("key %s, culture %s: placeholder {%s} is used by other cultures, but not by this one", i.Key, i.Culture, i.Placeholder)
+	}
+}
+
+func ParseCheckMode(s string) (CheckMode, error) {
+	switch m := CheckMode(strings.ToLower(s)); m {
+	case CheckOff, CheckWarning, CheckError:
+		return m, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidCheckMode)
+}
+
+// CheckPlaceholders compares placeholders used in every non empty culture message with declared item parameters.
+func CheckPlaceholders(arbData *Data) []*PlaceholderIssue {
+	var issues []*PlaceholderIssue
+
+	for key, item := range arbData.Items {
+		used := make(map[string]map[string]struct{})
+		for culture, value := range item.Cultures {
+			if value == "" {
+				continue
+			}
+			m, err := icu.Parse(value)
+			if err != nil {
+				// syntax errors are reported by loaders
+				continue
+			}
+			used[culture] = m.Arguments()
+		}
+
+		// declared placeholders must be used by every culture
+		for pName := range item.Parameters {
+			for culture, args := range used {
+				if _, ok := args[pName]; !ok {
+					issues = append(issues, &PlaceholderIssue{Kind: IssueMissing, Key: key, Culture: culture, Placeholder: pName})
+				}
+			}
+		}
+
+		// undeclared placeholders: every usage and every culture without usage
+		undeclared := make(map[string]struct{})
+		for culture, args := range used {
+			for pName := range args {
+				if _, ok := item.Parameters[pName]; ok {
+					continue
+				}
+				undeclared[pName] = struct{}{}
+				issues = append(issues, &PlaceholderIssue{Kind: IssueUndeclared, Key: key, Culture: culture, Placeholder: pName})
+			}
+		}
+		for pName := range undeclared {
+			for culture, args := range used {
+				if _, ok := args[pName]; !ok {
+					issues = append(issues, &PlaceholderIssue{Kind: IssuePartial, Key: key, Culture: culture, Placeholder: pName})
+				}
+			}
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Culture != b.Culture {
+			return a.Culture < b.Culture
+		}
+		if a.Placeholder != b.Placeholder {
+			return a.Placeholder < b.Placeholder
+		}
+		return a.Kind < b.Kind
+	})
+	return issues
+}
+
+// ApplyPlaceholdersCheck runs CheckPlaceholders and logs issues as warnings or returns them as error depending on mode.
+func ApplyPlaceholdersCheck(logger *logrus.Logger, arbData *Data, mode CheckMode) error {
+	if mode == CheckOff {
+		return nil
+	}
+
+	issues := CheckPlaceholders(arbData)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	if mode == CheckWarning {
+		for _, i := range issues {
+			logger.Warningln(i)
+		}
+		return nil
+	}
+
+	sb := &strings.Builder{}
+	for _, i := range issues {
+		sb.WriteString("\n")
+		sb.WriteString(i.String())
+	}
+	return fmt.Errorf("%w, %d issue(s):%s", ErrPlaceholders, len(issues), sb.String())
+}
@@ -0,0 +1,43 @@
+package arb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPlaceholders(t *testing.T) {
+	arbData := &Data{
+		Cultures: []string{"en", "ru", "de"},
+		Items: map[string]*Item{
+			"ok": {
+				Cultures:   map[string]string{"en": "from {min} to {max}", "ru": "от {min} до {max}", "de": ""},
+				Parameters: map[string]*Placeholder{"min": {}, "max": {}},
+			},
+			"renamed": {
+				Cultures:   map[string]string{"en": "from {min}", "ru": "от {мин}", "de": "von {min}"},
+				Parameters: map[string]*Placeholder{"min": {}},
+			},
+			"plural": {
+				Cultures: map[string]string{"en": "{count, plural, one{# item} other{# items}}", "ru": "{count, plural, one{# шт} other{# шт}}"},
+			},
+		},
+	}
+
+	issues := CheckPlaceholders(arbData)
+	require.Equal(t, []*PlaceholderIssue{
+		{Kind: IssueUndeclared, Key: "plural", Culture: "en", Placeholder: "count"},
+		{Kind: IssueUndeclared, Key: "plural", Culture: "ru", Placeholder: "count"},
+		{Kind: IssuePartial, Key: "renamed", Culture: "de", Placeholder: "мин"},
+		{Kind: IssuePartial, Key: "renamed", Culture: "en", Placeholder: "мин"},
+		{Kind: IssueMissing, Key: "renamed", Culture: "ru", Placeholder: "min"},
+		{Kind: IssueUndeclared, Key: "renamed", Culture: "ru", Placeholder: "мин"},
+	}, issues)
+
+	require.NoError(t, ApplyPlaceholdersCheck(createLogger(), arbData, CheckWarning))
+	require.ErrorIs(t, ApplyPlaceholdersCheck(createLogger(), arbData, CheckError), ErrPlaceholders)
+	require.NoError(t, ApplyPlaceholdersCheck(createLogger(), arbData, CheckOff))
+
+	_, err := ParseCheckMode("fatal")
+	require.ErrorIs(t, err, ErrInvalidCheckMode)
+}
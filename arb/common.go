@@ -1,12 +1,149 @@
 package arb
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
 type Data struct {
 	Cultures []string
 	Items    map[string]*Item
+	// Keys keeps source order of items keys
+	Keys []string
+	// Attributes keeps global attributes (@@author, @@context, @@x-...) of every culture: culture -> name -> value.
+	Attributes map[string]map[string]string
+	// RawAttributes marks attributes which values are json (numbers, booleans, objects, arrays), they are
+	// written unquoted: culture -> name -> true.
+	RawAttributes map[string]map[string]bool
+	// OrphanMeta keeps keys of @key metadata without message of every culture loaded from arb files: culture -> keys.
+	OrphanMeta map[string][]string
 }
 
+// SetAttribute sets global attribute of culture, raw value is json which is written unquoted.
+func (d *Data) SetAttribute(culture, name, value string, raw bool) {
+	if d.Attributes == nil {
+		d.Attributes = make(map[string]map[string]string)
+	}
+	if d.Attributes[culture] == nil {
+		d.Attributes[culture] = make(map[string]string)
+	}
+	d.Attributes[culture][name] = value
+
+	if !raw {
+		delete(d.RawAttributes[culture], name)
+		return
+	}
+	if d.RawAttributes == nil {
+		d.RawAttributes = make(map[string]map[string]bool)
+	}
+	if d.RawAttributes[culture] == nil {
+		d.RawAttributes[culture] = make(map[string]bool)
+	}
+	d.RawAttributes[culture][name] = true
+}
+
+const (
+	// StateFuzzy marks translation which needs review (gettext fuzzy flag).
+	StateFuzzy = "fuzzy"
+	// StateMachine marks machine translation, it needs review too.
+	StateMachine = "machine"
+)
+
 type Item struct {
 	Description string
 	Cultures    map[string]string
-	Parameters  map[string]struct{}
+	Parameters  map[string]*Placeholder
+	// States keeps translation states of cultures (StateFuzzy, StateMachine), cultures without state are absent.
+	States map[string]string
+}
+
+// SetState sets translation state of culture, empty state removes it.
+func (i *Item) SetState(culture, state string) {
+	if state == "" {
+		delete(i.States, culture)
+		return
+	}
+	if i.States == nil {
+		i.States = make(map[string]string)
+	}
+	i.States[culture] = state
+}
+
+// Placeholder is a placeholder declaration from the "placeholders" section of arb item metadata.
+type Placeholder struct {
+	Type               string
+	Format             string
+	Example            string
+	OptionalParameters map[string]interface{}
+	IsCustomDateFormat bool
+	// numericExample keeps example from json number written back as number.
+	numericExample bool
+}
+
+type placeholderJSON struct {
+	Type               string                 `json:"type,omitempty"`
+	Format             string                 `json:"format,omitempty"`
+	Example            interface{}            `json:"example,omitempty"`
+	OptionalParameters map[string]interface{} `json:"optionalParameters,omitempty"`
+	IsCustomDateFormat interface{}            `json:"isCustomDateFormat,omitempty"`
+}
+
+// IsEmpty reports whether placeholder has no attributes except name.
+func (p *Placeholder) IsEmpty() bool {
+	return p.Type == "" &&
+		p.Format == "" &&
+		p.Example == "" &&
+		len(p.OptionalParameters) == 0 &&
+		!p.IsCustomDateFormat
+}
+
+func (p *Placeholder) MarshalJSON() ([]byte, error) {
+	pj := placeholderJSON{
+		Type:               p.Type,
+		Format:             p.Format,
+		OptionalParameters: p.OptionalParameters,
+	}
+	if p.numericExample {
+		pj.Example = json.Number(p.Example)
+	} else if p.Example != "" {
+		pj.Example = p.Example
+	}
+	if p.IsCustomDateFormat {
+		// gen-l10n documents isCustomDateFormat as string value
+		pj.IsCustomDateFormat = "true"
+	}
+	return json.Marshal(pj)
+}
+
+func (p *Placeholder) UnmarshalJSON(b []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*p = *placeholderFromMeta(m)
+	return nil
+}
+
+func placeholderFromMeta(meta map[string]interface{}) *Placeholder {
+	p := &Placeholder{
+		Type:               getStrByKey("type", meta),
+		Format:             getStrByKey("format", meta),
+		OptionalParameters: getMapByKey("optionalParameters", meta),
+	}
+	switch v := meta["example"].(type) {
+	case nil:
+	case float64:
+		p.Example = strconv.FormatFloat(v, 'f', -1, 64)
+		p.numericExample = true
+	default:
+		p.Example = fmt.Sprint(v)
+	}
+	switch v := meta["isCustomDateFormat"].(type) {
+	case bool:
+		p.IsCustomDateFormat = v
+	case string:
+		p.IsCustomDateFormat = v == "true"
+	}
+	return p
 }
@@ -0,0 +1,281 @@
+package arb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DiffFormat is an output format of WriteDiff.
+type DiffFormat string
+
+const (
+	DiffText     DiffFormat = "text"
+	DiffJSON     DiffFormat = "json"
+	DiffMarkdown DiffFormat = "markdown"
+)
+
+// Kinds of changes.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Changed parts of key.
+const (
+	FieldKey         = "key"
+	FieldDescription = "description"
+	FieldParameters  = "parameters"
+	FieldMessage     = "message"
+)
+
+var fieldsOrder = map[string]int{FieldKey: 0, FieldDescription: 1, FieldParameters: 2, FieldMessage: 3}
+
+var (
+	ErrInvalidDiffFormat = errors.New("invalid diff format")
+	ErrDiff              = errors.New("data differ")
+)
+
+// Change is a difference of key between old and new data. Culture is set for changes of messages only,
+// empty messages are the same as absent ones.
+type Change struct {
+	Kind    string `json:"kind"`
+	Field   string `json:"field"`
+	Key     string `json:"key"`
+	Culture string `json:"culture,omitempty"`
+	Old     string `json:"old"`
+	New     string `json:"new"`
+}
+
+func (c *Change) String() string {
+	switch {
+	case c.Field == FieldKey:
+		return fmt.Sprintf("key %s: %s", c.Key, c.Kind)
+	case c.Field != FieldMessage:
+		return fmt.Sprintf("key %s: %s %q -> %q", c.Key, c.Field, c.Old, c.New)
+	case c.Kind == ChangeAdded:
+		return fmt.Sprintf("key %s, culture %s: added %q", c.Key, c.Culture, c.New)
+	case c.Kind == ChangeRemoved:
+		return fmt.Sprintf("key %s, culture %s: removed %q", c.Key, c.Culture, c.Old)
+	default:
+		return fmt.Sprintf("key %s, culture %s: %q -> %q", c.Key, c.Culture, c.Old, c.New)
+	}
+}
+
+// DiffSummary counts changes of messages of culture.
+type DiffSummary struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+func ParseDiffFormat(s string) (DiffFormat, error) {
+	switch f := DiffFormat(strings.ToLower(s)); f {
+	case DiffText, DiffJSON, DiffMarkdown:
+		return f, nil
+	case "md":
+		return DiffMarkdown, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidDiffFormat)
+}
+
+// Diff returns added and removed keys, changed descriptions and parameters of keys and added, removed
+// and changed messages of every culture. Global attributes are not compared.
+func Diff(oldData, newData *Data) []*Change {
+	keys := make(map[string]struct{}, len(newData.Items))
+	for key := range oldData.Items {
+		keys[key] = struct{}{}
+	}
+	for key := range newData.Items {
+		keys[key] = struct{}{}
+	}
+
+	var changes []*Change
+	for key := range keys {
+		oldItem, newItem := oldData.Items[key], newData.Items[key]
+		switch {
+		case oldItem == nil:
+			changes = append(changes, &Change{Kind: ChangeAdded, Field: FieldKey, Key: key})
+			oldItem = &Item{}
+		case newItem == nil:
+			changes = append(changes, &Change{Kind: ChangeRemoved, Field: FieldKey, Key: key})
+			newItem = &Item{}
+		default:
+			if oldItem.Description != newItem.Description {
+				changes = append(changes, &Change{Kind: ChangeChanged, Field: FieldDescription, Key: key,
+					Old: oldItem.Description, New: newItem.Description})
+			}
+			oldParameters, newParameters := diffParameters(oldItem.Parameters), diffParameters(newItem.Parameters)
+			if oldParameters != newParameters {
+				changes = append(changes, &Change{Kind: ChangeChanged, Field: FieldParameters, Key: key,
+					Old: oldParameters, New: newParameters})
+			}
+		}
+
+		cultures := make(map[string]struct{}, len(newItem.Cultures))
+		for c := range oldItem.Cultures {
+			cultures[c] = struct{}{}
+		}
+		for c := range newItem.Cultures {
+			cultures[c] = struct{}{}
+		}
+		for c := range cultures {
+			change := &Change{Field: FieldMessage, Key: key, Culture: c, Old: oldItem.Cultures[c], New: newItem.Cultures[c]}
+			switch {
+			case change.Old == change.New:
+				continue
+			case change.Old == "":
+				change.Kind = ChangeAdded
+			case change.New == "":
+				change.Kind = ChangeRemoved
+			default:
+				change.Kind = ChangeChanged
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Field != b.Field {
+			return fieldsOrder[a.Field] < fieldsOrder[b.Field]
+		}
+		return a.Culture < b.Culture
+	})
+	return changes
+}
+
+// diffParameters returns parameters as json object with sorted names, empty string if there are no parameters.
+func diffParameters(parameters map[string]*Placeholder) string {
+	if len(parameters) == 0 {
+		return ""
+	}
+	normalized := make(map[string]*Placeholder, len(parameters))
+	for name, p := range parameters {
+		if p == nil {
+			p = &Placeholder{}
+		}
+		normalized[name] = p
+	}
+	buf, err := json.Marshal(normalized)
+	if err != nil {
+		return fmt.Sprint(parameters)
+	}
+	return string(buf)
+}
+
+// SummarizeDiff counts changes of messages by cultures.
+func SummarizeDiff(changes []*Change) map[string]*DiffSummary {
+	summary := make(map[string]*DiffSummary)
+	for _, c := range changes {
+		if c.Field != FieldMessage {
+			continue
+		}
+		s := summary[c.Culture]
+		if s == nil {
+			s = &DiffSummary{}
+			summary[c.Culture] = s
+		}
+		switch c.Kind {
+		case ChangeAdded:
+			s.Added++
+		case ChangeRemoved:
+			s.Removed++
+		default:
+			s.Changed++
+		}
+	}
+	return summary
+}
+
+// WriteDiff writes changes in format, text lines are colored by kind of change if color is set.
+func WriteDiff(w io.Writer, changes []*Change, format DiffFormat, color bool) error {
+	summary := SummarizeDiff(changes)
+	cultures := make([]string, 0, len(summary))
+	for c := range summary {
+		cultures = append(cultures, c)
+	}
+	sort.Strings(cultures)
+
+	switch format {
+	case DiffJSON:
+		if changes == nil {
+			changes = []*Change{}
+		}
+		buf, err := json.MarshalIndent(struct {
+			Changes  []*Change               `json:"changes"`
+			Cultures map[string]*DiffSummary `json:"cultures"`
+		}{changes, summary}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", buf)
+		return err
+	case DiffMarkdown:
+		return writeDiffMarkdown(w, changes, cultures, summary)
+	}
+	return writeDiffText(w, changes, cultures, summary, color)
+}
+
+func writeDiffText(w io.Writer, changes []*Change, cultures []string, summary map[string]*DiffSummary, color bool) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "no differences")
+		return err
+	}
+
+	marks := map[string]string{ChangeAdded: "+", ChangeRemoved: "-", ChangeChanged: "~"}
+	colors := map[string]string{ChangeAdded: "\x1b[32m", ChangeRemoved: "\x1b[31m", ChangeChanged: "\x1b[33m"}
+	for _, c := range changes {
+		line := marks[c.Kind] + " " + c.String()
+		if color {
+			line = colors[c.Kind] + line + "\x1b[0m"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	for _, c := range cultures {
+		s := summary[c]
+		if _, err := fmt.Fprintf(w, "culture %s: %d added, %d removed, %d changed\n", c, s.Added, s.Removed, s.Changed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDiffMarkdown(w io.Writer, changes []*Change, cultures []string, summary map[string]*DiffSummary) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No differences.")
+		return err
+	}
+
+	sb := &strings.Builder{}
+	if len(cultures) > 0 {
+		sb.WriteString("| Culture | Added | Removed | Changed |\n|---|---:|---:|---:|\n")
+		for _, c := range cultures {
+			s := summary[c]
+			fmt.Fprintf(sb, "| %s | %d | %d | %d |\n", c, s.Added, s.Removed, s.Changed)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("| Change | Key | Culture | Field | Old | New |\n|---|---|---|---|---|---|\n")
+	for _, c := range changes {
+		fmt.Fprintf(sb, "| %s | %s | %s | %s | %s | %s |\n", c.Kind, markdownCell(c.Key), c.Culture, c.Field,
+			markdownCell(c.Old), markdownCell(c.New))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// markdownCell escapes text for cell of markdown table.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
@@ -0,0 +1,111 @@
+package arb
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	oldData := &Data{
+		Cultures: []string{"en", "ru"},
+		Items: map[string]*Item{
+			"same":    {Description: "d", Cultures: map[string]string{"en": "Same", "ru": "Так же"}},
+			"removed": {Cultures: map[string]string{"en": "Removed", "ru": "Удален"}},
+			"changed": {
+				Description: "old",
+				Cultures:    map[string]string{"en": "Hello {name}", "ru": "", "de": "Hallo"},
+				Parameters:  map[string]*Placeholder{"name": nil},
+			},
+		},
+	}
+	newData := &Data{
+		Cultures: []string{"en", "ru"},
+		Items: map[string]*Item{
+			"same":  {Description: "d", Cultures: map[string]string{"en": "Same", "ru": "Так же"}},
+			"added": {Cultures: map[string]string{"en": "Added", "ru": ""}},
+			"changed": {
+				Description: "new",
+				Cultures:    map[string]string{"en": "Hi {name}", "ru": "Привет {name}"},
+				Parameters:  map[string]*Placeholder{"name": {Type: "String"}},
+			},
+		},
+	}
+
+	changes := Diff(oldData, newData)
+	require.Equal(t, []*Change{
+		{Kind: ChangeAdded, Field: FieldKey, Key: "added"},
+		{Kind: ChangeAdded, Field: FieldMessage, Key: "added", Culture: "en", New: "Added"},
+		{Kind: ChangeChanged, Field: FieldDescription, Key: "changed", Old: "old", New: "new"},
+		{Kind: ChangeChanged, Field: FieldParameters, Key: "changed", Old: `{"name":{}}`, New: `{"name":{"type":"String"}}`},
+		{Kind: ChangeRemoved, Field: FieldMessage, Key: "changed", Culture: "de", Old: "Hallo"},
+		{Kind: ChangeChanged, Field: FieldMessage, Key: "changed", Culture: "en", Old: "Hello {name}", New: "Hi {name}"},
+		{Kind: ChangeAdded, Field: FieldMessage, Key: "changed", Culture: "ru", New: "Привет {name}"},
+		{Kind: ChangeRemoved, Field: FieldKey, Key: "removed"},
+		{Kind: ChangeRemoved, Field: FieldMessage, Key: "removed", Culture: "en", Old: "Removed"},
+		{Kind: ChangeRemoved, Field: FieldMessage, Key: "removed", Culture: "ru", Old: "Удален"},
+	}, changes)
+
+	require.Equal(t, map[string]*DiffSummary{
+		"de": {Removed: 1},
+		"en": {Added: 1, Removed: 1, Changed: 1},
+		"ru": {Added: 1, Removed: 1},
+	}, SummarizeDiff(changes))
+
+	require.Empty(t, Diff(oldData, oldData))
+}
+
+func TestWriteDiff(t *testing.T) {
+	changes := []*Change{
+		{Kind: ChangeAdded, Field: FieldKey, Key: "added"},
+		{Kind: ChangeChanged, Field: FieldDescription, Key: "changed", Old: "old", New: "new"},
+		{Kind: ChangeChanged, Field: FieldMessage, Key: "changed", Culture: "en", Old: "a|b", New: "line\nline"},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteDiff(buf, changes, DiffText, false))
+	require.Equal(t, `+ key added: added
+~ key changed: description "old" -> "new"
+~ key changed, culture en: "a|b" -> "line\nline"
+culture en: 0 added, 0 removed, 1 changed
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteDiff(buf, changes[:1], DiffText, true))
+	require.Equal(t, "\x1b[32m+ key added: added\x1b[0m\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteDiff(buf, changes, DiffMarkdown, false))
+	require.Equal(t, `| Culture | Added | Removed | Changed |
+|---|---:|---:|---:|
+| en | 0 | 0 | 1 |
+
+| Change | Key | Culture | Field | Old | New |
+|---|---|---|---|---|---|
+| added | added |  | key |  |  |
+| changed | changed |  | description | old | new |
+| changed | changed | en | message | a\|b | line<br>line |
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteDiff(buf, changes, DiffJSON, false))
+	var result struct {
+		Changes  []*Change
+		Cultures map[string]*DiffSummary
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Equal(t, changes, result.Changes)
+	require.Equal(t, &DiffSummary{Changed: 1}, result.Cultures["en"])
+
+	buf.Reset()
+	require.NoError(t, WriteDiff(buf, nil, DiffJSON, false))
+	require.JSONEq(t, `{"changes": [], "cultures": {}}`, buf.String())
+
+	f, err := ParseDiffFormat("MD")
+	require.NoError(t, err)
+	require.Equal(t, DiffMarkdown, f)
+	_, err = ParseDiffFormat("html")
+	require.ErrorIs(t, err, ErrInvalidDiffFormat)
+}
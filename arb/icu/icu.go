@@ -0,0 +1,445 @@
+// Package icu parses ICU MessageFormat strings used as arb message values
+// (the subset supported by flutter gen-l10n).
+package icu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	TypePlural        = "plural"
+	TypeSelect        = "select"
+	TypeSelectOrdinal = "selectordinal"
+
+	OtherSelector = "other"
+)
+
+var pluralSelectors = map[string]struct{}{
+	"zero":  {},
+	"one":   {},
+	"two":   {},
+	"few":   {},
+	"many":  {},
+	"other": {},
+}
+
+// Message is a parsed message: sequence of text and argument nodes.
+type Message []Node
+
+// Node is an element of Message. Pos returns offset of node (in runes) in the source string.
+type Node interface {
+	Pos() int
+}
+
+// Text is a literal part of message.
+type Text struct {
+	Offset int
+	Value  string
+}
+
+// Argument is a simple argument: {name}, {name, number} or {name, date, short}.
+type Argument struct {
+	Offset int
+	Name   string
+	Type   string
+	Style  string
+}
+
+// Plural is a plural or selectordinal argument: {count, plural, offset:1 =0{...} one{...} other{...}}.
+type Plural struct {
+	Offset       int
+	Name         string
+	Ordinal      bool
+	PluralOffset int
+	Options      []*Option
+}
+
+// Select is a select argument: {gender, select, male{...} female{...} other{...}}.
+type Select struct {
+	Offset  int
+	Name    string
+	Options []*Option
+}
+
+// Option is one branch of plural or select argument.
+type Option struct {
+	Offset   int
+	Selector string
+	Value    Message
+}
+
+// Pound is a "#" inside plural branch, it is replaced by plural value.
+type Pound struct {
+	Offset int
+}
+
+func (n *Text) Pos() int     { return n.Offset }
+func (n *Argument) Pos() int { return n.Offset }
+func (n *Plural) Pos() int   { return n.Offset }
+func (n *Select) Pos() int   { return n.Offset }
+func (n *Pound) Pos() int    { return n.Offset }
+
+// SyntaxError describes invalid message, Offset is position (in runes) of the error in the source string.
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Offset, e.Msg)
+}
+
+// Parse parses message string to AST.
+func Parse(msg string) (Message, error) {
+	p := &parser{src: []rune(msg)}
+	m, err := p.parseMessage(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected '%c'", p.src[p.pos])
+	}
+	return m, nil
+}
+
+// Validate checks message syntax.
+func Validate(msg string) error {
+	_, err := Parse(msg)
+	return err
+}
+
+type parser struct {
+	src []rune
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{
+		Offset: p.pos,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+// parseMessage parses nodes until closing '}' (not consumed) or end of source.
+// pluralDepth > 0 means the message is a branch of plural, so '#' is a Pound node.
+func (p *parser) parseMessage(pluralDepth int) (Message, error) {
+	var m Message
+	text := &strings.Builder{}
+	textOffset := p.pos
+
+	flushText := func() {
+		if text.Len() > 0 {
+			m = append(m, &Text{Offset: textOffset, Value: text.String()})
+			text.Reset()
+		}
+	}
+
+	for !p.eof() {
+		switch c := p.src[p.pos]; {
+		case c == '}':
+			flushText()
+			return m, nil
+		case c == '{':
+			flushText()
+			n, err := p.parseArgument(pluralDepth)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, n)
+			textOffset = p.pos
+		case c == '#' && pluralDepth > 0:
+			flushText()
+			m = append(m, &Pound{Offset: p.pos})
+			p.pos++
+			textOffset = p.pos
+		default:
+			text.WriteRune(c)
+			p.pos++
+		}
+	}
+	flushText()
+	return m, nil
+}
+
+func (p *parser) parseArgument(pluralDepth int) (Node, error) {
+	offset := p.pos
+	p.pos++ // '{'
+	p.skipSpaces()
+
+	name := p.parseIdentifier()
+	if name == "" {
+		return nil, p.errorf("argument name expected")
+	}
+	p.skipSpaces()
+
+	if p.eof() {
+		return nil, p.errorf("unclosed argument '%s'", name)
+	}
+	if p.src[p.pos] == '}' {
+		p.pos++
+		return &Argument{Offset: offset, Name: name}, nil
+	}
+	if p.src[p.pos] != ',' {
+		return nil, p.errorf("',' or '}' expected after argument name '%s'", name)
+	}
+	p.pos++
+	p.skipSpaces()
+
+	argType := p.parseIdentifier()
+	if argType == "" {
+		return nil, p.errorf("argument type expected for '%s'", name)
+	}
+	p.skipSpaces()
+
+	switch argType {
+	case TypePlural, TypeSelectOrdinal:
+		return p.parsePlural(offset, name, argType == TypeSelectOrdinal, pluralDepth)
+	case TypeSelect:
+		options, err := p.parseOptions(name, false, pluralDepth)
+		if err != nil {
+			return nil, err
+		}
+		return &Select{Offset: offset, Name: name, Options: options}, nil
+	}
+
+	arg := &Argument{Offset: offset, Name: name, Type: argType}
+	if p.eof() {
+		return nil, p.errorf("unclosed argument '%s'", name)
+	}
+	if p.src[p.pos] == ',' {
+		p.pos++
+		start := p.pos
+		for !p.eof() && p.src[p.pos] != '}' {
+			if p.src[p.pos] == '{' {
+				return nil, p.errorf("unexpected '{' in style of argument '%s'", name)
+			}
+			p.pos++
+		}
+		arg.Style = strings.TrimSpace(string(p.src[start:p.pos]))
+	}
+	if p.eof() || p.src[p.pos] != '}' {
+		return nil, p.errorf("unclosed argument '%s'", name)
+	}
+	p.pos++
+	return arg, nil
+}
+
+func (p *parser) parsePlural(offset int, name string, ordinal bool, pluralDepth int) (Node, error) {
+	if p.eof() || p.src[p.pos] != ',' {
+		return nil, p.errorf("',' expected after plural type of '%s'", name)
+	}
+	p.pos++
+	p.skipSpaces()
+
+	n := &Plural{Offset: offset, Name: name, Ordinal: ordinal}
+	if p.hasPrefix("offset:") {
+		p.pos += len("offset:")
+		p.skipSpaces()
+		start := p.pos
+		for !p.eof() && unicode.IsDigit(p.src[p.pos]) {
+			p.pos++
+		}
+		v, err := strconv.Atoi(string(p.src[start:p.pos]))
+		if err != nil {
+			return nil, p.errorf("invalid plural offset of '%s'", name)
+		}
+		n.PluralOffset = v
+	}
+
+	options, err := p.parseOptions(name, true, pluralDepth+1)
+	if err != nil {
+		return nil, err
+	}
+	n.Options = options
+	return n, nil
+}
+
+// parseOptions parses branches of plural or select argument including closing '}' of argument.
+func (p *parser) parseOptions(name string, plural bool, pluralDepth int) ([]*Option, error) {
+	if !plural {
+		if p.eof() || p.src[p.pos] != ',' {
+			return nil, p.errorf("',' expected after select type of '%s'", name)
+		}
+		p.pos++
+	}
+
+	var options []*Option
+	selectors := make(map[string]struct{})
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return nil, p.errorf("unclosed argument '%s'", name)
+		}
+		if p.src[p.pos] == '}' {
+			break
+		}
+
+		offset := p.pos
+		selector, err := p.parseSelector(name, plural)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := selectors[selector]; ok {
+			return nil, p.errorf("duplicate selector '%s' in '%s'", selector, name)
+		}
+		selectors[selector] = struct{}{}
+
+		p.skipSpaces()
+		if p.eof() || p.src[p.pos] != '{' {
+			return nil, p.errorf("'{' expected after selector '%s' in '%s'", selector, name)
+		}
+		p.pos++
+		value, err := p.parseMessage(pluralDepth)
+		if err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return nil, p.errorf("unclosed branch '%s' in '%s'", selector, name)
+		}
+		p.pos++ // '}'
+		options = append(options, &Option{Offset: offset, Selector: selector, Value: value})
+	}
+
+	if _, ok := selectors[OtherSelector]; !ok {
+		return nil, p.errorf("'%s' must have '%s' branch", name, OtherSelector)
+	}
+	p.pos++ // '}'
+	return options, nil
+}
+
+func (p *parser) parseSelector(name string, plural bool) (string, error) {
+	if plural && p.src[p.pos] == '=' {
+		start := p.pos
+		p.pos++
+		for !p.eof() && unicode.IsDigit(p.src[p.pos]) {
+			p.pos++
+		}
+		if p.pos-start == 1 {
+			return "", p.errorf("number expected after '=' in '%s'", name)
+		}
+		return string(p.src[start:p.pos]), nil
+	}
+
+	selector := p.parseIdentifier()
+	if selector == "" {
+		return "", p.errorf("selector expected in '%s'", name)
+	}
+	if plural {
+		if _, ok := pluralSelectors[selector]; !ok {
+			return "", p.errorf("invalid plural selector '%s' in '%s'", selector, name)
+		}
+	}
+	return selector, nil
+}
+
+func (p *parser) parseIdentifier() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.src[p.pos]
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '-' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return strings.HasPrefix(string(p.src[p.pos:]), s)
+}
+
+// Arguments returns names of all arguments (including plural and select ones) used in message.
+func (m Message) Arguments() map[string]struct{} {
+	names := make(map[string]struct{})
+	m.collectArguments(names)
+	return names
+}
+
+func (m Message) collectArguments(names map[string]struct{}) {
+	for _, n := range m {
+		switch n := n.(type) {
+		case *Argument:
+			names[n.Name] = struct{}{}
+		case *Plural:
+			names[n.Name] = struct{}{}
+			collectOptionsArguments(n.Options, names)
+		case *Select:
+			names[n.Name] = struct{}{}
+			collectOptionsArguments(n.Options, names)
+		}
+	}
+}
+
+func collectOptionsArguments(options []*Option, names map[string]struct{}) {
+	for _, o := range options {
+		o.Value.collectArguments(names)
+	}
+}
+
+// String returns message in ICU MessageFormat syntax, Parse(m.String()) returns the same message.
+func (m Message) String() string {
+	sb := &strings.Builder{}
+	m.write(sb)
+	return sb.String()
+}
+
+func (m Message) write(sb *strings.Builder) {
+	for _, n := range m {
+		switch n := n.(type) {
+		case *Text:
+			sb.WriteString(n.Value)
+		case *Pound:
+			sb.WriteString("#")
+		case *Argument:
+			sb.WriteString(n.String())
+		case *Plural:
+			argType := TypePlural
+			if n.Ordinal {
+				argType = TypeSelectOrdinal
+			}
+			fmt.Fprintf(sb, "{%s, %s,", n.Name, argType)
+			if n.PluralOffset != 0 {
+				fmt.Fprintf(sb, " offset:%d", n.PluralOffset)
+			}
+			writeOptions(sb, n.Options)
+		case *Select:
+			fmt.Fprintf(sb, "{%s, %s,", n.Name, TypeSelect)
+			writeOptions(sb, n.Options)
+		}
+	}
+}
+
+func writeOptions(sb *strings.Builder, options []*Option) {
+	for _, o := range options {
+		sb.WriteString(" ")
+		sb.WriteString(o.Selector)
+		sb.WriteString("{")
+		o.Value.write(sb)
+		sb.WriteString("}")
+	}
+	sb.WriteString("}")
+}
+
+// String returns argument in ICU MessageFormat syntax: {name}, {name, number} or {name, date, short}.
+func (n *Argument) String() string {
+	switch {
+	case n.Type == "":
+		return "{" + n.Name + "}"
+	case n.Style == "":
+		return "{" + n.Name + ", " + n.Type + "}"
+	}
+	return "{" + n.Name + ", " + n.Type + ", " + n.Style + "}"
+}
@@ -0,0 +1,90 @@
+package icu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	m, err := Parse("Hello {name}, you have {count, plural, =0{no messages} one{# message} other{# messages}}")
+	require.NoError(t, err)
+	require.Len(t, m, 4)
+
+	require.Equal(t, &Text{Offset: 0, Value: "Hello "}, m[0])
+	require.Equal(t, &Argument{Offset: 6, Name: "name"}, m[1])
+	require.Equal(t, &Text{Offset: 12, Value: ", you have "}, m[2])
+
+	plural, ok := m[3].(*Plural)
+	require.True(t, ok)
+	require.Equal(t, "count", plural.Name)
+	require.False(t, plural.Ordinal)
+	require.Len(t, plural.Options, 3)
+	require.Equal(t, "=0", plural.Options[0].Selector)
+	require.Equal(t, "one", plural.Options[1].Selector)
+	require.Equal(t, Message{&Pound{Offset: 59}, &Text{Offset: 60, Value: " message"}}, plural.Options[1].Value)
+}
+
+func TestParseNested(t *testing.T) {
+	m, err := Parse("{gender, select, male{He has {n, plural, offset:1 one{# item} other{# items}}} other{They have {n, number, compact}}}")
+	require.NoError(t, err)
+	require.Len(t, m, 1)
+
+	sel, ok := m[0].(*Select)
+	require.True(t, ok)
+	require.Equal(t, "gender", sel.Name)
+	require.Len(t, sel.Options, 2)
+
+	plural, ok := sel.Options[0].Value[1].(*Plural)
+	require.True(t, ok)
+	require.Equal(t, 1, plural.PluralOffset)
+
+	arg, ok := sel.Options[1].Value[1].(*Argument)
+	require.True(t, ok)
+	require.Equal(t, &Argument{Offset: 95, Name: "n", Type: "number", Style: "compact"}, arg)
+
+	m, err = Parse("Цена # {мин}")
+	require.NoError(t, err)
+	require.Equal(t, Message{&Text{Offset: 0, Value: "Цена # "}, &Argument{Offset: 7, Name: "мин"}}, m)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		msg    string
+		offset int
+	}{
+		{msg: "Hello {name", offset: 11},
+		{msg: "Hello name}", offset: 10},
+		{msg: "Hello {}", offset: 7},
+		{msg: "Привет {имя", offset: 11},
+		{msg: "{count, plural, one{# item}}", offset: 27},
+		{msg: "{count, plural, several{x} other{y}}", offset: 23},
+		{msg: "{count, plural, one{x} one{y} other{z}}", offset: 26},
+		{msg: "{count, plural, one{x other{y}}", offset: 31},
+		{msg: "{gender, select male{x} other{y}}", offset: 16},
+		{msg: "{name, number, {x}}", offset: 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			err := Validate(tt.msg)
+			require.Error(t, err)
+			syntaxErr, ok := err.(*SyntaxError)
+			require.True(t, ok)
+			require.Equal(t, tt.offset, syntaxErr.Offset, syntaxErr.Error())
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, s := range []string{
+		"Hello, {name}!",
+		"{count, plural, =0{No items} one{# item} other{# items}}",
+		"{gender, select, male{He has {n, plural, offset:1 one{# item} other{# items}}} other{They have {n, number, compact}}}",
+		"{n, selectordinal, one{#st} two{#nd} few{#rd} other{#th}} at {d, date, EEE, M/d/y}",
+	} {
+		m, err := Parse(s)
+		require.NoError(t, err)
+		require.Equal(t, s, m.String())
+	}
+}
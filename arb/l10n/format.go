@@ -0,0 +1,133 @@
+package l10n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+)
+
+// formatter writes message with substituted arguments. Number and date styles are not applied,
+// values are written with fmt.Sprint.
+type formatter struct {
+	culture string
+	args    map[string]interface{}
+	sb      strings.Builder
+	// pound keeps values for "#" of nested plural arguments
+	pound []string
+}
+
+func (f *formatter) format(m icu.Message) error {
+	for _, n := range m {
+		switch n := n.(type) {
+		case *icu.Text:
+			f.sb.WriteString(n.Value)
+		case *icu.Pound:
+			if len(f.pound) > 0 {
+				f.sb.WriteString(f.pound[len(f.pound)-1])
+			}
+		case *icu.Argument:
+			v, err := f.arg(n.Name)
+			if err != nil {
+				return err
+			}
+			f.sb.WriteString(fmt.Sprint(v))
+		case *icu.Select:
+			v, err := f.arg(n.Name)
+			if err != nil {
+				return err
+			}
+			if err := f.format(findOption(n.Options, fmt.Sprint(v))); err != nil {
+				return err
+			}
+		case *icu.Plural:
+			if err := f.formatPlural(n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *formatter) formatPlural(n *icu.Plural) error {
+	v, err := f.arg(n.Name)
+	if err != nil {
+		return err
+	}
+	num, err := numberString(v)
+	if err != nil {
+		return fmt.Errorf("argument %s (%v): %w", n.Name, err, ErrInvalidArgs)
+	}
+	value, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return fmt.Errorf("argument %s is not a number: %w", n.Name, ErrInvalidArgs)
+	}
+
+	// exact selectors (=N) match value itself, categories and "#" use value minus offset
+	pound := num
+	if n.PluralOffset != 0 {
+		pound = strconv.FormatFloat(value-float64(n.PluralOffset), 'f', -1, 64)
+	}
+	for _, opt := range n.Options {
+		if strings.HasPrefix(opt.Selector, "=") {
+			if exact, err := strconv.ParseFloat(opt.Selector[1:], 64); err == nil && exact == value {
+				return f.formatBranch(opt.Value, pound)
+			}
+		}
+	}
+
+	o, err := locale.ParseOperands(pound)
+	if err != nil {
+		return fmt.Errorf("argument %s (%v): %w", n.Name, err, ErrInvalidArgs)
+	}
+	category := locale.CardinalCategory(f.culture, o)
+	if n.Ordinal {
+		category = locale.OrdinalCategory(f.culture, o)
+	}
+	return f.formatBranch(findOption(n.Options, category), pound)
+}
+
+func (f *formatter) formatBranch(m icu.Message, pound string) error {
+	f.pound = append(f.pound, pound)
+	defer func() { f.pound = f.pound[:len(f.pound)-1] }()
+	return f.format(m)
+}
+
+func (f *formatter) arg(name string) (interface{}, error) {
+	v, ok := f.args[name]
+	if !ok {
+		return nil, fmt.Errorf("argument %s is not set: %w", name, ErrInvalidArgs)
+	}
+	return v, nil
+}
+
+// findOption returns value of option with selector or value of "other" option.
+func findOption(options []*icu.Option, selector string) icu.Message {
+	var other icu.Message
+	for _, o := range options {
+		if o.Selector == selector {
+			return o.Value
+		}
+		if o.Selector == icu.OtherSelector {
+			other = o.Value
+		}
+	}
+	return other
+}
+
+// numberString returns decimal representation of number value, strings keep visible fraction digits: "1.50".
+func numberString(v interface{}) (string, error) {
+	switch n := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(n), nil
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case string:
+		return strings.TrimSpace(n), nil
+	}
+	return "", fmt.Errorf("%v (%T) is not a number", v, v)
+}
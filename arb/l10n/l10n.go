@@ -0,0 +1,135 @@
+// Package l10n localizes strings at runtime with arb data: it substitutes placeholders,
+// evaluates plural and select arguments by CLDR rules and falls back between cultures.
+package l10n
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrUnknownKey  = errors.New("unknown key")
+	ErrInvalidArgs = errors.New("invalid arguments")
+)
+
+// Localizer keeps parsed messages of all cultures, it is safe for concurrent use.
+type Localizer struct {
+	mu             sync.RWMutex
+	defaultCulture string
+	cultures       []string
+	// messages keeps parsed non empty messages: culture -> key -> message
+	messages map[string]map[string]icu.Message
+}
+
+// New returns localizer for arb data, all messages are parsed in advance.
+func New(arbData *arb.Data, defaultCulture string) (*Localizer, error) {
+	l := &Localizer{}
+	if err := l.Update(arbData, defaultCulture); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Load returns localizer for arb files matching template from arb folder.
+func Load(logger *logrus.Logger, arbFolderPath, arbFileTemplate, defaultCulture string) (*Localizer, error) {
+	arbData, err := arb.LoadArb(logger, arbFolderPath, arbFileTemplate, defaultCulture)
+	if err != nil {
+		return nil, err
+	}
+	return New(arbData, defaultCulture)
+}
+
+// LoadFS returns localizer for arb files matching template from root of fsys (embed.FS for example).
+func LoadFS(logger *logrus.Logger, fsys fs.FS, arbFileTemplate, defaultCulture string) (*Localizer, error) {
+	arbData, err := arb.LoadArbFS(logger, fsys, arbFileTemplate, defaultCulture)
+	if err != nil {
+		return nil, err
+	}
+	return New(arbData, defaultCulture)
+}
+
+// Update replaces messages of localizer, it can be called while localizer is in use.
+func (l *Localizer) Update(arbData *arb.Data, defaultCulture string) error {
+	defaultCulture, err := locale.Canonical(defaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
+	}
+
+	messages := make(map[string]map[string]icu.Message, len(arbData.Cultures))
+	for _, cn := range arbData.Cultures {
+		messages[cn] = make(map[string]icu.Message)
+	}
+	for key, item := range arbData.Items {
+		for cn, value := range item.Cultures {
+			if value == "" {
+				continue
+			}
+			m, err := icu.Parse(value)
+			if err != nil {
+				return fmt.Errorf("invalid message %s for culture %s (%v): %w", key, cn, err, arb.ErrArbFile)
+			}
+			if messages[cn] == nil {
+				messages[cn] = make(map[string]icu.Message)
+			}
+			messages[cn][key] = m
+		}
+	}
+
+	cultures := make([]string, 0, len(messages))
+	for cn := range messages {
+		cultures = append(cultures, cn)
+	}
+	sort.Strings(cultures)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.defaultCulture = defaultCulture
+	l.cultures = cultures
+	l.messages = messages
+	return nil
+}
+
+// Cultures returns cultures of localizer in alphabetical order.
+func (l *Localizer) Cultures() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	res := make([]string, len(l.cultures))
+	copy(res, l.cultures)
+	return res
+}
+
+// Localize returns message of key for culture with substituted arguments.
+// Message is searched in culture, its parents and default culture: pt-BR -> pt -> en.
+func (l *Localizer) Localize(culture, key string, args map[string]interface{}) (string, error) {
+	l.mu.RLock()
+	defaultCulture := l.defaultCulture
+	messages := l.messages
+	l.mu.RUnlock()
+
+	var chain []string
+	if c, err := locale.Canonical(culture); err == nil {
+		chain = append([]string{c}, locale.Parents(c)...)
+	}
+	chain = append(chain, defaultCulture)
+
+	for _, c := range chain {
+		m, ok := messages[c][key]
+		if !ok {
+			continue
+		}
+		f := &formatter{culture: c, args: args}
+		if err := f.format(m); err != nil {
+			return "", fmt.Errorf("key %s, culture %s: %w", key, c, err)
+		}
+		return f.sb.String(), nil
+	}
+	return "", fmt.Errorf("%s: %w", key, ErrUnknownKey)
+}
@@ -0,0 +1,91 @@
+package l10n
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalize(t *testing.T) {
+	l, err := LoadFS(createLogger(), os.DirFS("test_data"), "app_{culture}.arb", "en")
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "pt", "ru"}, l.Cultures())
+
+	tests := []struct {
+		culture string
+		key     string
+		args    map[string]interface{}
+		res     string
+	}{
+		{"en", "hello", map[string]interface{}{"name": "Bob"}, "Hello, Bob!"},
+		{"ru", "hello", map[string]interface{}{"name": "Боб"}, "Привет, Боб!"},
+		{"pt_BR", "hello", map[string]interface{}{"name": "Rui"}, "Olá, Rui!"},
+		{"de", "hello", map[string]interface{}{"name": "Bob"}, "Hello, Bob!"},
+		{"not a culture", "hello", map[string]interface{}{"name": "Bob"}, "Hello, Bob!"},
+		{"ru", "onlyEn", nil, "Only in English"},
+		{"en", "items", map[string]interface{}{"count": 0}, "No items"},
+		{"en", "items", map[string]interface{}{"count": 1}, "1 item"},
+		{"en", "items", map[string]interface{}{"count": "1.0"}, "1.0 items"},
+		{"en", "items", map[string]interface{}{"count": 2.5}, "2.5 items"},
+		{"ru", "items", map[string]interface{}{"count": 21}, "21 файл"},
+		{"ru", "items", map[string]interface{}{"count": int64(3)}, "3 файла"},
+		{"ru", "items", map[string]interface{}{"count": uint(11)}, "11 файлов"},
+		{"ru", "items", map[string]interface{}{"count": 1.5}, "1.5 файла"},
+		{"en", "place", map[string]interface{}{"n": 22}, "22nd"},
+		{"en", "place", map[string]interface{}{"n": 13}, "13th"},
+		{"en", "invite", map[string]interface{}{"gender": "female", "guests": 0, "host": "Ann"}, "She invited nobody"},
+		{"en", "invite", map[string]interface{}{"gender": "female", "guests": 1, "host": "Ann"}, "She invited Ann"},
+		{"en", "invite", map[string]interface{}{"gender": "male", "guests": 2, "host": "Ann"}, "They invited Ann and 1 other"},
+		{"en", "invite", map[string]interface{}{"gender": "x", "guests": 5, "host": "Ann"}, "They invited Ann and 4 others"},
+	}
+	for _, tt := range tests {
+		res, err := l.Localize(tt.culture, tt.key, tt.args)
+		require.NoError(t, err, "%s %s", tt.culture, tt.key)
+		require.Equal(t, tt.res, res)
+	}
+
+	_, err = l.Localize("en", "unknown", nil)
+	require.ErrorIs(t, err, ErrUnknownKey)
+	_, err = l.Localize("en", "hello", nil)
+	require.ErrorIs(t, err, ErrInvalidArgs)
+	_, err = l.Localize("en", "items", map[string]interface{}{"count": "many"})
+	require.ErrorIs(t, err, ErrInvalidArgs)
+}
+
+func TestLocalizeConcurrent(t *testing.T) {
+	l, err := Load(createLogger(), "test_data", "", "en")
+	require.NoError(t, err)
+
+	updated := &arb.Data{
+		Cultures: []string{"en"},
+		Items:    map[string]*arb.Item{"hello": {Cultures: map[string]string{"en": "Hi, {name}!"}}},
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := l.Localize("ru", "hello", map[string]interface{}{"name": "x"})
+				require.NoError(t, err)
+			}
+		}()
+	}
+	require.NoError(t, l.Update(updated, "en"))
+	wg.Wait()
+
+	res, err := l.Localize("ru", "hello", map[string]interface{}{"name": "Bob"})
+	require.NoError(t, err)
+	require.Equal(t, "Hi, Bob!", res)
+}
+
+func createLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.TraceLevel)
+	return logger
+}
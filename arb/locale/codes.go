@@ -0,0 +1,47 @@
+package locale
+
+import "strings"
+
+// iso639 keeps ISO 639-1 two-letter language codes and deprecated aliases still used by
+// gettext and old platforms (iw -> he, in -> id, ji -> yi, jw -> jv, mo -> ro, sh -> sr).
+var iso639 = codeSet(`
+in iw ji jw mo sh
+aa ab ae af ak am an ar as av ay az ba be bg bh bi bm bn bo br bs ca ce ch co cr cs cu cv cy
+da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he hi ho hr ht hu
+hy hz ia id ie ig ii ik io is it iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky la lb
+lg li ln lo lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny oc oj om
+or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd se sg si sk sl sm sn so sq sr ss st su sv sw
+ta te tg th ti tk tl tn to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi yo za zh zu
+`)
+
+// iso639Alpha3 keeps three-letter language codes of CLDR locales and plural rules, arbitrary
+// words (new, old, tag) are not languages, so files like app_new.arb are not treated as cultures.
+var iso639Alpha3 = codeSet(`
+agq ars asa ast bal bas bem bez bgc bho brx byn ccp ceb cgg chr ckb dav dje doi dsb dua dyo
+ebu ewo fil fur gaa gez gsw guw guz haw hnj hsb jbo jgo jmc kab kam kde kea kgp khq kkj kln
+kok kpe ksb ksf ksh lag lij lkt lrc luo luy mai mas mer mfe mgh mgo mni moh mua myv mzn naq
+nds nmg nnh nqo nso nus nyn osa pcm prg quc rof rwk sah saq sat sbp scn seh ses shi sma smj
+smn sms syr szl teo tig tok trv twq tzm vai vec vun wae wal xog yav yrl yue zgh
+`)
+
+// iso3166 keeps ISO 3166-1 alpha-2 region codes.
+var iso3166 = codeSet(`
+AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR
+BS BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ
+EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW
+GY HK HM HN HR HT HU ID IE IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY
+KZ LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV
+MW MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR PS PT PW PY
+QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG
+TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM
+ZW EU UN
+`)
+
+func codeSet(s string) map[string]struct{} {
+	codes := strings.Fields(s)
+	set := make(map[string]struct{}, len(codes))
+	for _, c := range codes {
+		set[c] = struct{}{}
+	}
+	return set
+}
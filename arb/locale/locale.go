@@ -0,0 +1,193 @@
+// Package locale parses and formats BCP-47 language tags used as cultures.
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Style defines how tag is written to file names, @@locale and csv headers.
+type Style string
+
+const (
+	// StyleUnderscore writes tags with underscore separator: pt_BR, zh_Hant_TW (flutter convention), it is the default style.
+	StyleUnderscore Style = "underscore"
+	// StyleBCP47 writes tags with hyphen separator: pt-BR, zh-Hant-TW.
+	StyleBCP47 Style = "bcp47"
+)
+
+var (
+	ErrInvalidLocale = errors.New("invalid locale")
+	ErrInvalidStyle  = errors.New("invalid locale style")
+)
+
+// Tag is a BCP-47 language tag: language[-script][-region][-variant...].
+type Tag struct {
+	Language string
+	Script   string
+	Region   string
+	Variants []string
+}
+
+// Parse parses tag, "-" and "_" separators are equivalent, case of subtags is not significant.
+func Parse(s string) (Tag, error) {
+	var t Tag
+	if s == "" {
+		return t, fmt.Errorf("empty locale: %w", ErrInvalidLocale)
+	}
+
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 || strings.Trim(s, "-_") != s || strings.Contains(s, "--") || strings.Contains(s, "__") {
+		return t, fmt.Errorf("%q has empty subtags: %w", s, ErrInvalidLocale)
+	}
+
+	lang := strings.ToLower(parts[0])
+	if !isAlpha(lang) || (len(lang) != 2 && len(lang) != 3) {
+		return t, fmt.Errorf("%q has invalid language subtag %q: %w", s, parts[0], ErrInvalidLocale)
+	}
+	known := iso639
+	if len(lang) == 3 {
+		known = iso639Alpha3
+	}
+	if _, ok := known[lang]; !ok {
+		return t, fmt.Errorf("%q has unknown language %q: %w", s, parts[0], ErrInvalidLocale)
+	}
+	t.Language = lang
+
+	rest := parts[1:]
+	if len(rest) > 0 && len(rest[0]) == 4 && isAlpha(rest[0]) {
+		t.Script = strings.ToUpper(rest[0][:1]) + strings.ToLower(rest[0][1:])
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && isRegion(rest[0]) {
+		region := strings.ToUpper(rest[0])
+		if isAlpha(region) {
+			if _, ok := iso3166[region]; !ok && !isPrivateRegion(region) {
+				return t, fmt.Errorf("%q has unknown region %q: %w", s, rest[0], ErrInvalidLocale)
+			}
+		}
+		t.Region = region
+		rest = rest[1:]
+	}
+	for _, v := range rest {
+		if !isVariant(v) {
+			return t, fmt.Errorf("%q has invalid subtag %q: %w", s, v, ErrInvalidLocale)
+		}
+		t.Variants = append(t.Variants, strings.ToLower(v))
+	}
+	return t, nil
+}
+
+// Canonical returns canonical BCP-47 form of tag: en_us -> en-US, zh_hant_tw -> zh-Hant-TW.
+func Canonical(s string) (string, error) {
+	t, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return t.String(), nil
+}
+
+// Format returns tag with the given style, tags which can not be parsed are returned as is.
+func Format(s string, style Style) string {
+	t, err := Parse(s)
+	if err != nil {
+		return s
+	}
+	return t.Format(style)
+}
+
+func ParseStyle(s string) (Style, error) {
+	switch st := Style(strings.ToLower(s)); st {
+	case StyleUnderscore, StyleBCP47:
+		return st, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidStyle)
+}
+
+// Parents returns canonical parents of tag from the nearest one: zh-Hant-TW -> zh-Hant, zh.
+// Tags which can not be parsed have no parents.
+func Parents(s string) []string {
+	t, err := Parse(s)
+	if err != nil {
+		return nil
+	}
+	var parents []string
+	for {
+		switch {
+		case len(t.Variants) > 0:
+			t.Variants = t.Variants[:len(t.Variants)-1]
+		case t.Region != "":
+			t.Region = ""
+		case t.Script != "":
+			t.Script = ""
+		default:
+			return parents
+		}
+		parents = append(parents, t.String())
+	}
+}
+
+func (t Tag) String() string {
+	return t.Format(StyleBCP47)
+}
+
+// Format returns tag with the given style, empty style means StyleUnderscore.
+func (t Tag) Format(style Style) string {
+	sep := "_"
+	if style == StyleBCP47 {
+		sep = "-"
+	}
+	parts := []string{t.Language}
+	if t.Script != "" {
+		parts = append(parts, t.Script)
+	}
+	if t.Region != "" {
+		parts = append(parts, t.Region)
+	}
+	parts = append(parts, t.Variants...)
+	return strings.Join(parts, sep)
+}
+
+func isRegion(s string) bool {
+	return (len(s) == 2 && isAlpha(s)) || (len(s) == 3 && isDigits(s))
+}
+
+// isPrivateRegion reports whether region is from private use range (used by pseudo locales en-XA, ar-XB).
+func isPrivateRegion(s string) bool {
+	return s == "AA" || s == "ZZ" || (s[0] == 'Q' && s[1] >= 'M') || s[0] == 'X'
+}
+
+func isVariant(s string) bool {
+	if !isAlphaNum(s) {
+		return false
+	}
+	return (len(s) >= 5 && len(s) <= 8) || (len(s) == 4 && s[0] >= '0' && s[0] <= '9')
+}
+
+func isAlpha(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphaNum(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
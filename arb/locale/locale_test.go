@@ -0,0 +1,66 @@
+package locale
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"en", "en"},
+		{"EN", "en"},
+		{"en_US", "en-US"},
+		{"en-us", "en-US"},
+		{"pt_br", "pt-BR"},
+		{"sr-latn", "sr-Latn"},
+		{"zh_hant_tw", "zh-Hant-TW"},
+		{"es-419", "es-419"},
+		{"en-XA", "en-XA"},
+		{"de-DE-1996", "de-DE-1996"},
+		{"fil", "fil"},
+		{"yue_hk", "yue-HK"},
+		{"iw", "iw"},
+		{"sh", "sh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			out, err := Canonical(tt.in)
+			require.NoError(t, err)
+			require.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func TestCanonicalErrors(t *testing.T) {
+	for _, in := range []string{"", "xx", "comments", "en-QQQ", "en-US-", "en--US", "e", "en-UK1", "new", "old", "tag", "app"} {
+		t.Run(in, func(t *testing.T) {
+			_, err := Canonical(in)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, ErrInvalidLocale))
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	require.Equal(t, "zh_Hant_TW", Format("zh-hant-tw", StyleUnderscore))
+	require.Equal(t, "zh-Hant-TW", Format("zh_Hant_TW", StyleBCP47))
+	require.Equal(t, "not a locale", Format("not a locale", StyleBCP47))
+
+	st, err := ParseStyle("BCP47")
+	require.NoError(t, err)
+	require.Equal(t, StyleBCP47, st)
+	_, err = ParseStyle("dash")
+	require.True(t, errors.Is(err, ErrInvalidStyle))
+}
+
+func TestParents(t *testing.T) {
+	require.Equal(t, []string{"zh-Hant", "zh"}, Parents("zh_hant_tw"))
+	require.Equal(t, []string{"pt"}, Parents("pt-BR"))
+	require.Empty(t, Parents("en"))
+	require.Empty(t, Parents("xx-YY"))
+}
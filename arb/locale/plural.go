@@ -0,0 +1,424 @@
+package locale
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Plural categories of CLDR plural rules.
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// Operands are CLDR plural operands of a decimal number:
+// N - absolute value, I - integer digits, V - count of visible fraction digits (with trailing zeros),
+// W - count of visible fraction digits without trailing zeros, F - visible fraction digits, T - F without trailing zeros.
+type Operands struct {
+	N          float64
+	I, V, W, F int64
+	T          int64
+}
+
+// ParseOperands returns operands of decimal number written as string: "1.50" has V = 2, F = 50, T = 5.
+func ParseOperands(s string) (Operands, error) {
+	var o Operands
+	s = strings.TrimPrefix(strings.TrimSpace(s), "-")
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || strings.ContainsAny(s, "eEnN") {
+		return o, fmt.Errorf("invalid number %q", s)
+	}
+	o.N = n
+
+	intPart, fracPart := s, ""
+	if dot := strings.Index(s, "."); dot >= 0 {
+		intPart, fracPart = s[:dot], s[dot+1:]
+	}
+	if intPart != "" {
+		if o.I, err = strconv.ParseInt(intPart, 10, 64); err != nil {
+			return o, fmt.Errorf("invalid number %q", s)
+		}
+	}
+	if fracPart != "" {
+		trimmed := strings.TrimRight(fracPart, "0")
+		o.V, o.W = int64(len(fracPart)), int64(len(trimmed))
+		if o.F, err = strconv.ParseInt(fracPart, 10, 64); err != nil {
+			return o, fmt.Errorf("invalid number %q", s)
+		}
+		if trimmed != "" {
+			o.T, _ = strconv.ParseInt(trimmed, 10, 64)
+		}
+	}
+	return o, nil
+}
+
+// CardinalCategory returns CLDR cardinal plural category of number for culture ("1 item", "2 items").
+// Cultures without known rules use PluralOther for all numbers.
+func CardinalCategory(culture string, o Operands) string {
+	t, err := Parse(culture)
+	if err != nil {
+		return PluralOther
+	}
+	if t.Language == "pt" && t.Region == "PT" {
+		return cardinalOneIntegerOne(o)
+	}
+	if rule, ok := cardinalRules[t.Language]; ok {
+		return rule(o)
+	}
+	return PluralOther
+}
+
+// OrdinalCategory returns CLDR ordinal plural category of number for culture ("1st", "2nd").
+func OrdinalCategory(culture string, o Operands) string {
+	t, err := Parse(culture)
+	if err != nil {
+		return PluralOther
+	}
+	if rule, ok := ordinalRules[t.Language]; ok {
+		return rule(o)
+	}
+	return PluralOther
+}
+
+type pluralRule func(o Operands) string
+
+var (
+	cardinalRules = make(map[string]pluralRule)
+	ordinalRules  = make(map[string]pluralRule)
+)
+
+func addRule(rules map[string]pluralRule, languages string, rule pluralRule) {
+	for _, l := range strings.Fields(languages) {
+		rules[l] = rule
+	}
+}
+
+func isInt(n float64) bool {
+	return n == float64(int64(n))
+}
+
+// inRange reports whether n is integer from lo..hi range.
+func inRange(n float64, lo, hi int64) bool {
+	return isInt(n) && int64(n) >= lo && int64(n) <= hi
+}
+
+func mod(n float64, m int64) float64 {
+	i := int64(n)
+	return float64(i%m) + (n - float64(i))
+}
+
+func cardinalOneIntegerOne(o Operands) string {
+	if o.I == 1 && o.V == 0 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// slavicFew is "few" of east slavic and serbo-croatian rules: v = 0 and i % 10 = 2..4 and i % 100 != 12..14.
+func slavicFew(i int64) bool {
+	return i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14)
+}
+
+func init() {
+	addRule(cardinalRules, "ast ca de en et fi fy gl ia io it ji lij nl sc scn sv sw ur yi", cardinalOneIntegerOne)
+	addRule(cardinalRules, "af an asa az bal bem bez bg brx ce cgg chr ckb dv ee el eo es eu fo fur gsw ha haw hu "+
+		"jgo jmc ka kaj kcg kk kkj kl ks ksb ku ky lb lg mas mgo ml mn mr nah nb nd ne nn nnh no nr ny nyn om or os "+
+		"pap ps rm rof rwk saq sd sdh seh sn so sq ss ssy st syr ta te teo tig tk tn tr ts ug uz ve vo vun wae xh xog",
+		func(o Operands) string {
+			if o.N == 1 {
+				return PluralOne
+			}
+			return PluralOther
+		})
+	addRule(cardinalRules, "am as bn doi fa gu hi kn pcm zu", func(o Operands) string {
+		if o.I == 0 || o.N == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "ff fr hy kab pt", func(o Operands) string {
+		if o.I == 0 || o.I == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "ak bho guw ln mg nso pa ti wa", func(o Operands) string {
+		if inRange(o.N, 0, 1) {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "si", func(o Operands) string {
+		if o.N == 0 || o.N == 1 || (o.I == 0 && o.F == 1) {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "da", func(o Operands) string {
+		if o.N == 1 || (o.T != 0 && (o.I == 0 || o.I == 1)) {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "is", func(o Operands) string {
+		if (o.T == 0 && o.I%10 == 1 && o.I%100 != 11) || (o.T%10 == 1 && o.T%100 != 11) {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "mk", func(o Operands) string {
+		if (o.V == 0 && o.I%10 == 1 && o.I%100 != 11) || (o.F%10 == 1 && o.F%100 != 11) {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "fil tl", func(o Operands) string {
+		if (o.V == 0 && (o.I == 1 || o.I == 2 || o.I == 3)) ||
+			(o.V == 0 && o.I%10 != 4 && o.I%10 != 6 && o.I%10 != 9) ||
+			(o.V != 0 && o.F%10 != 4 && o.F%10 != 6 && o.F%10 != 9) {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "lv prg", func(o Operands) string {
+		n10, n100 := mod(o.N, 10), mod(o.N, 100)
+		switch {
+		case n10 == 0 || inRange(n100, 11, 19) || (o.V == 2 && o.F%100 >= 11 && o.F%100 <= 19):
+			return PluralZero
+		case (n10 == 1 && n100 != 11) || (o.V == 2 && o.F%10 == 1 && o.F%100 != 11) || (o.V != 2 && o.F%10 == 1):
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "ru uk", func(o Operands) string {
+		switch {
+		case o.V != 0:
+			return PluralOther
+		case o.I%10 == 1 && o.I%100 != 11:
+			return PluralOne
+		case slavicFew(o.I):
+			return PluralFew
+		}
+		return PluralMany
+	})
+	addRule(cardinalRules, "be", func(o Operands) string {
+		n10, n100 := mod(o.N, 10), mod(o.N, 100)
+		switch {
+		case n10 == 1 && n100 != 11:
+			return PluralOne
+		case inRange(n10, 2, 4) && !inRange(n100, 12, 14):
+			return PluralFew
+		case n10 == 0 || inRange(n10, 5, 9) || inRange(n100, 11, 14):
+			return PluralMany
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "bs hr sh sr", func(o Operands) string {
+		switch {
+		case (o.V == 0 && o.I%10 == 1 && o.I%100 != 11) || (o.F%10 == 1 && o.F%100 != 11):
+			return PluralOne
+		case (o.V == 0 && slavicFew(o.I)) || slavicFew(o.F):
+			return PluralFew
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "pl", func(o Operands) string {
+		switch {
+		case o.V != 0:
+			return PluralOther
+		case o.I == 1:
+			return PluralOne
+		case slavicFew(o.I):
+			return PluralFew
+		}
+		return PluralMany
+	})
+	addRule(cardinalRules, "cs sk", func(o Operands) string {
+		switch {
+		case o.V != 0:
+			return PluralMany
+		case o.I == 1:
+			return PluralOne
+		case o.I >= 2 && o.I <= 4:
+			return PluralFew
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "lt", func(o Operands) string {
+		n10, n100 := mod(o.N, 10), mod(o.N, 100)
+		switch {
+		case o.F != 0:
+			return PluralMany
+		case n10 == 1 && !inRange(n100, 11, 19):
+			return PluralOne
+		case inRange(n10, 2, 9) && !inRange(n100, 11, 19):
+			return PluralFew
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "sl", func(o Operands) string {
+		switch {
+		case o.V == 0 && o.I%100 == 1:
+			return PluralOne
+		case o.V == 0 && o.I%100 == 2:
+			return PluralTwo
+		case (o.V == 0 && (o.I%100 == 3 || o.I%100 == 4)) || o.V != 0:
+			return PluralFew
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "mo ro", func(o Operands) string {
+		switch {
+		case o.I == 1 && o.V == 0:
+			return PluralOne
+		case o.V != 0 || o.N == 0 || (o.N != 1 && inRange(mod(o.N, 100), 1, 19)):
+			return PluralFew
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "ar ars", func(o Operands) string {
+		n100 := mod(o.N, 100)
+		switch {
+		case o.N == 0:
+			return PluralZero
+		case o.N == 1:
+			return PluralOne
+		case o.N == 2:
+			return PluralTwo
+		case inRange(n100, 3, 10):
+			return PluralFew
+		case inRange(n100, 11, 99):
+			return PluralMany
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "he iw", func(o Operands) string {
+		switch {
+		case (o.I == 1 && o.V == 0) || (o.I == 0 && o.V != 0):
+			return PluralOne
+		case o.I == 2 && o.V == 0:
+			return PluralTwo
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "ga", func(o Operands) string {
+		switch {
+		case o.N == 1:
+			return PluralOne
+		case o.N == 2:
+			return PluralTwo
+		case inRange(o.N, 3, 6):
+			return PluralFew
+		case inRange(o.N, 7, 10):
+			return PluralMany
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "gd", func(o Operands) string {
+		switch {
+		case o.N == 1 || o.N == 11:
+			return PluralOne
+		case o.N == 2 || o.N == 12:
+			return PluralTwo
+		case inRange(o.N, 3, 10) || inRange(o.N, 13, 19):
+			return PluralFew
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "cy", func(o Operands) string {
+		switch o.N {
+		case 0:
+			return PluralZero
+		case 1:
+			return PluralOne
+		case 2:
+			return PluralTwo
+		case 3:
+			return PluralFew
+		case 6:
+			return PluralMany
+		}
+		return PluralOther
+	})
+	addRule(cardinalRules, "mt", func(o Operands) string {
+		n100 := mod(o.N, 100)
+		switch {
+		case o.N == 1:
+			return PluralOne
+		case o.N == 2:
+			return PluralTwo
+		case o.N == 0 || inRange(n100, 3, 10):
+			return PluralFew
+		case inRange(n100, 11, 19):
+			return PluralMany
+		}
+		return PluralOther
+	})
+
+	addRule(ordinalRules, "en", func(o Operands) string {
+		n10, n100 := mod(o.N, 10), mod(o.N, 100)
+		switch {
+		case n10 == 1 && n100 != 11:
+			return PluralOne
+		case n10 == 2 && n100 != 12:
+			return PluralTwo
+		case n10 == 3 && n100 != 13:
+			return PluralFew
+		}
+		return PluralOther
+	})
+	addRule(ordinalRules, "fil fr ga hy lo mo ms ro tl vi", func(o Operands) string {
+		if o.N == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(ordinalRules, "sv", func(o Operands) string {
+		n10, n100 := mod(o.N, 10), mod(o.N, 100)
+		if (n10 == 1 || n10 == 2) && n100 != 11 && n100 != 12 {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(ordinalRules, "it sc scn", func(o Operands) string {
+		if o.N == 11 || o.N == 8 || o.N == 80 || o.N == 800 {
+			return PluralMany
+		}
+		return PluralOther
+	})
+	addRule(ordinalRules, "ca", func(o Operands) string {
+		switch o.N {
+		case 1, 3:
+			return PluralOne
+		case 2:
+			return PluralTwo
+		case 4:
+			return PluralFew
+		}
+		return PluralOther
+	})
+	addRule(ordinalRules, "hu", func(o Operands) string {
+		if o.N == 1 || o.N == 5 {
+			return PluralOne
+		}
+		return PluralOther
+	})
+	addRule(ordinalRules, "kk", func(o Operands) string {
+		n10 := mod(o.N, 10)
+		if n10 == 6 || n10 == 9 || (n10 == 0 && o.N != 0) {
+			return PluralMany
+		}
+		return PluralOther
+	})
+	addRule(ordinalRules, "uk", func(o Operands) string {
+		if mod(o.N, 10) == 3 && mod(o.N, 100) != 13 {
+			return PluralFew
+		}
+		return PluralOther
+	})
+}
@@ -0,0 +1,73 @@
+package locale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOperands(t *testing.T) {
+	o, err := ParseOperands("1.50")
+	require.NoError(t, err)
+	require.Equal(t, Operands{N: 1.5, I: 1, V: 2, W: 1, F: 50, T: 5}, o)
+
+	o, err = ParseOperands("-21")
+	require.NoError(t, err)
+	require.Equal(t, Operands{N: 21, I: 21}, o)
+
+	o, err = ParseOperands("0.25")
+	require.NoError(t, err)
+	require.Equal(t, Operands{N: 0.25, V: 2, W: 2, F: 25, T: 25}, o)
+
+	_, err = ParseOperands("1e3")
+	require.Error(t, err)
+	_, err = ParseOperands("many")
+	require.Error(t, err)
+}
+
+func TestCardinalCategory(t *testing.T) {
+	tests := []struct {
+		culture  string
+		number   string
+		category string
+	}{
+		{"en", "1", PluralOne},
+		{"en", "1.0", PluralOther},
+		{"en-US", "2", PluralOther},
+		{"ru", "1", PluralOne},
+		{"ru", "21", PluralOne},
+		{"ru", "11", PluralMany},
+		{"ru", "3", PluralFew},
+		{"ru", "14", PluralMany},
+		{"ru", "1.5", PluralOther},
+		{"pl", "22", PluralFew},
+		{"pl", "25", PluralMany},
+		{"cs", "3", PluralFew},
+		{"cs", "1.5", PluralMany},
+		{"fr", "0", PluralOne},
+		{"fr", "1.5", PluralOne},
+		{"pt-BR", "0", PluralOne},
+		{"pt-PT", "0", PluralOther},
+		{"ar", "0", PluralZero},
+		{"ar", "2", PluralTwo},
+		{"ar", "105", PluralFew},
+		{"ar", "111", PluralMany},
+		{"ja", "1", PluralOther},
+		{"xx", "1", PluralOther},
+	}
+	for _, tt := range tests {
+		o, err := ParseOperands(tt.number)
+		require.NoError(t, err)
+		require.Equal(t, tt.category, CardinalCategory(tt.culture, o), "%s %s", tt.culture, tt.number)
+	}
+}
+
+func TestOrdinalCategory(t *testing.T) {
+	for n, category := range map[string]string{"1": PluralOne, "2": PluralTwo, "3": PluralFew, "4": PluralOther, "11": PluralOther, "22": PluralTwo, "113": PluralOther} {
+		o, err := ParseOperands(n)
+		require.NoError(t, err)
+		require.Equal(t, category, OrdinalCategory("en", o), n)
+	}
+	o, _ := ParseOperands("1")
+	require.Equal(t, PluralOther, OrdinalCategory("ru", o))
+}
@@ -0,0 +1,159 @@
+package arb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+// MissingPolicy defines what to do with empty or absent translations of a culture.
+type MissingPolicy string
+
+const (
+	// MissingEmpty writes missing translations as empty strings.
+	MissingEmpty MissingPolicy = "empty"
+	// MissingOmit does not write missing keys to arb file of culture.
+	MissingOmit MissingPolicy = "omit"
+	// MissingFallback fills missing translations from parent cultures and default culture: pt-BR -> pt -> en.
+	MissingFallback MissingPolicy = "fallback"
+	// MissingFail stops conversion if any translation is missing.
+	MissingFail MissingPolicy = "fail"
+)
+
+var (
+	ErrInvalidMissingPolicy = errors.New("invalid missing translation policy")
+	ErrMissingTranslation   = errors.New("missing translations")
+)
+
+// MissingPolicies keeps policy of every culture, cultures without own policy use Default.
+type MissingPolicies struct {
+	Default  MissingPolicy
+	Cultures map[string]MissingPolicy
+}
+
+// For returns policy of culture, empty policy means MissingEmpty.
+func (p MissingPolicies) For(culture string) MissingPolicy {
+	if mp, ok := p.Cultures[culture]; ok {
+		return mp
+	}
+	if p.Default == "" {
+		return MissingEmpty
+	}
+	return p.Default
+}
+
+func ParseMissingPolicy(s string) (MissingPolicy, error) {
+	switch mp := MissingPolicy(strings.ToLower(strings.TrimSpace(s))); mp {
+	case MissingEmpty, MissingOmit, MissingFallback, MissingFail:
+		return mp, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidMissingPolicy)
+}
+
+// ParseMissingPolicies parses comma separated list of policies: "fallback,de=fail,pt-BR=omit",
+// item without culture sets default policy.
+func ParseMissingPolicies(s string) (MissingPolicies, error) {
+	policies := MissingPolicies{Cultures: make(map[string]MissingPolicy)}
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		eq := strings.Index(part, "=")
+		if eq < 0 {
+			mp, err := ParseMissingPolicy(part)
+			if err != nil {
+				return policies, err
+			}
+			policies.Default = mp
+			continue
+		}
+
+		culture, err := locale.Canonical(strings.TrimSpace(part[:eq]))
+		if err != nil {
+			return policies, fmt.Errorf("%s (%v): %w", part, err, ErrInvalidMissingPolicy)
+		}
+		mp, err := ParseMissingPolicy(part[eq+1:])
+		if err != nil {
+			return policies, err
+		}
+		policies.Cultures[culture] = mp
+	}
+	return policies, nil
+}
+
+// MissingFill describes translation filled by fallback.
+type MissingFill struct {
+	Key     string
+	Culture string
+	// From is culture the translation is taken from.
+	From string
+}
+
+func (f *MissingFill) String() string {
+	return fmt.Sprintf("key %s, culture %s: filled from %s", f.Key, f.Culture, f.From)
+}
+
+// ApplyMissingPolicy applies policy of every culture to empty and absent translations:
+// omitted translations are removed from item cultures, fallback translations are copied from parent cultures
+// or default culture. It returns translations filled by fallback.
+func ApplyMissingPolicy(logger *logrus.Logger, arbData *Data, defaultCulture string, policies MissingPolicies) ([]*MissingFill, error) {
+	defaultCulture, err := locale.Canonical(defaultCulture)
+	if err != nil {
+		return nil, fmt.Errorf("default culture: %w", err)
+	}
+
+	var fills []*MissingFill
+	var missing []string
+	for _, key := range arbData.OrderedKeys(OrderSource) {
+		item := arbData.Items[key]
+		if item.Cultures == nil {
+			item.Cultures = make(map[string]string)
+		}
+
+		// translations are filled only from source values, not from values filled by fallback
+		source := make(map[string]string, len(item.Cultures))
+		for c, v := range item.Cultures {
+			source[c] = v
+		}
+
+		for _, cn := range arbData.Cultures {
+			if source[cn] != "" {
+				continue
+			}
+
+			switch policies.For(cn) {
+			case MissingEmpty:
+				item.Cultures[cn] = ""
+			case MissingOmit:
+				delete(item.Cultures, cn)
+			case MissingFail:
+				missing = append(missing, fmt.Sprintf("key %s, culture %s", key, cn))
+			case MissingFallback:
+				from := ""
+				for _, c := range append(locale.Parents(cn), defaultCulture) {
+					if c != cn && source[c] != "" {
+						from = c
+						break
+					}
+				}
+				if from == "" {
+					logger.Warningf("key %s, culture %s: no fallback translation", key, cn)
+					item.Cultures[cn] = ""
+					continue
+				}
+				item.Cultures[cn] = source[from]
+				fill := &MissingFill{Key: key, Culture: cn, From: from}
+				logger.Debugln(fill)
+				fills = append(fills, fill)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fills, fmt.Errorf("%w, %d translation(s):\n%s", ErrMissingTranslation, len(missing), strings.Join(missing, "\n"))
+	}
+	return fills, nil
+}
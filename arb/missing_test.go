@@ -0,0 +1,81 @@
+package arb
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createMissingData() *Data {
+	return &Data{
+		Cultures: []string{"en", "de", "pt", "pt-BR"},
+		Items: map[string]*Item{
+			"a": {Cultures: map[string]string{"en": "en a", "de": "", "pt": "pt a", "pt-BR": ""}},
+			"b": {Cultures: map[string]string{"en": "en b", "de": "de b"}},
+		},
+		Keys: []string{"a", "b"},
+	}
+}
+
+func TestParseMissingPolicies(t *testing.T) {
+	policies, err := ParseMissingPolicies("fallback, pt_br=fail,DE=omit")
+	require.NoError(t, err)
+	require.Equal(t, MissingFallback, policies.Default)
+	require.Equal(t, MissingFail, policies.For("pt-BR"))
+	require.Equal(t, MissingOmit, policies.For("de"))
+	require.Equal(t, MissingFallback, policies.For("en"))
+
+	require.Equal(t, MissingEmpty, MissingPolicies{}.For("en"))
+
+	_, err = ParseMissingPolicies("skip")
+	require.ErrorIs(t, err, ErrInvalidMissingPolicy)
+	_, err = ParseMissingPolicies("xx=omit")
+	require.ErrorIs(t, err, ErrInvalidMissingPolicy)
+}
+
+func TestApplyMissingPolicy(t *testing.T) {
+	arbData := createMissingData()
+	fills, err := ApplyMissingPolicy(createLogger(), arbData, "en", MissingPolicies{Default: MissingFallback})
+	require.NoError(t, err)
+	require.Equal(t, []*MissingFill{
+		{Key: "a", Culture: "de", From: "en"},
+		{Key: "a", Culture: "pt-BR", From: "pt"},
+		{Key: "b", Culture: "pt", From: "en"},
+		{Key: "b", Culture: "pt-BR", From: "en"},
+	}, fills)
+	require.Equal(t, "pt a", arbData.Items["a"].Cultures["pt-BR"])
+	require.Equal(t, "en b", arbData.Items["b"].Cultures["pt-BR"])
+
+	arbData = createMissingData()
+	fills, err = ApplyMissingPolicy(createLogger(), arbData, "en", MissingPolicies{
+		Default:  MissingEmpty,
+		Cultures: map[string]MissingPolicy{"pt-BR": MissingOmit},
+	})
+	require.NoError(t, err)
+	require.Empty(t, fills)
+	require.Equal(t, map[string]string{"en": "en b", "de": "de b", "pt": ""}, arbData.Items["b"].Cultures)
+
+	dir, err := ioutil.TempDir("", "arb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, SaveArb(createLogger(), arbData, SaveParams{
+		FolderPath:     dir,
+		FileTemplate:   "app_{culture}.arb",
+		DefaultCulture: "en",
+	}))
+	ptData, err := ioutil.ReadFile(path.Join(dir, "app_pt_BR.arb"))
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "@@locale": "pt_BR"
+}
+`, string(ptData))
+
+	_, err = ApplyMissingPolicy(createLogger(), createMissingData(), "en", MissingPolicies{
+		Cultures: map[string]MissingPolicy{"de": MissingFail},
+	})
+	require.ErrorIs(t, err, ErrMissingTranslation)
+	require.Contains(t, err.Error(), "key a, culture de")
+}
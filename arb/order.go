@@ -0,0 +1,174 @@
+package arb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Order defines order of keys in generated arb and csv files.
+type Order string
+
+const (
+	// OrderSource keeps order of keys from source (csv rows or keys of arb files).
+	OrderSource Order = "source"
+	// OrderAlpha sorts keys alphabetically.
+	OrderAlpha Order = "alpha"
+	// OrderPrefix groups keys by prefix (part of key before first "_", "." or upper case letter),
+	// groups and keys inside groups keep source order.
+	OrderPrefix Order = "prefix"
+)
+
+var (
+	ErrInvalidOrder = errors.New("invalid order")
+)
+
+func ParseOrder(s string) (Order, error) {
+	switch o := Order(strings.ToLower(s)); o {
+	case OrderSource, OrderAlpha, OrderPrefix:
+		return o, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidOrder)
+}
+
+// OrderedKeys returns keys of items in requested order.
+// Items missing in Keys are placed after known keys in alphabetical order.
+func (d *Data) OrderedKeys(order Order) []string {
+	keys := make([]string, 0, len(d.Items))
+	known := make(map[string]struct{}, len(d.Items))
+	for _, k := range d.Keys {
+		if _, ok := d.Items[k]; !ok {
+			continue
+		}
+		if _, ok := known[k]; ok {
+			continue
+		}
+		known[k] = struct{}{}
+		keys = append(keys, k)
+	}
+
+	var unknown []string
+	for k := range d.Items {
+		if _, ok := known[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	keys = append(keys, unknown...)
+
+	switch order {
+	case OrderAlpha:
+		sort.Strings(keys)
+	case OrderPrefix:
+		groupIndex := make(map[string]int)
+		for _, k := range keys {
+			p := KeyPrefix(k)
+			if _, ok := groupIndex[p]; !ok {
+				groupIndex[p] = len(groupIndex)
+			}
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			return groupIndex[KeyPrefix(keys[i])] < groupIndex[KeyPrefix(keys[j])]
+		})
+	}
+	return keys
+}
+
+// OrderedCultures returns cultures with default culture first and others in alphabetical order.
+func (d *Data) OrderedCultures(defaultCulture string) []string {
+	cultures := make([]string, len(d.Cultures))
+	copy(cultures, d.Cultures)
+	sort.SliceStable(cultures, func(i, j int) bool {
+		if cultures[i] == defaultCulture || cultures[j] == defaultCulture {
+			return cultures[i] == defaultCulture && cultures[j] != defaultCulture
+		}
+		return cultures[i] < cultures[j]
+	})
+	return cultures
+}
+
+// KeyPrefix returns part of key before first "_", "." or upper case letter: loginTitle -> login, home_title -> home.
+func KeyPrefix(key string) string {
+	for i, c := range key {
+		if i > 0 && (c == '_' || c == '.' || unicode.IsUpper(c)) {
+			return key[:i]
+		}
+	}
+	return key
+}
+
+type jsonEntry struct {
+	key   string
+	value interface{}
+}
+
+// marshalOrderedJSON writes json object with entries in the given order.
+func marshalOrderedJSON(entries []jsonEntry) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("{")
+	for i, e := range entries {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  ")
+		if err := writeJSONValue(buf, e.key, ""); err != nil {
+			return nil, err
+		}
+		buf.WriteString(": ")
+		if err := writeJSONValue(buf, e.value, "  "); err != nil {
+			return nil, err
+		}
+	}
+	if len(entries) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+func writeJSONValue(buf *bytes.Buffer, v interface{}, prefix string) error {
+	vb := &bytes.Buffer{}
+	enc := json.NewEncoder(vb)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimRight(vb.Bytes(), "\n"))
+	return nil
+}
+
+// readOrderedKeys returns top level keys of json object in the source order.
+func readOrderedKeys(rawData []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(rawData))
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("json object expected")
+	}
+
+	var keys []string
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		k, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("json object key expected")
+		}
+		keys = append(keys, k)
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
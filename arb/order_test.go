@@ -0,0 +1,52 @@
+package arb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedKeys(t *testing.T) {
+	arbData := &Data{
+		Items: map[string]*Item{
+			"loginTitle":  {},
+			"home_title":  {},
+			"loginButton": {},
+			"about":       {},
+			"home_button": {},
+			"extra":       {},
+		},
+		Keys: []string{"loginTitle", "home_title", "loginButton", "about", "home_button", "removed"},
+	}
+
+	require.Equal(t, []string{"loginTitle", "home_title", "loginButton", "about", "home_button", "extra"}, arbData.OrderedKeys(OrderSource))
+	require.Equal(t, []string{"about", "extra", "home_button", "home_title", "loginButton", "loginTitle"}, arbData.OrderedKeys(OrderAlpha))
+	require.Equal(t, []string{"loginTitle", "loginButton", "home_title", "home_button", "about", "extra"}, arbData.OrderedKeys(OrderPrefix))
+}
+
+func TestOrderedCultures(t *testing.T) {
+	arbData := &Data{Cultures: []string{"ru", "de", "en", "fr"}}
+	require.Equal(t, []string{"en", "de", "fr", "ru"}, arbData.OrderedCultures("en"))
+	require.Equal(t, []string{"ru", "de", "en", "fr"}, arbData.Cultures)
+}
+
+func TestMarshalOrderedJSON(t *testing.T) {
+	buf, err := marshalOrderedJSON([]jsonEntry{
+		{key: "b", value: "<b>{name}</b>"},
+		{key: "@b", value: map[string]interface{}{"description": "d"}},
+		{key: "a", value: "x"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "b": "<b>{name}</b>",
+  "@b": {
+    "description": "d"
+  },
+  "a": "x"
+}
+`, string(buf))
+
+	keys, err := readOrderedKeys(buf)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "@b", "a"}, keys)
+}
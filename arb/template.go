@@ -0,0 +1,119 @@
+package arb
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+const cultureTemplate = "{culture}"
+
+// fileNameForCulture returns file name for culture by template like app_{culture}.arb.
+func fileNameForCulture(fileTemplate, culture string) string {
+	return strings.ReplaceAll(fileTemplate, cultureTemplate, culture)
+}
+
+// templateRegexp returns regexp which matches file names created by template and captures culture.
+func templateRegexp(fileTemplate string) *regexp.Regexp {
+	parts := strings.Split(fileTemplate, cultureTemplate)
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, `([^/\\]+)`) + "$")
+}
+
+// cultureFromFileName returns culture from file name created by template.
+func cultureFromFileName(fileTemplate, name string) (string, bool) {
+	m := templateRegexp(fileTemplate).FindStringSubmatch(name)
+	if m == nil {
+		return "", false
+	}
+	if len(m) == 1 {
+		// template without culture
+		return "", false
+	}
+	for _, c := range m[2:] {
+		if c != m[1] {
+			return "", false
+		}
+	}
+	return m[1], true
+}
+
+type arbFile struct {
+	// relPath is slash separated path relative to arb folder
+	relPath string
+	// culture is detected from file name, can be empty
+	culture string
+}
+
+// findArbFiles returns arb files of fsys matching template (template can contain subdirectories: {culture}/app.arb),
+// folders deeper than the template are not read.
+// With empty template it returns all arb files of the root folder and detects culture by last part of file name.
+func findArbFiles(logger *logrus.Logger, fsys fs.FS, fileTemplate string) ([]arbFile, error) {
+	var files []arbFile
+
+	if fileTemplate == "" {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || strings.ToLower(path.Ext(e.Name())) != arbExt {
+				logger.Tracef("skip %s", e.Name())
+				continue
+			}
+			files = append(files, arbFile{relPath: e.Name(), culture: getCultureFromFileName(e.Name())})
+		}
+		return files, nil
+	}
+
+	re := templateRegexp(fileTemplate)
+	depth := strings.Count(fileTemplate, "/")
+	err := fs.WalkDir(fsys, ".", func(relPath string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if e.IsDir() {
+			if relPath != "." && strings.Count(relPath, "/") >= depth {
+				logger.Tracef("skip folder %s", relPath)
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !re.MatchString(relPath) {
+			logger.Tracef("skip %s", relPath)
+			return nil
+		}
+		culture, ok := cultureFromFileName(fileTemplate, relPath)
+		if !ok {
+			logger.Tracef("skip %s", relPath)
+			return nil
+		}
+		files = append(files, arbFile{relPath: relPath, culture: culture})
+		return nil
+	})
+	return files, err
+}
+
+// getCultureFromFileName returns the longest valid locale from the end of file name: app_pt_BR.arb -> pt_BR,
+// if there is no valid locale it returns the last part of file name.
+func getCultureFromFileName(name string) string {
+	nameWithoutExt := name[:len(name)-len(filepath.Ext(name))]
+	parts := strings.Split(nameWithoutExt, "_")
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	for i := 1; i < len(parts); i++ {
+		culture := strings.Join(parts[i:], "_")
+		if _, err := locale.Parse(culture); err == nil {
+			return culture
+		}
+	}
+	return parts[len(parts)-1]
+}
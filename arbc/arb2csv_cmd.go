@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
 	"github.com/evg1605/csv_arb/csv"
 
 	"github.com/sirupsen/logrus"
@@ -9,7 +10,29 @@ import (
 )
 
 func arb2csv(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
-	arbData, err := arb.LoadArb(logger, getStrFromFlag(flags, arbPathFlag), getStrFromFlag(flags, cultureFlag))
+	arbData, err := arb.LoadArb(logger,
+		getStrFromFlag(flags, arbPathFlag),
+		getArbTemplateFromFlag(flags),
+		getStrFromFlag(flags, cultureFlag))
+	if err != nil {
+		return err
+	}
+
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	return saveCsv(logger, flags, getStrFromFlag(flags, csvPathFlag), arbData)
+}
+
+// saveCsv writes arb data to csv or xlsx file csvPath.
+func saveCsv(logger *logrus.Logger, flags map[string]commando.FlagValue, csvPath string, arbData *arb.Data) error {
+	order, err := arb.ParseOrder(getStrFromFlag(flags, orderFlag))
+	if err != nil {
+		return err
+	}
+
+	localeStyle, err := locale.ParseStyle(getStrFromFlag(flags, localeStyleFlag))
 	if err != nil {
 		return err
 	}
@@ -18,7 +41,14 @@ func arb2csv(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
 		ColumnName:        getStrFromFlag(flags, colNameFlag),
 		ColumnDescription: getStrFromFlag(flags, colDescrFlag),
 		ColumnParameters:  getStrFromFlag(flags, colParamsFlag),
+		ColumnStates:      getStrFromFlag(flags, colStatesFlag),
 		DefaultCulture:    getStrFromFlag(flags, cultureFlag),
+		Order:             order,
+		LocaleStyle:       localeStyle,
+		Sheet:             getStrFromFlag(flags, sheetFlag),
+	}
+	if err := setDialectFromFlags(flags, csvPath, &csvParams); err != nil {
+		return err
 	}
-	return csv.SaveArb(logger, getStrFromFlag(flags, csvPathFlag), csvParams, arbData)
+	return csv.SaveArb(logger, csvPath, csvParams, arbData)
 }
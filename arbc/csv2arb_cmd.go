@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
 	"strings"
 
 	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
 	"github.com/evg1605/csv_arb/csv"
 	"github.com/sirupsen/logrus"
 	"github.com/thatisuday/commando"
@@ -12,27 +17,85 @@ import (
 func csv2arb(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
 	src := getStrFromFlag(flags, csvPathFlag)
 
+	order, err := arb.ParseOrder(getStrFromFlag(flags, orderFlag))
+	if err != nil {
+		return err
+	}
+
+	localeStyle, err := locale.ParseStyle(getStrFromFlag(flags, localeStyleFlag))
+	if err != nil {
+		return err
+	}
+
+	csvParams, err := getCsvParams(flags, src)
+	if err != nil {
+		return err
+	}
+	csvParams.Order = order
+	csvParams.LocaleStyle = localeStyle
+
+	arbData, err := loadCsv(logger, flags, src, csvParams)
+	if err != nil {
+		return err
+	}
+
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	missingPolicies, err := arb.ParseMissingPolicies(getStrFromFlag(flags, missingFlag))
+	if err != nil {
+		return err
+	}
+	fills, err := arb.ApplyMissingPolicy(logger, arbData, csvParams.DefaultCulture, missingPolicies)
+	if err != nil {
+		return err
+	}
+	for _, f := range fills {
+		fmt.Println(f)
+	}
+
+	return arb.SaveArb(logger, arbData, arb.SaveParams{
+		FolderPath:        getStrFromFlag(flags, arbPathFlag),
+		FileTemplate:      getStrFromFlag(flags, arbTemplateFlag),
+		DefaultCulture:    csvParams.DefaultCulture,
+		Order:             order,
+		Prune:             getBoolFromFlag(flags, pruneFlag),
+		StampLastModified: getBoolFromFlag(flags, stampFlag),
+		LocaleStyle:       localeStyle,
+	})
+}
+
+// getCsvParams returns params of csv file or url src from flags.
+func getCsvParams(flags map[string]commando.FlagValue, src string) (csv.Params, error) {
 	csvParams := csv.Params{
 		ColumnName:        getStrFromFlag(flags, colNameFlag),
 		ColumnDescription: getStrFromFlag(flags, colDescrFlag),
 		ColumnParameters:  getStrFromFlag(flags, colParamsFlag),
+		ColumnStates:      getStrFromFlag(flags, colStatesFlag),
 		DefaultCulture:    getStrFromFlag(flags, cultureFlag),
+		Sheet:             getStrFromFlag(flags, sheetFlag),
+		Tabs:              getTabsFromFlag(flags),
+		MaxSize:           int64(getIntFromFlag(flags, maxSizeFlag)) << 20,
 	}
+	err := setDialectFromFlags(flags, src, &csvParams)
+	return csvParams, err
+}
 
-	var arbData *arb.Data
-	var arbDataErr error
-	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
-		arbData, arbDataErr = csv.LoadArbFromWeb(logger, src, csvParams)
-	} else {
-		arbData, arbDataErr = csv.LoadArbFromFile(logger, src, csvParams)
+// loadCsv loads csv from url or file src.
+func loadCsv(logger *logrus.Logger, flags map[string]commando.FlagValue, src string, csvParams csv.Params) (*arb.Data, error) {
+	if !isURL(src) {
+		return csv.LoadArbFromFile(logger, src, csvParams)
 	}
-	if arbDataErr != nil {
-		return arbDataErr
+	webParams, err := getWebParams(flags)
+	if err != nil {
+		return nil, err
 	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return csv.LoadArbFromWeb(ctx, logger, src, csvParams, webParams)
+}
 
-	return arb.SaveArb(logger,
-		arbData,
-		getStrFromFlag(flags, arbPathFlag),
-		getStrFromFlag(flags, arbTemplateFlag),
-		csvParams.DefaultCulture)
+func isURL(src string) bool {
+	return strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/evg1605/csv_arb/csv"
+	"github.com/thatisuday/commando"
+)
+
+const (
+	// autoDelimiter is a value of delimiter flag which means tab for .tsv files and comma for others.
+	autoDelimiter = "auto"
+	// noComment is a value of comment flag which means that comment lines are not skipped.
+	noComment = "none"
+)
+
+// addDialectFlags adds flags of csv dialect.
+func addDialectFlags(c *commando.Command) *commando.Command {
+	return c.
+		AddFlag(delimiterFlag, "field delimiter of csv: ;, tab (auto - tab for .tsv files, comma for others)", commando.String, autoDelimiter).
+		AddFlag(encodingFlag, "encoding of csv (utf-8, utf-16, utf-16le, utf-16be, windows-1251)", commando.String, string(csv.EncodingUTF8)).
+		AddFlag(commentFlag, "prefix of comment lines of csv which are skipped: #, // (none - no comments)", commando.String, noComment).
+		AddFlag(lazyQuotesFlag, "allow quotes in unquoted fields and unescaped quotes in quoted fields of csv", commando.Bool, false).
+		AddFlag(excelFlag, "write csv with BOM and CRLF line endings for Excel", commando.Bool, false)
+}
+
+// setDialectFromFlags sets dialect of csvParams for csv file or url csvPath.
+func setDialectFromFlags(flags map[string]commando.FlagValue, csvPath string, csvParams *csv.Params) error {
+	delimiter, err := parseDelimiter(getStrFromFlag(flags, delimiterFlag), csvPath)
+	if err != nil {
+		return err
+	}
+	encoding, err := csv.ParseEncoding(getStrFromFlag(flags, encodingFlag))
+	if err != nil {
+		return err
+	}
+
+	csvParams.Delimiter = delimiter
+	csvParams.Encoding = encoding
+	if comment := getStrFromFlag(flags, commentFlag); comment != noComment {
+		csvParams.Comment = comment
+	}
+	csvParams.LazyQuotes = getBoolFromFlag(flags, lazyQuotesFlag)
+	csvParams.Excel = getBoolFromFlag(flags, excelFlag)
+	return nil
+}
+
+func parseDelimiter(s, csvPath string) (rune, error) {
+	switch strings.ToLower(s) {
+	case autoDelimiter:
+		if strings.EqualFold(filepath.Ext(csvPath), ".tsv") {
+			return '\t', nil
+		}
+		return 0, nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, fmt.Errorf("delimiter must be one character: %q", s)
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return r, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/sirupsen/logrus"
+	"github.com/thatisuday/commando"
+)
+
+// Values of color flag.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// Exit codes of diff command, the same as of diff(1).
+const (
+	diffExitChanges = 1
+	diffExitError   = 2
+)
+
+func diff(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	n, err := writeDiff(logger, flags)
+	if err != nil {
+		return &exitError{code: diffExitError, err: err}
+	}
+	if n > 0 {
+		return &exitError{code: diffExitChanges, err: fmt.Errorf("%d change(s): %w", n, arb.ErrDiff), reported: true}
+	}
+	return nil
+}
+
+// writeDiff writes changes between sources to stdout and returns number of changes.
+func writeDiff(logger *logrus.Logger, flags map[string]commando.FlagValue) (int, error) {
+	format, err := arb.ParseDiffFormat(getStrFromFlag(flags, formatFlag))
+	if err != nil {
+		return 0, err
+	}
+	color, err := useColor(getStrFromFlag(flags, colorFlag))
+	if err != nil {
+		return 0, err
+	}
+
+	oldData, err := loadSource(logger, flags, getStrFromFlag(flags, oldFlag))
+	if err != nil {
+		return 0, err
+	}
+	newData, err := loadSource(logger, flags, getStrFromFlag(flags, newFlag))
+	if err != nil {
+		return 0, err
+	}
+
+	changes := arb.Diff(oldData, newData)
+	if err := arb.WriteDiff(os.Stdout, changes, format, color); err != nil {
+		return 0, err
+	}
+	return len(changes), nil
+}
+
+// loadSource loads arb folder, csv url or csv (xlsx) file src.
+func loadSource(logger *logrus.Logger, flags map[string]commando.FlagValue, src string) (*arb.Data, error) {
+	if isArbFolder(src) {
+		return arb.LoadArb(logger, src, getArbTemplateFromFlag(flags), getStrFromFlag(flags, cultureFlag))
+	}
+	csvParams, err := getCsvParams(flags, src)
+	if err != nil {
+		return nil, err
+	}
+	return loadCsv(logger, flags, src, csvParams)
+}
+
+func isArbFolder(src string) bool {
+	if isURL(src) {
+		return false
+	}
+	info, err := os.Stat(src)
+	return err == nil && info.IsDir()
+}
+
+// useColor reports whether text output is colored, auto mode colors output to terminal
+// unless NO_COLOR environment variable is set.
+func useColor(mode string) (bool, error) {
+	switch mode {
+	case colorAlways:
+		return true, nil
+	case colorNever:
+		return false, nil
+	case colorAuto:
+		if _, ok := os.LookupEnv("NO_COLOR"); ok {
+			return false, nil
+		}
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	}
+	return false, fmt.Errorf("invalid color mode %s, expected auto, always or never", mode)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/lint"
+	"github.com/sirupsen/logrus"
+	"github.com/thatisuday/commando"
+)
+
+func lintArb(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	format, err := lint.ParseFormat(getStrFromFlag(flags, formatFlag))
+	if err != nil {
+		return err
+	}
+	rules := getStrFromFlag(flags, rulesFlag)
+	if rules == noRules {
+		rules = ""
+	}
+	severities, err := lint.ParseSeverities(rules)
+	if err != nil {
+		return err
+	}
+
+	src := getStrFromFlag(flags, sourceFlag)
+	params := lint.Params{
+		DefaultCulture: getStrFromFlag(flags, cultureFlag),
+		Severities:     severities,
+		Source:         src,
+	}
+	report, err := lintSource(logger, flags, params)
+	if err != nil {
+		return err
+	}
+
+	if getBoolFromFlag(flags, fixFlag) && report.Fixable() > 0 {
+		if !isArbFolder(src) {
+			logger.Warningln("fixes are applied to arb folders only")
+		} else {
+			removed, err := arb.RemoveOrphanMeta(logger, src, getArbTemplateFromFlag(flags))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "removed %d orphan metadata entries\n", removed)
+			if report, err = lintSource(logger, flags, params); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := report.Write(os.Stdout, format, AppVersion); err != nil {
+		return err
+	}
+	if n := report.Count(lint.SeverityError); n > 0 {
+		return fmt.Errorf("%d error(s): %w", n, lint.ErrFindings)
+	}
+	return nil
+}
+
+func lintSource(logger *logrus.Logger, flags map[string]commando.FlagValue, params lint.Params) (*lint.Report, error) {
+	arbData, err := loadSource(logger, flags, params.Source)
+	if err != nil {
+		return nil, err
+	}
+	return lint.Lint(arbData, params)
+}
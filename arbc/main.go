@@ -1,12 +1,22 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"path"
 	"runtime"
+	"strings"
 
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
 	"github.com/evg1605/csv_arb/csv"
+	"github.com/evg1605/csv_arb/lint"
+	"github.com/evg1605/csv_arb/pseudo"
+	"github.com/evg1605/csv_arb/stats"
+	"github.com/evg1605/csv_arb/translate"
+	"github.com/evg1605/csv_arb/xliff"
 	"github.com/sirupsen/logrus"
 	"github.com/thatisuday/commando"
 )
@@ -18,10 +28,87 @@ const (
 	arbPathFlag     = "arb-path"
 	colDescrFlag    = "col-descr"
 	colParamsFlag   = "col-params"
+	colStatesFlag   = "col-states"
 	cultureFlag     = "culture"
 	logLevelFlag    = "log-level"
+	checkPhFlag     = "check-placeholders"
+	orderFlag       = "order"
+	pruneFlag       = "prune"
+	stampFlag       = "stamp-last-modified"
+	localeStyleFlag = "locale-style"
+	missingFlag     = "missing"
+	xliffPathFlag   = "xliff-path"
+	xliffTmplFlag   = "xliff-template"
+	xliffVerFlag    = "xliff-version"
+	poPathFlag      = "po-path"
+	poTmplFlag      = "po-template"
+	potFileFlag     = "pot-file"
+	androidPathFlag = "android-path"
+	iosPathFlag     = "ios-path"
+	xcstringsFlag   = "xcstrings-path"
+	sheetFlag       = "sheet"
+	tabsFlag        = "tabs"
+	authFlag        = "auth"
+	headersFlag     = "headers"
+	timeoutFlag     = "timeout"
+	retriesFlag     = "retries"
+	proxyFlag       = "proxy"
+	caFileFlag      = "ca-file"
+	offlineFlag     = "offline"
+	maxSizeFlag     = "max-size"
+	delimiterFlag   = "delimiter"
+	encodingFlag    = "encoding"
+	commentFlag     = "comment"
+	lazyQuotesFlag  = "lazy-quotes"
+	excelFlag       = "excel"
+	oldFlag         = "old"
+	newFlag         = "new"
+	formatFlag      = "format"
+	colorFlag       = "color"
+	sourceFlag      = "source"
+	rulesFlag       = "rules"
+	fixFlag         = "fix"
+	minCoverageFlag = "min-coverage"
+	badgePathFlag   = "badge-path"
+	accentedFlag    = "accented"
+	bidiFlag        = "bidi"
+	expansionFlag   = "expansion"
+	outFlag         = "out"
+	providerURLFlag = "provider-url"
+	apiKeyFlag      = "api-key"
+	culturesFlag    = "cultures"
+	batchSizeFlag   = "batch-size"
+	// cacheFlag is registered as inverted flag --no-cache, its value is true by default
+	cacheFlag = "cache"
+	// bracketsFlag is registered as inverted flag --no-brackets, its value is true by default
+	bracketsFlag = "brackets"
 )
 
+// defaultArbTemplate is a template of arb files written by csv2arb and loaded by arb2csv by default.
+const defaultArbTemplate = "app_{culture}.arb"
+
+// anyArbTemplate is a value of arb template flag which means any arb file in arb folder,
+// commando treats flags with empty default value as required.
+const anyArbTemplate = "*"
+
+// noRules is a value of rules flag which keeps default severities of lint rules.
+const noRules = "default"
+
+// noMinCoverage is a value of min coverage flag which disables coverage check.
+const noMinCoverage = "none"
+
+// noPseudo is a value of pseudo culture flags which disables generation of culture.
+const noPseudo = "none"
+
+// toSource is a value of out flag which means writing result to source.
+const toSource = "source"
+
+// allCultures is a value of cultures flag which means all cultures except default one.
+const allCultures = "*"
+
+// urlTabs is a value of tabs flag which means tab of google sheets url.
+const urlTabs = "*"
+
 var AppVersion = "develop"
 
 func main() {
@@ -42,34 +129,299 @@ func main() {
 		Register("csv2arb").
 		SetDescription("convert csv to arb").
 		SetShortDescription("convert csv to arb").
-		AddFlag(csvPathFlag, "url or path of csv file", commando.String, "").
-		AddFlag(arbTemplateFlag, "arb file template", commando.String, "app_{culture}.arb").
+		AddFlag(csvPathFlag, "url or path of csv or xlsx file", commando.String, "").
+		AddFlag(arbTemplateFlag, "arb file template", commando.String, defaultArbTemplate).
+		AddFlag(pruneFlag, "remove arb files matching template for cultures absent in csv", commando.Bool, false).
+		AddFlag(stampFlag, "write current time to @@last_modified of changed arb files", commando.Bool, false).
+		AddFlag(missingFlag, "missing translations policy (empty, omit, fallback, fail), per culture: fallback,de=fail", commando.String, string(arb.MissingEmpty)).
 		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
 			baseAction(r, csv2arbCmd, flags, csv2arb)
 		})
 	addCommonFlags(csv2arbCmd)
+	addWebFlags(csv2arbCmd)
 
 	var arb2csvCmd *commando.Command
 	arb2csvCmd = commando.
 		Register("arb2csv").
 		SetDescription("convert arb to csv").
 		SetShortDescription("convert arb to csv").
-		AddFlag(csvPathFlag, "path to csv file", commando.String, "").
+		AddFlag(csvPathFlag, "path to csv or xlsx file", commando.String, "").
+		AddFlag(arbTemplateFlag, "arb file template (* - any arb file in arb folder)", commando.String, defaultArbTemplate).
 		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
 			baseAction(r, arb2csvCmd, flags, arb2csv)
 		})
 	addCommonFlags(arb2csvCmd)
 
+	var arb2xliffCmd *commando.Command
+	arb2xliffCmd = commando.
+		Register("arb2xliff").
+		SetDescription("export arb to xliff files, one file per target culture, default culture is source").
+		SetShortDescription("export arb to xliff").
+		AddFlag(arbTemplateFlag, "arb file template (* - any arb file in arb folder)", commando.String, anyArbTemplate).
+		AddFlag(xliffPathFlag, "xliff folder path", commando.String, "").
+		AddFlag(xliffTmplFlag, "xliff file template", commando.String, "app_{culture}.xlf").
+		AddFlag(xliffVerFlag, "xliff version (1.2, 2.0)", commando.String, string(xliff.Version12)).
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, arb2xliffCmd, flags, arb2xliff)
+		})
+	addArbFlags(arb2xliffCmd)
+
+	var xliff2arbCmd *commando.Command
+	xliff2arbCmd = commando.
+		Register("xliff2arb").
+		SetDescription("merge translated xliff files to arb files").
+		SetShortDescription("import xliff to arb").
+		AddFlag(arbTemplateFlag, "arb file template", commando.String, defaultArbTemplate).
+		AddFlag(xliffPathFlag, "xliff folder path", commando.String, "").
+		AddFlag(xliffTmplFlag, "xliff file template", commando.String, "app_{culture}.xlf").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, xliff2arbCmd, flags, xliff2arb)
+		})
+	addArbFlags(xliff2arbCmd)
+
+	var arb2poCmd *commando.Command
+	arb2poCmd = commando.
+		Register("arb2po").
+		SetDescription("export arb to gettext pot file and po files, one file per target culture, default culture is msgid").
+		SetShortDescription("export arb to po").
+		AddFlag(arbTemplateFlag, "arb file template (* - any arb file in arb folder)", commando.String, anyArbTemplate).
+		AddFlag(poPathFlag, "po folder path", commando.String, "").
+		AddFlag(poTmplFlag, "po file template", commando.String, "{culture}.po").
+		AddFlag(potFileFlag, "pot file name", commando.String, "messages.pot").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, arb2poCmd, flags, arb2po)
+		})
+	addArbFlags(arb2poCmd)
+
+	var po2arbCmd *commando.Command
+	po2arbCmd = commando.
+		Register("po2arb").
+		SetDescription("convert gettext po files to arb files").
+		SetShortDescription("import po to arb").
+		AddFlag(arbTemplateFlag, "arb file template", commando.String, defaultArbTemplate).
+		AddFlag(poPathFlag, "po folder path", commando.String, "").
+		AddFlag(poTmplFlag, "po file template", commando.String, "{culture}.po").
+		AddFlag(potFileFlag, "pot file name", commando.String, "messages.pot").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, po2arbCmd, flags, po2arb)
+		})
+	addArbFlags(po2arbCmd)
+
+	var arb2androidCmd *commando.Command
+	arb2androidCmd = commando.
+		Register("arb2android").
+		SetDescription("export arb to android resources: values-<locale>/strings.xml, default culture is written to values").
+		SetShortDescription("export arb to android").
+		AddFlag(arbTemplateFlag, "arb file template (* - any arb file in arb folder)", commando.String, anyArbTemplate).
+		AddFlag(androidPathFlag, "android res folder path", commando.String, "").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, arb2androidCmd, flags, arb2android)
+		})
+	addArbFlags(arb2androidCmd)
+
+	var arb2iosCmd *commando.Command
+	arb2iosCmd = commando.
+		Register("arb2ios").
+		SetDescription("export arb to ios resources: <locale>.lproj/Localizable.strings and Localizable.stringsdict").
+		SetShortDescription("export arb to ios").
+		AddFlag(arbTemplateFlag, "arb file template (* - any arb file in arb folder)", commando.String, anyArbTemplate).
+		AddFlag(iosPathFlag, "folder path of .lproj folders", commando.String, "").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, arb2iosCmd, flags, arb2ios)
+		})
+	addArbFlags(arb2iosCmd)
+
+	var android2csvCmd *commando.Command
+	android2csvCmd = commando.
+		Register("android2csv").
+		SetDescription("convert android strings.xml files to csv").
+		SetShortDescription("import android to csv").
+		AddFlag(androidPathFlag, "android res folder path", commando.String, "").
+		AddFlag(csvPathFlag, "path to csv or xlsx file", commando.String, "").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, android2csvCmd, flags, android2csv)
+		})
+	addNativeCsvFlags(android2csvCmd)
+
+	var ios2csvCmd *commando.Command
+	ios2csvCmd = commando.
+		Register("ios2csv").
+		SetDescription("convert ios Localizable.strings and Localizable.stringsdict files to csv").
+		SetShortDescription("import ios to csv").
+		AddFlag(iosPathFlag, "folder path of .lproj folders", commando.String, "").
+		AddFlag(csvPathFlag, "path to csv or xlsx file", commando.String, "").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, ios2csvCmd, flags, ios2csv)
+		})
+	addNativeCsvFlags(ios2csvCmd)
+
+	var arb2xcstringsCmd *commando.Command
+	arb2xcstringsCmd = commando.
+		Register("arb2xcstrings").
+		SetDescription("export arb to xcode string catalog (.xcstrings), default culture is a source language").
+		SetShortDescription("export arb to xcstrings").
+		AddFlag(arbTemplateFlag, "arb file template (* - any arb file in arb folder)", commando.String, anyArbTemplate).
+		AddFlag(xcstringsFlag, "path to .xcstrings file", commando.String, "").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, arb2xcstringsCmd, flags, arb2xcstrings)
+		})
+	addArbFlags(arb2xcstringsCmd)
+
+	var xcstrings2csvCmd *commando.Command
+	xcstrings2csvCmd = commando.
+		Register("xcstrings2csv").
+		SetDescription("convert xcode string catalog (.xcstrings) to csv").
+		SetShortDescription("import xcstrings to csv").
+		AddFlag(xcstringsFlag, "path to .xcstrings file", commando.String, "").
+		AddFlag(csvPathFlag, "path to csv or xlsx file", commando.String, "").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, xcstrings2csvCmd, flags, xcstrings2csv)
+		})
+	addNativeCsvFlags(xcstrings2csvCmd)
+
+	var diffCmd *commando.Command
+	diffCmd = commando.
+		Register("diff").
+		SetDescription("compare two sources, every source is arb folder, csv or xlsx file or csv url; exit code is 1 if they differ, 2 on errors").
+		SetShortDescription("compare arb folders and csv").
+		AddFlag(oldFlag, "old arb folder, csv or xlsx file or csv url", commando.String, "").
+		AddFlag(newFlag, "new arb folder, csv or xlsx file or csv url", commando.String, "").
+		AddFlag(arbTemplateFlag, "arb file template (* - any arb file in arb folder)", commando.String, anyArbTemplate).
+		AddFlag(formatFlag, "output format (text, json, markdown)", commando.String, string(arb.DiffText)).
+		AddFlag(colorFlag, "colors of text output (auto, always, never)", commando.String, colorAuto).
+		AddFlag(cultureFlag, "default culture", commando.String, "en").
+		AddFlag(logLevelFlag, "log level (trace, debug, info, warning, error, fatal, panic)", commando.String, "error").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, diffCmd, flags, diff)
+		})
+	addCsvFlags(diffCmd)
+	addWebFlags(diffCmd)
+
+	var lintCmd *commando.Command
+	lintCmd = commando.
+		Register("lint").
+		SetDescription("check arb folder, csv or xlsx file or csv url; exit code is 1 if there are findings with error severity").
+		SetShortDescription("check arb and csv").
+		AddFlag(sourceFlag, "arb folder, csv or xlsx file or csv url", commando.String, "").
+		AddFlag(arbTemplateFlag, "arb file template (* - any arb file in arb folder)", commando.String, anyArbTemplate).
+		AddFlag(formatFlag, "output format (text, json, sarif, junit)", commando.String, string(lint.FormatText)).
+		AddFlag(rulesFlag, "severities of rules (off, info, warning, error): missing-description=off,empty-translation=error", commando.String, noRules).
+		AddFlag(fixFlag, "remove orphan metadata from arb files", commando.Bool, false).
+		AddFlag(cultureFlag, "default culture", commando.String, "en").
+		AddFlag(logLevelFlag, "log level (trace, debug, info, warning, error, fatal, panic)", commando.String, "error").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, lintCmd, flags, lintArb)
+		})
+	addCsvFlags(lintCmd)
+	addWebFlags(lintCmd)
+
+	var statsCmd *commando.Command
+	statsCmd = commando.
+		Register("stats").
+		SetDescription("print translation coverage of arb folder, csv or xlsx file or csv url; exit code is 1 if coverage is below minimum").
+		SetShortDescription("translation coverage").
+		AddFlag(sourceFlag, "arb folder, csv or xlsx file or csv url", commando.String, "").
+		AddFlag(arbTemplateFlag, "arb file template (* - any arb file in arb folder)", commando.String, anyArbTemplate).
+		AddFlag(formatFlag, "output format (text, json, markdown, svg - badge of every culture)", commando.String, string(stats.FormatText)).
+		AddFlag(minCoverageFlag, "minimal coverage in percent, per culture: 90,ru=95,de=80", commando.String, noMinCoverage).
+		AddFlag(badgePathFlag, "folder of svg badges", commando.String, "badges").
+		AddFlag(cultureFlag, "default culture", commando.String, "en").
+		AddFlag(logLevelFlag, "log level (trace, debug, info, warning, error, fatal, panic)", commando.String, "error").
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, statsCmd, flags, statsArb)
+		})
+	addCsvFlags(statsCmd)
+	addWebFlags(statsCmd)
+
+	var pseudoCmd *commando.Command
+	pseudoCmd = commando.
+		Register("pseudo").
+		SetDescription("generate pseudo localized cultures from default culture of arb folder, csv or xlsx file or csv url and write them with other cultures to arb folder").
+		SetShortDescription("generate pseudo cultures").
+		AddFlag(sourceFlag, "arb folder, csv or xlsx file or csv url", commando.String, "").
+		AddFlag(arbTemplateFlag, "arb file template", commando.String, defaultArbTemplate).
+		AddFlag(accentedFlag, "culture with accented text (none - do not generate)", commando.String, pseudo.DefaultAccented).
+		AddFlag(bidiFlag, "culture with right-to-left text (none - do not generate)", commando.String, pseudo.DefaultBidi).
+		AddFlag(expansionFlag, "expansion of text length in percent", commando.Int, 30).
+		AddFlag("no-"+bracketsFlag, "do not wrap messages in [ ]", commando.Bool, false).
+		AddFlag(stampFlag, "write current time to @@last_modified of changed arb files", commando.Bool, false).
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, pseudoCmd, flags, pseudoArb)
+		})
+	addArbFlags(pseudoCmd)
+	addCsvFlags(pseudoCmd)
+	addWebFlags(pseudoCmd)
+
+	var translateCmd *commando.Command
+	translateCmd = commando.
+		Register("translate").
+		SetDescription("fill empty translations of arb folder, csv or xlsx file or csv url by machine translation of LibreTranslate compatible API, translations are marked with machine state").
+		SetShortDescription("machine translation of empty cells").
+		AddFlag(sourceFlag, "arb folder, csv or xlsx file or csv url", commando.String, "").
+		AddFlag(outFlag, "arb folder (path without extension) or csv or xlsx file of result (source - write to source)", commando.String, toSource).
+		AddFlag(arbTemplateFlag, "arb file template", commando.String, defaultArbTemplate).
+		AddFlag(providerURLFlag, "url of LibreTranslate compatible API", commando.String, "http://localhost:5000").
+		AddFlag(apiKeyFlag, "api key of translation API, none - without key (env - from "+apiKeyEnv+")", commando.String, fromEnv).
+		AddFlag(culturesFlag, "filled cultures separated by ',' (* - all cultures except default)", commando.String, allCultures).
+		AddFlag(batchSizeFlag, "count of texts in one request to translation API", commando.Int, translate.DefaultBatchSize).
+		SetAction(func(args map[string]commando.ArgValue, flags map[string]commando.FlagValue) {
+			baseAction(r, translateCmd, flags, translateArb)
+		})
+	addConvertFlags(translateCmd)
+	addCsvFlags(translateCmd)
+	addWebFlags(translateCmd)
+
 	commando.Parse(nil)
 }
 
 func addCommonFlags(c *commando.Command) *commando.Command {
+	addCsvFlags(c)
+	return addArbFlags(c)
+}
+
+// addCsvFlags adds flags of csv columns, xlsx sheet and csv dialect.
+func addCsvFlags(c *commando.Command) *commando.Command {
 	c.
-		AddFlag(arbPathFlag, "arb folder path (folder contains arb files - one for every culture)", commando.String, "").
 		AddFlag(colNameFlag, "name column name in csv table", commando.String, csv.ColName).
 		AddFlag(colDescrFlag, "name column name in csv table", commando.String, csv.ColDescr).
 		AddFlag(colParamsFlag, "name column name in csv table", commando.String, csv.ColParams).
+		AddFlag(colStatesFlag, "translation states column name in csv table", commando.String, csv.ColStates).
+		AddFlag(sheetFlag, "name or number of sheet of xlsx file", commando.String, "1")
+	return addDialectFlags(c)
+}
+
+// addWebFlags adds flags of loading csv from url.
+func addWebFlags(c *commando.Command) *commando.Command {
+	return c.
+		AddFlag(maxSizeFlag, "max size of csv file or download in megabytes (0 - no limit)", commando.Int, 1024).
+		AddFlag(tabsFlag, "gids of google sheets tabs with optional ranges: 0,123!A1:F200 (* - tab of url)", commando.String, urlTabs).
+		AddFlag(authFlag, "authorization of csv url: bearer:<token>, basic:<user>:<password>, none (env - from "+authEnv+")", commando.String, fromEnv).
+		AddFlag(headersFlag, "headers of csv url request separated by ';': X-Api-Key: <key>;X-Team: mobile (env - from "+headersEnv+")", commando.String, fromEnv).
+		AddFlag(timeoutFlag, "timeout of csv url request in seconds (0 - no timeout)", commando.Int, 60).
+		AddFlag(retriesFlag, "retries of csv url request failed with network error or 5xx status", commando.Int, 3).
+		AddFlag(proxyFlag, "proxy url (env - from HTTPS_PROXY, HTTP_PROXY, NO_PROXY)", commando.String, fromEnv).
+		AddFlag("no-"+cacheFlag, "do not use and do not update cache of downloaded csv", commando.Bool, false).
+		AddFlag(offlineFlag, "use cached csv without request", commando.Bool, false).
+		AddFlag(caFileFlag, "PEM file with trusted CA certificates in addition to system ones (system - only system ones)", commando.String, systemCA)
+}
+
+func addArbFlags(c *commando.Command) *commando.Command {
+	c.AddFlag(arbPathFlag, "arb folder path (folder contains arb files - one for every culture)", commando.String, "")
+	return addConvertFlags(c)
+}
+
+// addNativeCsvFlags adds flags of commands which convert native resources to csv.
+func addNativeCsvFlags(c *commando.Command) *commando.Command {
+	addCsvFlags(c)
+	return addConvertFlags(c)
+}
+
+func addConvertFlags(c *commando.Command) *commando.Command {
+	c.
 		AddFlag(cultureFlag, "default culture", commando.String, "en").
+		AddFlag(localeStyleFlag, "style of cultures in arb file names, @@locale and csv header (underscore, bcp47)", commando.String, string(locale.StyleUnderscore)).
+		AddFlag(orderFlag, "order of keys in output files (source, alpha, prefix)", commando.String, string(arb.OrderSource)).
+		AddFlag(checkPhFlag, "placeholders consistency check mode (off, warning, error)", commando.String, string(arb.CheckWarning)).
 		AddFlag(logLevelFlag, "log level (trace, debug, info, warning, error, fatal, panic)", commando.String, "error")
 	return c
 }
@@ -87,11 +439,36 @@ func baseAction(r *commando.CommandRegistry, c *commando.Command, flags map[stri
 	}
 
 	if err := action(logger, flags); err != nil {
-		logger.Fatal(err)
+		var exitErr *exitError
+		if !errors.As(err, &exitErr) {
+			logger.Fatal(err)
+		}
+		if exitErr.reported {
+			logger.Debug(exitErr.err)
+		} else {
+			logger.Error(exitErr.err)
+		}
+		os.Exit(exitErr.code)
 	}
 	logger.Traceln("success!!!")
 }
 
+// exitError is returned by actions with own exit codes.
+type exitError struct {
+	code int
+	err  error
+	// reported tells that result is written to output already, so err is not an error of command
+	reported bool
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
 func createLogger(levelFlag commando.FlagValue) (*logrus.Logger, error) {
 	level, err := levelFlag.GetString()
 	if err != nil {
@@ -120,3 +497,42 @@ func getStrFromFlag(flags map[string]commando.FlagValue, flagName string) string
 	s, _ := flags[flagName].GetString()
 	return s
 }
+
+func checkPlaceholders(logger *logrus.Logger, flags map[string]commando.FlagValue, arbData *arb.Data) error {
+	mode, err := arb.ParseCheckMode(getStrFromFlag(flags, checkPhFlag))
+	if err != nil {
+		return err
+	}
+	return arb.ApplyPlaceholdersCheck(logger, arbData, mode)
+}
+
+// getArbTemplateFromFlag returns arb file template, empty template means any arb file.
+func getArbTemplateFromFlag(flags map[string]commando.FlagValue) string {
+	template := getStrFromFlag(flags, arbTemplateFlag)
+	if template == anyArbTemplate {
+		return ""
+	}
+	return template
+}
+
+// getTabsFromFlag returns gids of google sheets tabs, empty list means tab of url.
+func getTabsFromFlag(flags map[string]commando.FlagValue) []string {
+	var tabs []string
+	for _, tab := range strings.Split(getStrFromFlag(flags, tabsFlag), ",") {
+		tab = strings.TrimSpace(tab)
+		if tab != "" && tab != urlTabs {
+			tabs = append(tabs, tab)
+		}
+	}
+	return tabs
+}
+
+func getIntFromFlag(flags map[string]commando.FlagValue, flagName string) int {
+	i, _ := flags[flagName].GetInt()
+	return i
+}
+
+func getBoolFromFlag(flags map[string]commando.FlagValue, flagName string) bool {
+	b, _ := flags[flagName].GetBool()
+	return b
+}
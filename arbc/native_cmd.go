@@ -0,0 +1,80 @@
+package main
+
+import (
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/native"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thatisuday/commando"
+)
+
+func arb2android(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	return arb2native(logger, flags, androidPathFlag, native.SaveAndroid)
+}
+
+func arb2ios(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	return arb2native(logger, flags, iosPathFlag, native.SaveIos)
+}
+
+func android2csv(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	return native2csv(logger, flags, androidPathFlag, native.LoadAndroid)
+}
+
+func ios2csv(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	return native2csv(logger, flags, iosPathFlag, native.LoadIos)
+}
+
+func arb2xcstrings(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	return arb2native(logger, flags, xcstringsFlag, func(logger *logrus.Logger, params native.Params, arbData *arb.Data) error {
+		return native.SaveXcstrings(logger, params.FolderPath, params, arbData)
+	})
+}
+
+func xcstrings2csv(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	return native2csv(logger, flags, xcstringsFlag, func(logger *logrus.Logger, params native.Params) (*arb.Data, error) {
+		return native.LoadXcstrings(logger, params.FolderPath, params)
+	})
+}
+
+func arb2native(logger *logrus.Logger, flags map[string]commando.FlagValue, pathFlag string,
+	save func(*logrus.Logger, native.Params, *arb.Data) error) error {
+	arbData, err := arb.LoadArb(logger,
+		getStrFromFlag(flags, arbPathFlag),
+		getArbTemplateFromFlag(flags),
+		getStrFromFlag(flags, cultureFlag))
+	if err != nil {
+		return err
+	}
+
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	order, err := arb.ParseOrder(getStrFromFlag(flags, orderFlag))
+	if err != nil {
+		return err
+	}
+
+	return save(logger, native.Params{
+		FolderPath:     getStrFromFlag(flags, pathFlag),
+		DefaultCulture: getStrFromFlag(flags, cultureFlag),
+		Order:          order,
+	}, arbData)
+}
+
+func native2csv(logger *logrus.Logger, flags map[string]commando.FlagValue, pathFlag string,
+	load func(*logrus.Logger, native.Params) (*arb.Data, error)) error {
+	arbData, err := load(logger, native.Params{
+		FolderPath:     getStrFromFlag(flags, pathFlag),
+		DefaultCulture: getStrFromFlag(flags, cultureFlag),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	return saveCsv(logger, flags, getStrFromFlag(flags, csvPathFlag), arbData)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/evg1605/csv_arb/po"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thatisuday/commando"
+)
+
+func arb2po(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	arbData, err := arb.LoadArb(logger,
+		getStrFromFlag(flags, arbPathFlag),
+		getArbTemplateFromFlag(flags),
+		getStrFromFlag(flags, cultureFlag))
+	if err != nil {
+		return err
+	}
+
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	params, err := getPoParams(flags)
+	if err != nil {
+		return err
+	}
+	if err := po.SavePot(logger, params, arbData); err != nil {
+		return err
+	}
+	return po.SavePo(logger, params, arbData)
+}
+
+func po2arb(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	params, err := getPoParams(flags)
+	if err != nil {
+		return err
+	}
+
+	arbData, err := po.LoadPo(logger, params)
+	if err != nil {
+		return err
+	}
+
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	return arb.SaveArb(logger, arbData, arb.SaveParams{
+		FolderPath:     getStrFromFlag(flags, arbPathFlag),
+		FileTemplate:   getStrFromFlag(flags, arbTemplateFlag),
+		DefaultCulture: params.DefaultCulture,
+		Order:          params.Order,
+		LocaleStyle:    params.LocaleStyle,
+	})
+}
+
+func getPoParams(flags map[string]commando.FlagValue) (po.Params, error) {
+	order, err := arb.ParseOrder(getStrFromFlag(flags, orderFlag))
+	if err != nil {
+		return po.Params{}, err
+	}
+
+	localeStyle, err := locale.ParseStyle(getStrFromFlag(flags, localeStyleFlag))
+	if err != nil {
+		return po.Params{}, err
+	}
+
+	return po.Params{
+		FolderPath:     getStrFromFlag(flags, poPathFlag),
+		FileTemplate:   getStrFromFlag(flags, poTmplFlag),
+		PotFile:        getStrFromFlag(flags, potFileFlag),
+		DefaultCulture: getStrFromFlag(flags, cultureFlag),
+		Order:          order,
+		LocaleStyle:    localeStyle,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/evg1605/csv_arb/pseudo"
+	"github.com/sirupsen/logrus"
+	"github.com/thatisuday/commando"
+)
+
+func pseudoArb(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	order, err := arb.ParseOrder(getStrFromFlag(flags, orderFlag))
+	if err != nil {
+		return err
+	}
+	localeStyle, err := locale.ParseStyle(getStrFromFlag(flags, localeStyleFlag))
+	if err != nil {
+		return err
+	}
+
+	arbData, err := loadSource(logger, flags, getStrFromFlag(flags, sourceFlag))
+	if err != nil {
+		return err
+	}
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	defaultCulture := getStrFromFlag(flags, cultureFlag)
+	err = pseudo.Generate(logger, arbData, pseudo.Params{
+		DefaultCulture: defaultCulture,
+		Accented:       getPseudoCultureFromFlag(flags, accentedFlag),
+		Bidi:           getPseudoCultureFromFlag(flags, bidiFlag),
+		Expansion:      float64(getIntFromFlag(flags, expansionFlag)) / 100,
+		Brackets:       getBoolFromFlag(flags, bracketsFlag),
+	})
+	if err != nil {
+		return err
+	}
+
+	return arb.SaveArb(logger, arbData, arb.SaveParams{
+		FolderPath:        getStrFromFlag(flags, arbPathFlag),
+		FileTemplate:      getStrFromFlag(flags, arbTemplateFlag),
+		DefaultCulture:    defaultCulture,
+		Order:             order,
+		StampLastModified: getBoolFromFlag(flags, stampFlag),
+		LocaleStyle:       localeStyle,
+	})
+}
+
+// getPseudoCultureFromFlag returns pseudo culture, empty culture is not generated.
+func getPseudoCultureFromFlag(flags map[string]commando.FlagValue, flagName string) string {
+	culture := getStrFromFlag(flags, flagName)
+	if culture == noPseudo {
+		return ""
+	}
+	return culture
+}
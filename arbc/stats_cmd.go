@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/evg1605/csv_arb/stats"
+	"github.com/sirupsen/logrus"
+	"github.com/thatisuday/commando"
+)
+
+func statsArb(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	format, err := stats.ParseFormat(getStrFromFlag(flags, formatFlag))
+	if err != nil {
+		return err
+	}
+	minCoverage := stats.MinCoverage{}
+	if s := getStrFromFlag(flags, minCoverageFlag); s != noMinCoverage {
+		if minCoverage, err = stats.ParseMinCoverage(s); err != nil {
+			return err
+		}
+	}
+
+	arbData, err := loadSource(logger, flags, getStrFromFlag(flags, sourceFlag))
+	if err != nil {
+		return err
+	}
+	report, err := stats.Compute(arbData, getStrFromFlag(flags, cultureFlag))
+	if err != nil {
+		return err
+	}
+
+	if format == stats.FormatSVG {
+		paths, err := report.SaveBadges(getStrFromFlag(flags, badgePathFlag))
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			fmt.Println(p)
+		}
+	} else if err := report.Write(os.Stdout, format); err != nil {
+		return err
+	}
+
+	return report.CheckCoverage(minCoverage)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/evg1605/csv_arb/translate"
+	"github.com/sirupsen/logrus"
+	"github.com/thatisuday/commando"
+)
+
+const apiKeyEnv = "ARBC_TRANSLATE_API_KEY"
+
+func translateArb(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	src := getStrFromFlag(flags, sourceFlag)
+	out := getStrFromFlag(flags, outFlag)
+	if out == toSource {
+		if isURL(src) {
+			return fmt.Errorf("csv url can not be written, use --%s", outFlag)
+		}
+		out = src
+	}
+
+	arbData, err := loadSource(logger, flags, src)
+	if err != nil {
+		return err
+	}
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	apiKey := getStrFromFlag(flags, apiKeyFlag)
+	switch apiKey {
+	case fromEnv:
+		apiKey = os.Getenv(apiKeyEnv)
+	case "none":
+		apiKey = ""
+	}
+	provider := &translate.LibreTranslate{
+		URL:    getStrFromFlag(flags, providerURLFlag),
+		APIKey: apiKey,
+		Client: &http.Client{Timeout: time.Duration(getIntFromFlag(flags, timeoutFlag)) * time.Second},
+	}
+
+	var cultures []string
+	for _, c := range strings.Split(getStrFromFlag(flags, culturesFlag), ",") {
+		if c = strings.TrimSpace(c); c != "" && c != allCultures {
+			cultures = append(cultures, c)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fills, err := translate.Translate(ctx, logger, arbData, provider, translate.Params{
+		DefaultCulture: getStrFromFlag(flags, cultureFlag),
+		Cultures:       cultures,
+		BatchSize:      getIntFromFlag(flags, batchSizeFlag),
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range fills {
+		fmt.Println(f)
+	}
+
+	if !isArbFolder(out) && filepath.Ext(out) != "" {
+		return saveCsv(logger, flags, out, arbData)
+	}
+	order, err := arb.ParseOrder(getStrFromFlag(flags, orderFlag))
+	if err != nil {
+		return err
+	}
+	localeStyle, err := locale.ParseStyle(getStrFromFlag(flags, localeStyleFlag))
+	if err != nil {
+		return err
+	}
+	return arb.SaveArb(logger, arbData, arb.SaveParams{
+		FolderPath:     out,
+		FileTemplate:   getStrFromFlag(flags, arbTemplateFlag),
+		DefaultCulture: getStrFromFlag(flags, cultureFlag),
+		Order:          order,
+		LocaleStyle:    localeStyle,
+	})
+}
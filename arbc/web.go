@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/evg1605/csv_arb/csv"
+	"github.com/thatisuday/commando"
+)
+
+const (
+	// fromEnv is a value of flags which are read from environment variables,
+	// so tokens are not visible in command line of CI logs.
+	fromEnv  = "env"
+	systemCA = "system"
+
+	// cacheFolder is a folder of downloaded csv in user cache folder.
+	cacheFolder = "arbc"
+
+	authEnv    = "ARBC_AUTH"
+	headersEnv = "ARBC_HEADERS"
+)
+
+func getWebParams(flags map[string]commando.FlagValue) (csv.WebParams, error) {
+	params := csv.WebParams{
+		Timeout: time.Duration(getIntFromFlag(flags, timeoutFlag)) * time.Second,
+		Retries: getIntFromFlag(flags, retriesFlag),
+	}
+
+	auth := getStrFromFlag(flags, authFlag)
+	if auth == fromEnv {
+		auth = os.Getenv(authEnv)
+	}
+	kind, value := auth, ""
+	if i := strings.Index(auth, ":"); i >= 0 {
+		kind, value = auth[:i], auth[i+1:]
+	}
+	switch kind {
+	case "", "none":
+	case "bearer":
+		params.BearerToken = value
+	case "basic":
+		i := strings.Index(value, ":")
+		if i < 0 {
+			return params, fmt.Errorf("basic authorization must be basic:<user>:<password>")
+		}
+		params.Username, params.Password = value[:i], value[i+1:]
+	default:
+		return params, fmt.Errorf("unknown authorization %s, expected bearer, basic or none", kind)
+	}
+
+	headers := getStrFromFlag(flags, headersFlag)
+	if headers == fromEnv {
+		headers = os.Getenv(headersEnv)
+	}
+	for _, h := range strings.Split(headers, ";") {
+		if strings.TrimSpace(h) == "" {
+			continue
+		}
+		i := strings.Index(h, ":")
+		if i < 0 {
+			return params, fmt.Errorf("invalid header %q, expected <name>: <value>", h)
+		}
+		if params.Headers == nil {
+			params.Headers = make(http.Header)
+		}
+		params.Headers.Add(strings.TrimSpace(h[:i]), strings.TrimSpace(h[i+1:]))
+	}
+
+	if proxy := getStrFromFlag(flags, proxyFlag); proxy != fromEnv {
+		params.ProxyURL = proxy
+	}
+	if caFile := getStrFromFlag(flags, caFileFlag); caFile != systemCA {
+		params.CAFile = caFile
+	}
+
+	params.Offline = getBoolFromFlag(flags, offlineFlag)
+	if getBoolFromFlag(flags, cacheFlag) {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return params, fmt.Errorf("cache folder not found (%v), use --no-%s", err, cacheFlag)
+		}
+		params.CacheDir = filepath.Join(cacheDir, cacheFolder)
+	}
+	return params, nil
+}
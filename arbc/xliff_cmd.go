@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/evg1605/csv_arb/xliff"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thatisuday/commando"
+)
+
+func arb2xliff(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	arbData, err := arb.LoadArb(logger,
+		getStrFromFlag(flags, arbPathFlag),
+		getArbTemplateFromFlag(flags),
+		getStrFromFlag(flags, cultureFlag))
+	if err != nil {
+		return err
+	}
+
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	params, err := getXliffParams(flags)
+	if err != nil {
+		return err
+	}
+	if params.Version, err = xliff.ParseVersion(getStrFromFlag(flags, xliffVerFlag)); err != nil {
+		return err
+	}
+	return xliff.SaveXliff(logger, params, arbData)
+}
+
+func xliff2arb(logger *logrus.Logger, flags map[string]commando.FlagValue) error {
+	arbData, err := arb.LoadArb(logger,
+		getStrFromFlag(flags, arbPathFlag),
+		getStrFromFlag(flags, arbTemplateFlag),
+		getStrFromFlag(flags, cultureFlag))
+	if err != nil {
+		return err
+	}
+
+	params, err := getXliffParams(flags)
+	if err != nil {
+		return err
+	}
+	if err := xliff.MergeXliff(logger, arbData, params); err != nil {
+		return err
+	}
+
+	if err := checkPlaceholders(logger, flags, arbData); err != nil {
+		return err
+	}
+
+	return arb.SaveArb(logger, arbData, arb.SaveParams{
+		FolderPath:     getStrFromFlag(flags, arbPathFlag),
+		FileTemplate:   getStrFromFlag(flags, arbTemplateFlag),
+		DefaultCulture: params.DefaultCulture,
+		Order:          params.Order,
+		LocaleStyle:    params.LocaleStyle,
+	})
+}
+
+func getXliffParams(flags map[string]commando.FlagValue) (xliff.Params, error) {
+	order, err := arb.ParseOrder(getStrFromFlag(flags, orderFlag))
+	if err != nil {
+		return xliff.Params{}, err
+	}
+
+	localeStyle, err := locale.ParseStyle(getStrFromFlag(flags, localeStyleFlag))
+	if err != nil {
+		return xliff.Params{}, err
+	}
+
+	return xliff.Params{
+		FolderPath:     getStrFromFlag(flags, xliffPathFlag),
+		FileTemplate:   getStrFromFlag(flags, xliffTmplFlag),
+		DefaultCulture: getStrFromFlag(flags, cultureFlag),
+		Order:          order,
+		LocaleStyle:    localeStyle,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// webCache keeps the last downloaded copy of every url: <sha256 of url>.csv with body
+// and <sha256 of url>.json with validators of conditional requests.
+type webCache struct {
+	dir string
+}
+
+type cacheMeta struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Downloaded   time.Time `json:"downloaded"`
+}
+
+func (c *webCache) paths(url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	name := filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+	return name + ".csv", name + ".json"
+}
+
+// meta returns metadata of cached copy of url, false if url is not cached.
+func (c *webCache) meta(url string) (*cacheMeta, bool) {
+	dataPath, metaPath := c.paths(url)
+	raw, err := ioutil.ReadFile(metaPath)
+	if err != nil {
+		return nil, false
+	}
+	var meta cacheMeta
+	if err := json.Unmarshal(raw, &meta); err != nil || meta.URL != url {
+		return nil, false
+	}
+	if _, err := os.Stat(dataPath); err != nil {
+		return nil, false
+	}
+	return &meta, true
+}
+
+func (c *webCache) open(url string) (io.ReadCloser, error) {
+	dataPath, _ := c.paths(url)
+	return os.Open(dataPath)
+}
+
+// conditionalHeader returns headers of conditional request for cached copy.
+func (meta *cacheMeta) conditionalHeader() http.Header {
+	h := make(http.Header)
+	if meta.ETag != "" {
+		h.Set("If-None-Match", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		h.Set("If-Modified-Since", meta.LastModified)
+	}
+	return h
+}
+
+// store returns body of response which is copied to cache while it is read.
+// Copy replaces cached copy only if body is read to the end, errors of cache are logged and ignored.
+func (c *webCache) store(logger *logrus.Logger, url string, resp *http.Response) io.ReadCloser {
+	if err := os.MkdirAll(c.dir, 0777); err != nil {
+		logger.Warningf("create cache folder error: %v", err)
+		return resp.Body
+	}
+	tmp, err := ioutil.TempFile(c.dir, "download-*")
+	if err != nil {
+		logger.Warningf("create cache file error: %v", err)
+		return resp.Body
+	}
+	return &cacheWriter{
+		logger: logger,
+		body:   resp.Body,
+		tmp:    tmp,
+		commit: func() error {
+			dataPath, metaPath := c.paths(url)
+			if err := os.Rename(tmp.Name(), dataPath); err != nil {
+				return err
+			}
+			raw, err := json.Marshal(&cacheMeta{
+				URL:          url,
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+				Downloaded:   time.Now().UTC(),
+			})
+			if err != nil {
+				return err
+			}
+			return ioutil.WriteFile(metaPath, raw, 0644)
+		},
+	}
+}
+
+type cacheWriter struct {
+	logger *logrus.Logger
+	body   io.ReadCloser
+	tmp    *os.File
+	commit func() error
+	done   bool
+}
+
+func (w *cacheWriter) Read(p []byte) (int, error) {
+	n, err := w.body.Read(p)
+	if w.tmp != nil && n > 0 {
+		if _, werr := w.tmp.Write(p[:n]); werr != nil {
+			w.logger.Warningf("write cache file error: %v", werr)
+			w.discard()
+		}
+	}
+	if err == io.EOF && w.tmp != nil && !w.done {
+		w.done = true
+		if cerr := w.tmp.Close(); cerr != nil {
+			w.logger.Warningf("close cache file error: %v", cerr)
+			w.discard()
+		} else if cerr := w.commit(); cerr != nil {
+			w.logger.Warningf("save cache error: %v", cerr)
+			w.discard()
+		}
+	}
+	return n, err
+}
+
+func (w *cacheWriter) Close() error {
+	if !w.done {
+		w.discard()
+	}
+	return w.body.Close()
+}
+
+// discard removes incomplete copy, cached copy is not changed.
+func (w *cacheWriter) discard() {
+	if w.tmp == nil {
+		return
+	}
+	w.tmp.Close()
+	os.Remove(w.tmp.Name())
+	w.tmp = nil
+}
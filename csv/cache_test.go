@@ -0,0 +1,109 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadArbFromWebCache(t *testing.T) {
+	var requests, notModified int32
+	content := "name,en\nhello,Hello\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/etag":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				atomic.AddInt32(&notModified, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+		case "/modified":
+			if r.Header.Get("If-Modified-Since") == "Mon, 02 Jan 2006 15:04:05 GMT" {
+				atomic.AddInt32(&notModified, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		case "/login":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<html>sign in</html>")
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	params := Params{ColumnName: ColName, DefaultCulture: "en"}
+	webParams := WebParams{CacheDir: dir}
+	load := func(path string, webParams WebParams) (string, error) {
+		arbData, err := LoadArbFromWeb(context.Background(), createLogger(), server.URL+path, params, webParams)
+		if err != nil {
+			return "", err
+		}
+		return arbData.Items["hello"].Cultures["en"], nil
+	}
+
+	for _, path := range []string{"/etag", "/modified"} {
+		v, err := load(path, webParams)
+		require.NoError(t, err)
+		require.Equal(t, "Hello", v)
+	}
+
+	// cached copies are revalidated
+	content = "name,en\nhello,Changed\n"
+	for _, path := range []string{"/etag", "/modified"} {
+		v, err := load(path, webParams)
+		require.NoError(t, err)
+		require.Equal(t, "Hello", v)
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&notModified))
+
+	// without cache body is downloaded
+	v, err := load("/etag", WebParams{})
+	require.NoError(t, err)
+	require.Equal(t, "Changed", v)
+
+	// offline mode does not send requests
+	atomic.StoreInt32(&requests, 0)
+	v, err = load("/modified", WebParams{CacheDir: dir, Offline: true})
+	require.NoError(t, err)
+	require.Equal(t, "Hello", v)
+	require.Equal(t, int32(0), atomic.LoadInt32(&requests))
+
+	_, err = load("/other", WebParams{CacheDir: dir, Offline: true})
+	require.ErrorIs(t, err, ErrNotCached)
+	_, err = load("/etag", WebParams{Offline: true})
+	require.ErrorIs(t, err, ErrInvalidCsvParams)
+
+	// html pages are not cached
+	_, err = load("/login", webParams)
+	require.ErrorIs(t, err, ErrNotCsv)
+	_, err = load("/login", WebParams{CacheDir: dir, Offline: true})
+	require.ErrorIs(t, err, ErrNotCached)
+
+	// cached copy is used if server is not available
+	server.Close()
+	v, err = load("/etag", webParams)
+	require.NoError(t, err)
+	require.Equal(t, "Hello", v)
+
+	_, err = load("/other", webParams)
+	require.Error(t, err)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+}
@@ -1,14 +1,19 @@
 package csv
 
 import (
+	"context"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,52 +21,198 @@ const (
 	ColName   = "name"
 	ColDescr  = "description"
 	ColParams = "parameters"
+	ColStates = "states"
 )
 
+// rawAttribute is a description of global attribute row with json values, values of other rows are strings.
+const rawAttribute = "json"
+
 type Params struct {
 	ColumnName        string
 	ColumnDescription string
 	ColumnParameters  string
 	DefaultCulture    string
+	Order             arb.Order
+	// ColumnStates is a column of translation states of cultures: de=fuzzy;fr=machine. The column is read
+	// if it is present and written after cultures only if any translation has state.
+	ColumnStates string
+	// LocaleStyle defines how cultures are written to csv header.
+	LocaleStyle locale.Style
+	// Sheet is a name or number (from 1) of xlsx sheet, first sheet is used if it is empty.
+	Sheet string
+	// Tabs are gids of google sheets tabs with optional ranges of cells ("0", "123!A1:F200").
+	Tabs []string
+	// MaxSize limits size of csv file or download in bytes (uncompressed parts of xlsx), zero means no limit.
+	MaxSize int64
+	// Delimiter is a field delimiter of csv, ',' is used if it is zero.
+	Delimiter rune
+	// Encoding is an encoding of csv, utf-8 by default. BOM is skipped on reading, UTF-16 with BOM is
+	// detected for utf-8 too.
+	Encoding Encoding
+	// Comment is a prefix of comment lines which are skipped on reading, comments are not skipped if it is empty.
+	Comment string
+	// LazyQuotes allows quotes in unquoted fields and unescaped quotes in quoted fields.
+	LazyQuotes bool
+	// Excel writes csv with BOM and CRLF line endings like Excel does.
+	Excel bool
 }
 
 var (
 	ErrInvalidCsvParams    = errors.New("invalid csv params")
 	ErrInvalidCsvStructure = errors.New("invalid csv")
 	ErrInvalidArbStructure = errors.New("invalid arb")
+	ErrNotCsv              = errors.New("response is not csv")
+	ErrNotCached           = errors.New("url is not cached")
+	ErrTooLarge            = errors.New("csv is too large")
 )
 
 type csvIndexes struct {
 	name             int
 	description      *int
 	parameters       *int
+	states           *int
 	cultures         map[string]int
 	countFieldsInRow int
 }
 
-func LoadArbFromWeb(logger *logrus.Logger, csvUrl string, csvParams Params) (*arb.Data, error) {
-	logger.Tracef("download csv from url %s", csvUrl)
-	r, err := csvFromWeb(logger, csvUrl)
+// LoadArbFromWeb downloads csv. Google sheets links are rewritten to csv export of tabs from Params.Tabs
+// (or of tab from link), keys of all tabs are loaded to the same data.
+// Responses with non 2xx status are returned as *HTTPError.
+func LoadArbFromWeb(ctx context.Context, logger *logrus.Logger, csvUrl string, csvParams Params, webParams WebParams) (*arb.Data, error) {
+	if err := checkCsvParams(&csvParams); err != nil {
+		return nil, err
+	}
+	client, err := newWebClient(webParams)
+	if err != nil {
+		return nil, err
+	}
+
+	urls, ok, err := sheetsExportURLs(csvUrl, sheetsHost, csvParams.Tabs)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if len(csvParams.Tabs) > 0 {
+			return nil, fmt.Errorf("tabs are supported for google sheets urls only: %w", ErrInvalidCsvParams)
+		}
+		urls = []string{csvUrl}
+	}
+
+	var arbData *arb.Data
+	for _, u := range urls {
+		tabData, err := loadArbFromURL(ctx, logger, client, u, csvParams)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", u, err)
+		}
+		if arbData == nil {
+			arbData = tabData
+			continue
+		}
+		if err := mergeArbData(arbData, tabData, csvParams.DefaultCulture); err != nil {
+			return nil, fmt.Errorf("%s: %w", u, err)
+		}
+	}
+	return arbData, nil
+}
+
+// loadArbFromURL converts csv while it is downloaded.
+func loadArbFromURL(ctx context.Context, logger *logrus.Logger, client *webClient, url string, csvParams Params) (*arb.Data, error) {
+	logger.Tracef("download csv from url %s", url)
+	body, err := openWeb(ctx, logger, client, url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := body.Close(); err != nil {
+			logger.Warningf("close response body error: %v", err)
+		}
+	}()
+
+	arbData, err := convertCsvToArb(logger, newCsvReader(body, csvParams), csvParams)
 	if err != nil {
 		return nil, err
 	}
 	logger.Traceln("csv downloaded")
+	return arbData, nil
+}
+
+// mergeArbData adds keys, cultures and attributes of src to dst, keys must be unique.
+func mergeArbData(dst, src *arb.Data, defaultCulture string) error {
+	for _, key := range src.Keys {
+		if _, ok := dst.Items[key]; ok {
+			return fmt.Errorf("found more than one key with same Name %s: %w", key, ErrInvalidCsvStructure)
+		}
+		dst.Items[key] = src.Items[key]
+		dst.Keys = append(dst.Keys, key)
+	}
 
-	return convertCsvToArb(logger, r, csvParams)
+	cultures := make(map[string]struct{}, len(dst.Cultures))
+	for _, c := range dst.Cultures {
+		cultures[c] = struct{}{}
+	}
+	for _, c := range src.Cultures {
+		if _, ok := cultures[c]; !ok {
+			dst.Cultures = append(dst.Cultures, c)
+		}
+	}
+	dst.Cultures = dst.OrderedCultures(defaultCulture)
+
+	for c, attributes := range src.Attributes {
+		for name, v := range attributes {
+			if _, ok := dst.Attributes[c][name]; !ok {
+				dst.SetAttribute(c, name, v, src.RawAttributes[c][name])
+			}
+		}
+	}
+	return nil
 }
 
+// LoadArbFromFile loads csv file or sheet of xlsx workbook (by .xlsx extension of file).
+// Comments of name cells of xlsx sheet are used as descriptions of keys without description.
 func LoadArbFromFile(logger *logrus.Logger, csvPath string, csvParams Params) (*arb.Data, error) {
 	logger.Tracef("load csv from file %s", csvPath)
-	r, err := csvFromFile(logger, csvPath)
+	if isXlsx(csvPath) {
+		r, err := xlsxFromFile(logger, csvPath, csvParams.Sheet, csvParams.MaxSize)
+		if err != nil {
+			return nil, err
+		}
+		return convertCsvToArb(logger, r, csvParams)
+	}
+
+	csvFile, err := os.Open(csvPath)
 	if err != nil {
 		return nil, err
 	}
-	logger.Traceln("csv loaded")
+	defer func() {
+		if err := csvFile.Close(); err != nil {
+			logger.Warningf("close csv file error: %v", err)
+		}
+	}()
 
-	return convertCsvToArb(logger, r, csvParams)
+	arbData, err := convertCsvToArb(logger, newCsvReader(csvFile, csvParams), csvParams)
+	if err != nil {
+		return nil, err
+	}
+	logger.Traceln("csv loaded")
+	return arbData, nil
 }
 
+// SaveArb writes csv file or xlsx workbook (by .xlsx extension of file). Header of xlsx sheet is frozen,
+// sheet is protected and only name column is locked. Csv is written with Delimiter in Encoding, with BOM
+// and CRLF line endings if Params.Excel is set.
 func SaveArb(logger *logrus.Logger, csvPath string, csvParams Params, arbData *arb.Data) error {
+	if isXlsx(csvPath) {
+		w := &sheetWriter{}
+		indexes, err := writeArb(logger, w, csvParams, arbData)
+		if err != nil {
+			return err
+		}
+		return w.save(logger, csvPath, csvParams.Sheet, indexes.name)
+	}
+
+	if err := checkDialect(csvParams); err != nil {
+		return err
+	}
 	csvFile, err := os.Create(csvPath)
 	if err != nil {
 		return err
@@ -72,47 +223,113 @@ func SaveArb(logger *logrus.Logger, csvPath string, csvParams Params, arbData *a
 		}
 	}()
 
-	w := csv.NewWriter(csvFile)
-	defer w.Flush()
+	dst, err := encodeWriter(csvFile, csvParams.Encoding, csvParams.Excel)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(dst)
+	if csvParams.Delimiter != 0 {
+		w.Comma = csvParams.Delimiter
+	}
+	w.UseCRLF = csvParams.Excel
+
+	if _, err := writeArb(logger, w, csvParams, arbData); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
 
-	indexes := createFieldsIndexes(logger, arbData)
+func writeArb(logger *logrus.Logger, w rowWriter, csvParams Params, arbData *arb.Data) (*csvIndexes, error) {
+	defaultCulture, err := locale.Canonical(csvParams.DefaultCulture)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DefaultCulture (%v): %w", err, ErrInvalidCsvParams)
+	}
+	indexes := createFieldsIndexes(logger, arbData.OrderedCultures(defaultCulture), csvParams.ColumnStates != "" && hasStates(arbData))
 
 	if err := writeHeader(logger, w, csvParams, indexes); err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := writeAttributes(logger, w, indexes, arbData); err != nil {
+		return nil, err
+	}
+
+	return indexes, writeItems(logger, w, indexes, arbData.OrderedKeys(csvParams.Order), arbData.Items)
+}
+
+// writeAttributes writes global attributes as rows with @@ names.
+// @@locale and @@last_modified are not written, they are generated by arb writer.
+// Rows of attributes with json values have rawAttribute description, all their values are written as json.
+func writeAttributes(logger *logrus.Logger, w rowWriter, indexes *csvIndexes, arbData *arb.Data) error {
+	names := make(map[string]bool)
+	for c, cultureAttributes := range arbData.Attributes {
+		for name := range cultureAttributes {
+			if name != arb.AttrLocale && name != arb.AttrLastModified {
+				names[name] = names[name] || arbData.RawAttributes[c][name]
+			}
+		}
+	}
+
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
 	}
+	sort.Strings(sortedNames)
 
-	return writeItems(logger, w, indexes, arbData.Items)
+	for _, name := range sortedNames {
+		record := make([]string, indexes.countFieldsInRow)
+		record[indexes.name] = name
+		raw := names[name]
+		if raw {
+			record[*indexes.description] = rawAttribute
+		}
+		for c, cInd := range indexes.cultures {
+			v, ok := arbData.Attributes[c][name]
+			if ok && raw && !arbData.RawAttributes[c][name] {
+				buf, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				v = string(buf)
+			}
+			record[cInd] = v
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func writeHeader(logger *logrus.Logger, w *csv.Writer, csvParams Params, indexes *csvIndexes) error {
+func writeHeader(logger *logrus.Logger, w rowWriter, csvParams Params, indexes *csvIndexes) error {
 	records := make([]string, indexes.countFieldsInRow)
 	records[indexes.name] = csvParams.ColumnName
 	records[*indexes.description] = csvParams.ColumnDescription
 	records[*indexes.parameters] = csvParams.ColumnParameters
+	if indexes.states != nil {
+		records[*indexes.states] = csvParams.ColumnStates
+	}
 	for c, cInd := range indexes.cultures {
-		records[cInd] = c
+		records[cInd] = locale.Format(c, csvParams.LocaleStyle)
 	}
 	return w.Write(records)
 }
 
-func writeItems(logger *logrus.Logger, w *csv.Writer, indexes *csvIndexes, items map[string]*arb.Item) error {
-	for itemName, item := range items {
+func writeItems(logger *logrus.Logger, w rowWriter, indexes *csvIndexes, keys []string, items map[string]*arb.Item) error {
+	for _, itemName := range keys {
+		item := items[itemName]
 		record := make([]string, indexes.countFieldsInRow)
 
 		record[indexes.name] = itemName
 
 		record[*indexes.description] = item.Description
 
-		if len(item.Parameters) > 0 {
-			sb := &strings.Builder{}
-			for p := range item.Parameters {
-				if sb.Len() > 0 {
-					sb.WriteString(";")
-				}
-				sb.WriteString(p)
-			}
-			record[*indexes.parameters] = sb.String()
+		parameters, err := formatParameters(item.Parameters)
+		if err != nil {
+			return fmt.Errorf("format parameters of %s error: %w", itemName, err)
 		}
+		record[*indexes.parameters] = parameters
 
 		for c, v := range item.Cultures {
 			cInd, ok := indexes.cultures[c]
@@ -121,6 +338,9 @@ func writeItems(logger *logrus.Logger, w *csv.Writer, indexes *csvIndexes, items
 			}
 			record[cInd] = v
 		}
+		if indexes.states != nil {
+			record[*indexes.states] = formatStates(item.States)
+		}
 
 		if err := w.Write(record); err != nil {
 			return err
@@ -129,7 +349,7 @@ func writeItems(logger *logrus.Logger, w *csv.Writer, indexes *csvIndexes, items
 	return nil
 }
 
-func createFieldsIndexes(logger *logrus.Logger, arbData *arb.Data) *csvIndexes {
+func createFieldsIndexes(logger *logrus.Logger, cultures []string, states bool) *csvIndexes {
 	descriptionInd := 1
 	parametersInd := 2
 	indexes := &csvIndexes{
@@ -137,28 +357,39 @@ func createFieldsIndexes(logger *logrus.Logger, arbData *arb.Data) *csvIndexes {
 		description:      &descriptionInd,
 		parameters:       &parametersInd,
 		cultures:         make(map[string]int),
-		countFieldsInRow: len(arbData.Cultures) + parametersInd + 1,
+		countFieldsInRow: len(cultures) + parametersInd + 1,
 	}
 
-	for cInd, c := range arbData.Cultures {
+	for cInd, c := range cultures {
 		indexes.cultures[c] = parametersInd + cInd + 1
 	}
+	if states {
+		statesInd := indexes.countFieldsInRow
+		indexes.states = &statesInd
+		indexes.countFieldsInRow++
+	}
 	return indexes
 }
 
-func checkCsvParams(csvParams Params) error {
+// checkCsvParams checks params and converts DefaultCulture to canonical form.
+func checkCsvParams(csvParams *Params) error {
 	if csvParams.DefaultCulture == "" {
 		return fmt.Errorf("invalid DefaultCulture: %w", ErrInvalidCsvParams)
 	}
+	defaultCulture, err := locale.Canonical(csvParams.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("invalid DefaultCulture (%v): %w", err, ErrInvalidCsvParams)
+	}
+	csvParams.DefaultCulture = defaultCulture
 	if csvParams.ColumnName == "" {
 		return fmt.Errorf("invalid ColumnName: %w", ErrInvalidCsvParams)
 	}
-	return nil
+	return checkDialect(*csvParams)
 }
 
-func convertCsvToArb(logger *logrus.Logger, r *csv.Reader, csvParams Params) (*arb.Data, error) {
+func convertCsvToArb(logger *logrus.Logger, r rowReader, csvParams Params) (*arb.Data, error) {
 	logger.Traceln("convert csv to arb")
-	if err := checkCsvParams(csvParams); err != nil {
+	if err := checkCsvParams(&csvParams); err != nil {
 		return nil, err
 	}
 	fieldsIndexes, err := getFieldsIndexes(logger, r, csvParams)
@@ -166,27 +397,33 @@ func convertCsvToArb(logger *logrus.Logger, r *csv.Reader, csvParams Params) (*a
 		return nil, err
 	}
 
-	items, err := getArbItems(logger, r, fieldsIndexes)
+	arbData, err := getArbItems(logger, r, fieldsIndexes)
 	if err != nil {
 		return nil, err
 	}
 
-	var cultures []string
 	for cn := range fieldsIndexes.cultures {
-		cultures = append(cultures, cn)
+		arbData.Cultures = append(arbData.Cultures, cn)
 	}
+	arbData.Cultures = arbData.OrderedCultures(csvParams.DefaultCulture)
 
 	logger.Traceln("csv to arb converted")
-	return &arb.Data{
-		Cultures: cultures,
-		Items:    items,
-	}, nil
+	return arbData, nil
 }
 
-func getArbItems(logger *logrus.Logger, r *csv.Reader, fieldsIndexes *csvIndexes) (map[string]*arb.Item, error) {
-	items := make(map[string]*arb.Item)
+// getArbItems returns data with items, their keys in order of csv rows and global attributes.
+// Rows with names starting with @@ contain global attributes of cultures.
+func getArbItems(logger *logrus.Logger, r rowReader, fieldsIndexes *csvIndexes) (*arb.Data, error) {
+	arbData := &arb.Data{
+		Items:      make(map[string]*arb.Item),
+		Attributes: make(map[string]map[string]string),
+	}
+	items := arbData.Items
 
+	// first line is header
+	line := 1
 	for {
+		line++
 		row, err := r.Read()
 		if err != nil {
 			if err == io.EOF {
@@ -200,6 +437,30 @@ func getArbItems(logger *logrus.Logger, r *csv.Reader, fieldsIndexes *csvIndexes
 		}
 
 		name := row[fieldsIndexes.name]
+		if strings.HasPrefix(name, arb.AttrPrefix) {
+			raw := fieldsIndexes.description != nil && row[*fieldsIndexes.description] == rawAttribute
+			for cn, ci := range fieldsIndexes.cultures {
+				if row[ci] == "" {
+					continue
+				}
+				if !raw {
+					arbData.SetAttribute(cn, name, row[ci], false)
+					continue
+				}
+				var v interface{}
+				if err := json.Unmarshal([]byte(row[ci]), &v); err != nil {
+					return nil, fmt.Errorf("attribute %s for culture %s in row %d is not json (%v): %w", name, cn, line, err, ErrInvalidCsvStructure)
+				}
+				s, ok := v.(string)
+				if ok {
+					arbData.SetAttribute(cn, name, s, false)
+				} else {
+					arbData.SetAttribute(cn, name, row[ci], true)
+				}
+			}
+			continue
+		}
+
 		if _, ok := items[name]; ok {
 			return nil, fmt.Errorf("found more than one key with same Name %s: %w", name, ErrInvalidCsvStructure)
 		}
@@ -211,19 +472,14 @@ func getArbItems(logger *logrus.Logger, r *csv.Reader, fieldsIndexes *csvIndexes
 		if fieldsIndexes.description != nil {
 			i.Description = row[*fieldsIndexes.description]
 		}
+		if c, ok := r.(cellComments); ok && i.Description == "" {
+			i.Description = c.Comment(fieldsIndexes.name)
+		}
 
 		if fieldsIndexes.parameters != nil {
-			parameters := make(map[string]struct{})
-			parametersRaw := strings.Split(row[*fieldsIndexes.parameters], ";")
-			for _, p := range parametersRaw {
-				pName := strings.Trim(p, " ")
-				if pName == "" {
-					continue
-				}
-				if _, ok := parameters[pName]; ok {
-					return nil, fmt.Errorf("key %s has more than one parameter with Name %s : %w", name, pName, ErrInvalidCsvStructure)
-				}
-				parameters[pName] = struct{}{}
+			parameters, err := parseParameters(name, row[*fieldsIndexes.parameters])
+			if err != nil {
+				return nil, err
 			}
 			if len(parameters) > 0 {
 				i.Parameters = parameters
@@ -231,18 +487,32 @@ func getArbItems(logger *logrus.Logger, r *csv.Reader, fieldsIndexes *csvIndexes
 		}
 
 		for cn, ci := range fieldsIndexes.cultures {
+			if err := icu.Validate(row[ci]); err != nil {
+				return nil, fmt.Errorf("invalid message %s for culture %s in row %d (%v): %w", name, cn, line, err, ErrInvalidCsvStructure)
+			}
 			i.Cultures[cn] = row[ci]
 		}
 
+		if fieldsIndexes.states != nil {
+			states, err := parseStates(name, row[*fieldsIndexes.states])
+			if err != nil {
+				return nil, err
+			}
+			for cn, state := range states {
+				i.SetState(cn, state)
+			}
+		}
+
 		items[name] = i
+		arbData.Keys = append(arbData.Keys, name)
 	}
-	return items, nil
+	return arbData, nil
 }
 
-func getFieldsIndexes(logger *logrus.Logger, r *csv.Reader, csvParams Params) (*csvIndexes, error) {
+func getFieldsIndexes(logger *logrus.Logger, r rowReader, csvParams Params) (*csvIndexes, error) {
 	// read first row and get indexes of Name and Description fields
 
-	var nameInd, descriptionInd, parametersInd *int
+	var nameInd, descriptionInd, parametersInd, statesInd *int
 
 	cultures := make(map[string]int)
 
@@ -260,6 +530,9 @@ func getFieldsIndexes(logger *logrus.Logger, r *csv.Reader, csvParams Params) (*
 	if csvParams.ColumnParameters != "" {
 		m[csvParams.ColumnParameters] = &parametersInd
 	}
+	if csvParams.ColumnStates != "" {
+		m[csvParams.ColumnStates] = &statesInd
+	}
 
 	for i, f := range row {
 		if f == "" {
@@ -276,10 +549,14 @@ func getFieldsIndexes(logger *logrus.Logger, r *csv.Reader, csvParams Params) (*
 			continue
 		}
 
-		if _, ok := cultures[f]; ok {
+		culture, err := locale.Canonical(f)
+		if err != nil {
+			return nil, fmt.Errorf("column %d has invalid culture %q (%v): %w", i+1, f, err, ErrInvalidCsvStructure)
+		}
+		if _, ok := cultures[culture]; ok {
 			return nil, fmt.Errorf("each culture to be represented by only one column (%s): %w", f, ErrInvalidCsvStructure)
 		}
-		cultures[f] = i
+		cultures[culture] = i
 	}
 
 	if nameInd == nil {
@@ -297,7 +574,111 @@ func getFieldsIndexes(logger *logrus.Logger, r *csv.Reader, csvParams Params) (*
 		name:             *nameInd,
 		description:      descriptionInd,
 		parameters:       parametersInd,
+		states:           statesInd,
 		cultures:         cultures,
 		countFieldsInRow: len(row),
 	}, nil
 }
+
+// parseParameters parses parameters cell. Cell contains either list of parameter names separated by ";"
+// or json object in the same format as "placeholders" section of arb item metadata.
+func parseParameters(name, raw string) (map[string]*arb.Placeholder, error) {
+	raw = strings.Trim(raw, " ")
+	if strings.HasPrefix(raw, "{") {
+		var parameters map[string]*arb.Placeholder
+		if err := json.Unmarshal([]byte(raw), &parameters); err != nil {
+			return nil, fmt.Errorf("key %s has invalid parameters (%v): %w", name, err, ErrInvalidCsvStructure)
+		}
+		for pName, p := range parameters {
+			if p == nil {
+				parameters[pName] = &arb.Placeholder{}
+			}
+		}
+		return parameters, nil
+	}
+
+	parameters := make(map[string]*arb.Placeholder)
+	for _, p := range strings.Split(raw, ";") {
+		pName := strings.Trim(p, " ")
+		if pName == "" {
+			continue
+		}
+		if _, ok := parameters[pName]; ok {
+			return nil, fmt.Errorf("key %s has more than one parameter with Name %s : %w", name, pName, ErrInvalidCsvStructure)
+		}
+		parameters[pName] = &arb.Placeholder{}
+	}
+	return parameters, nil
+}
+
+// formatParameters is inverse of parseParameters: parameters without attributes are written as list of names,
+// otherwise as json object.
+func formatParameters(parameters map[string]*arb.Placeholder) (string, error) {
+	if len(parameters) == 0 {
+		return "", nil
+	}
+
+	names := make([]string, 0, len(parameters))
+	onlyNames := true
+	for pName, p := range parameters {
+		names = append(names, pName)
+		if p != nil && !p.IsEmpty() {
+			onlyNames = false
+		}
+	}
+
+	if !onlyNames {
+		buf, err := json.Marshal(parameters)
+		if err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	sort.Strings(names)
+	return strings.Join(names, ";"), nil
+}
+
+// parseStates parses states cell: list of <culture>=<state> separated by ";".
+func parseStates(name, raw string) (map[string]string, error) {
+	states := make(map[string]string)
+	for _, part := range strings.Split(raw, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		eq := strings.Index(part, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("key %s has invalid state %q, expected <culture>=<state>: %w", name, part, ErrInvalidCsvStructure)
+		}
+		culture, err := locale.Canonical(strings.TrimSpace(part[:eq]))
+		if err != nil {
+			return nil, fmt.Errorf("key %s has invalid state %q (%v): %w", name, part, err, ErrInvalidCsvStructure)
+		}
+		states[culture] = strings.TrimSpace(part[eq+1:])
+	}
+	return states, nil
+}
+
+// formatStates is inverse of parseStates, cultures are sorted.
+func formatStates(states map[string]string) string {
+	cultures := make([]string, 0, len(states))
+	for c := range states {
+		cultures = append(cultures, c)
+	}
+	sort.Strings(cultures)
+
+	parts := make([]string, len(cultures))
+	for i, c := range cultures {
+		parts[i] = c + "=" + states[c]
+	}
+	return strings.Join(parts, ";")
+}
+
+func hasStates(arbData *arb.Data) bool {
+	for _, item := range arbData.Items {
+		if len(item.States) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"encoding/csv"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
 	"testing"
 
+	"github.com/evg1605/csv_arb/arb"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 )
@@ -36,8 +40,24 @@ func TestGetFieldsIndexes(t *testing.T) {
 	require.Equal(t, 5, *indexes.parameters)
 }
 
+func TestGetFieldsIndexesCultures(t *testing.T) {
+	params := Params{ColumnName: "name", DefaultCulture: "en-US"}
+
+	r := csv.NewReader(bytes.NewReader([]byte(`name,en_US,pt_br,zh-hant-tw`)))
+	indexes, err := getFieldsIndexes(createLogger(), r, params)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"en-US": 1, "pt-BR": 2, "zh-Hant-TW": 3}, indexes.cultures)
+
+	for _, header := range []string{`name,en_US,comments`, `name,en_US,xx`, `name,en_US,en-us`} {
+		r := csv.NewReader(bytes.NewReader([]byte(header)))
+		_, err := getFieldsIndexes(createLogger(), r, params)
+		require.ErrorIs(t, err, ErrInvalidCsvStructure, header)
+	}
+}
+
 func TestGetItems(t *testing.T) {
-	csvData := fmt.Sprintf(`item1,descr1,par1;par2,val-ru-1,val-en-1
+	csvData := fmt.Sprintf(`@@x-project,,,demo-ru,demo
+item1,descr1,par1;par2,val-ru-1,val-en-1
 item2,descr2,para,val-ru-2,val-en-2
 item3,descr3,,val-ru-3,val-en-3`)
 
@@ -54,10 +74,13 @@ item3,descr3,,val-ru-3,val-en-3`)
 		countFieldsInRow: 5,
 	}
 
-	items, err := getArbItems(createLogger(), r, indexes)
+	arbData, err := getArbItems(createLogger(), r, indexes)
 	require.NoError(t, err)
-	require.NotNil(t, items)
+	require.NotNil(t, arbData)
+	items := arbData.Items
 	require.Len(t, items, 3)
+	require.Equal(t, []string{"item1", "item2", "item3"}, arbData.Keys)
+	require.Equal(t, map[string]map[string]string{"ru": {"@@x-project": "demo-ru"}, "en": {"@@x-project": "demo"}}, arbData.Attributes)
 
 	require.Contains(t, items, "item1")
 	require.Contains(t, items, "item2")
@@ -74,6 +97,152 @@ item3,descr3,,val-ru-3,val-en-3`)
 	require.Len(t, items["item3"].Parameters, 0)
 }
 
+func TestGetItemsInvalidMessage(t *testing.T) {
+	csvData := `item1,val-en-1
+item2,val-en-{count`
+
+	r := csv.NewReader(bytes.NewReader([]byte(csvData)))
+	indexes := &csvIndexes{
+		name:             0,
+		cultures:         map[string]int{"en": 1},
+		countFieldsInRow: 2,
+	}
+
+	_, err := getArbItems(createLogger(), r, indexes)
+	require.ErrorIs(t, err, ErrInvalidCsvStructure)
+	require.Contains(t, err.Error(), "item2")
+	require.Contains(t, err.Error(), "row 3")
+	require.Contains(t, err.Error(), "position 13")
+}
+
+func TestSaveArb(t *testing.T) {
+	arbData := &arb.Data{
+		Cultures: []string{"ru", "de", "en"},
+		Items: map[string]*arb.Item{
+			"b": {Description: "descr b", Cultures: map[string]string{"en": "en b", "ru": "ru b", "de": "de b"}},
+			"a": {Cultures: map[string]string{"en": "en a", "ru": "ru a"}, Parameters: map[string]*arb.Placeholder{"y": {}, "x": {}}},
+		},
+		Keys: []string{"b", "a"},
+		Attributes: map[string]map[string]string{
+			"en": {"@@x-project": "demo", arb.AttrLastModified: "2021-01-01T00:00:00Z", "@@x-count": "3", "@@x-generator": `{"name":"tool"}`},
+			"ru": {"@@author": "translator", "@@x-count": "three"},
+		},
+		RawAttributes: map[string]map[string]bool{"en": {"@@x-count": true, "@@x-generator": true}},
+	}
+
+	dir, err := ioutil.TempDir("", "csv")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	csvPath := path.Join(dir, "data.csv")
+
+	csvParams := Params{
+		ColumnName:        ColName,
+		ColumnDescription: ColDescr,
+		ColumnParameters:  ColParams,
+		DefaultCulture:    "en",
+		Order:             arb.OrderSource,
+	}
+	require.NoError(t, SaveArb(createLogger(), csvPath, csvParams, arbData))
+
+	buf, err := ioutil.ReadFile(csvPath)
+	require.NoError(t, err)
+	require.Equal(t, `name,description,parameters,en,de,ru
+@@author,,,,,translator
+@@x-count,json,,3,,"""three"""
+@@x-generator,json,,"{""name"":""tool""}",,
+@@x-project,,,demo,,
+b,descr b,,en b,de b,ru b
+a,,x;y,en a,,ru a
+`, string(buf))
+
+	loaded, err := LoadArbFromFile(createLogger(), csvPath, csvParams)
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "de", "ru"}, loaded.Cultures)
+	require.Equal(t, []string{"b", "a"}, loaded.Keys)
+	require.Equal(t, map[string]map[string]string{
+		"en": {"@@x-project": "demo", "@@x-count": "3", "@@x-generator": `{"name":"tool"}`},
+		"ru": {"@@author": "translator", "@@x-count": "three"},
+	}, loaded.Attributes)
+	require.Equal(t, arbData.RawAttributes, loaded.RawAttributes)
+}
+
+func TestSaveArbStates(t *testing.T) {
+	arbData := &arb.Data{
+		Cultures: []string{"en", "de", "ru"},
+		Items: map[string]*arb.Item{
+			"a": {Cultures: map[string]string{"en": "en a", "de": "de a", "ru": "ru a"}, States: map[string]string{"ru": arb.StateMachine, "de": arb.StateFuzzy}},
+			"b": {Cultures: map[string]string{"en": "en b"}},
+		},
+		Keys: []string{"a", "b"},
+	}
+
+	dir, err := ioutil.TempDir("", "csv")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	csvPath := path.Join(dir, "data.csv")
+
+	csvParams := Params{
+		ColumnName:        ColName,
+		ColumnDescription: ColDescr,
+		ColumnParameters:  ColParams,
+		ColumnStates:      ColStates,
+		DefaultCulture:    "en",
+		Order:             arb.OrderSource,
+	}
+	require.NoError(t, SaveArb(createLogger(), csvPath, csvParams, arbData))
+
+	buf, err := ioutil.ReadFile(csvPath)
+	require.NoError(t, err)
+	require.Equal(t, `name,description,parameters,en,de,ru,states
+a,,,en a,de a,ru a,de=fuzzy;ru=machine
+b,,,en b,,,
+`, string(buf))
+
+	loaded, err := LoadArbFromFile(createLogger(), csvPath, csvParams)
+	require.NoError(t, err)
+	require.Equal(t, arbData.Items["a"].States, loaded.Items["a"].States)
+	require.Empty(t, loaded.Items["b"].States)
+
+	_, err = parseStates("a", "ru")
+	require.ErrorIs(t, err, ErrInvalidCsvStructure)
+	_, err = parseStates("a", "xx=machine")
+	require.ErrorIs(t, err, ErrInvalidCsvStructure)
+
+	// states column is not written without states
+	delete(arbData.Items, "a")
+	arbData.Keys = []string{"b"}
+	require.NoError(t, SaveArb(createLogger(), csvPath, csvParams, arbData))
+	buf, err = ioutil.ReadFile(csvPath)
+	require.NoError(t, err)
+	require.Equal(t, "name,description,parameters,en,de,ru\nb,,,en b,,\n", string(buf))
+}
+
+func TestParseParameters(t *testing.T) {
+	parameters, err := parseParameters("item", ` min ; max `)
+	require.NoError(t, err)
+	require.Len(t, parameters, 2)
+	require.True(t, parameters["min"].IsEmpty())
+	require.True(t, parameters["max"].IsEmpty())
+
+	_, err = parseParameters("item", `min;min`)
+	require.ErrorIs(t, err, ErrInvalidCsvStructure)
+
+	raw := `{"count":{"type":"int","format":"compact"},"name":{}}`
+	parameters, err = parseParameters("item", raw)
+	require.NoError(t, err)
+	require.Len(t, parameters, 2)
+	require.Equal(t, &arb.Placeholder{Type: "int", Format: "compact"}, parameters["count"])
+	require.True(t, parameters["name"].IsEmpty())
+
+	formatted, err := formatParameters(parameters)
+	require.NoError(t, err)
+	require.Equal(t, raw, formatted)
+
+	formatted, err = formatParameters(map[string]*arb.Placeholder{"b": {}, "a": {}})
+	require.NoError(t, err)
+	require.Equal(t, "a;b", formatted)
+}
+
 func createLogger() *logrus.Logger {
 	logger := logrus.New()
 	logger.SetLevel(logrus.TraceLevel)
@@ -0,0 +1,259 @@
+package csv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is a text encoding of csv file.
+type Encoding string
+
+const (
+	// EncodingUTF8 is a default encoding, UTF-16 is detected by BOM on reading.
+	EncodingUTF8 Encoding = "utf-8"
+	// EncodingUTF16 is UTF-16 with BOM, little endian is used on writing and on reading without BOM.
+	EncodingUTF16   Encoding = "utf-16"
+	EncodingUTF16LE Encoding = "utf-16le"
+	EncodingUTF16BE Encoding = "utf-16be"
+	// EncodingWindows1251 is a cyrillic encoding of Excel in Russian Windows.
+	EncodingWindows1251 Encoding = "windows-1251"
+)
+
+var (
+	ErrInvalidEncoding = errors.New("invalid encoding")
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+func ParseEncoding(s string) (Encoding, error) {
+	switch e := Encoding(strings.ToLower(s)); e {
+	case EncodingUTF8, EncodingUTF16, EncodingUTF16LE, EncodingUTF16BE, EncodingWindows1251:
+		return e, nil
+	case "utf8":
+		return EncodingUTF8, nil
+	case "utf16":
+		return EncodingUTF16, nil
+	case "cp1251":
+		return EncodingWindows1251, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidEncoding)
+}
+
+// decodeReader returns reader of utf-8 text of src, BOM is skipped.
+func decodeReader(src io.Reader, encoding Encoding) io.Reader {
+	r := bufio.NewReader(src)
+	head, _ := r.Peek(3)
+	hasBOM := func(bom []byte) bool {
+		if len(head) >= len(bom) && string(head[:len(bom)]) == string(bom) {
+			_, _ = r.Discard(len(bom))
+			return true
+		}
+		return false
+	}
+
+	switch encoding {
+	case EncodingUTF16, EncodingUTF16LE, EncodingUTF16BE:
+		switch {
+		case encoding != EncodingUTF16LE && hasBOM(bomUTF16BE):
+			return &utf16Reader{src: r, order: binary.BigEndian}
+		case encoding != EncodingUTF16BE && hasBOM(bomUTF16LE):
+			return &utf16Reader{src: r, order: binary.LittleEndian}
+		case encoding == EncodingUTF16BE:
+			return &utf16Reader{src: r, order: binary.BigEndian}
+		}
+		return &utf16Reader{src: r, order: binary.LittleEndian}
+	case EncodingWindows1251:
+		return &cp1251Reader{src: r}
+	}
+
+	// UTF-16 files are saved by Excel as "Unicode text", they always have BOM
+	switch {
+	case hasBOM(bomUTF8):
+	case hasBOM(bomUTF16LE):
+		return &utf16Reader{src: r, order: binary.LittleEndian}
+	case hasBOM(bomUTF16BE):
+		return &utf16Reader{src: r, order: binary.BigEndian}
+	}
+	return r
+}
+
+// encodeWriter returns writer which converts utf-8 text to encoding, BOM is written if bom is set
+// or encoding is EncodingUTF16.
+func encodeWriter(dst io.Writer, encoding Encoding, bom bool) (io.Writer, error) {
+	writeBOM := func(b []byte) error {
+		if !bom && encoding != EncodingUTF16 {
+			return nil
+		}
+		_, err := dst.Write(b)
+		return err
+	}
+
+	switch encoding {
+	case "", EncodingUTF8:
+		return dst, writeBOM(bomUTF8)
+	case EncodingUTF16, EncodingUTF16LE:
+		return &runeWriter{dst: dst, encode: func(buf []byte, r rune) ([]byte, error) {
+			return appendUTF16(buf, r, binary.LittleEndian), nil
+		}}, writeBOM(bomUTF16LE)
+	case EncodingUTF16BE:
+		return &runeWriter{dst: dst, encode: func(buf []byte, r rune) ([]byte, error) {
+			return appendUTF16(buf, r, binary.BigEndian), nil
+		}}, writeBOM(bomUTF16BE)
+	case EncodingWindows1251:
+		return &runeWriter{dst: dst, encode: appendCP1251}, nil
+	}
+	return nil, fmt.Errorf("%s: %w", encoding, ErrInvalidEncoding)
+}
+
+type utf16Reader struct {
+	src     *bufio.Reader
+	order   binary.ByteOrder
+	out     []byte
+	pending *uint16
+}
+
+func (r *utf16Reader) Read(p []byte) (int, error) {
+	for len(r.out) < len(p) {
+		u, err := r.unit()
+		if err != nil {
+			if len(r.out) > 0 {
+				break
+			}
+			return 0, err
+		}
+
+		c := rune(u)
+		if utf16.IsSurrogate(c) {
+			low, err := r.unit()
+			switch {
+			case err != nil:
+				c = utf8.RuneError
+			case low < 0xDC00 || low > 0xDFFF:
+				// not a pair, the next unit is decoded separately
+				c = utf8.RuneError
+				r.pending = &low
+			default:
+				c = utf16.DecodeRune(c, rune(low))
+			}
+		}
+		var buf [utf8.UTFMax]byte
+		n := utf8.EncodeRune(buf[:], c)
+		r.out = append(r.out, buf[:n]...)
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *utf16Reader) unit() (uint16, error) {
+	if r.pending != nil {
+		u := *r.pending
+		r.pending = nil
+		return u, nil
+	}
+	var b [2]byte
+	if _, err := io.ReadFull(r.src, b[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, io.EOF
+		}
+		return 0, err
+	}
+	return r.order.Uint16(b[:]), nil
+}
+
+func appendUTF16(buf []byte, r rune, order binary.ByteOrder) []byte {
+	var b [2]byte
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		order.PutUint16(b[:], uint16(r1))
+		buf = append(buf, b[:]...)
+		r = r2
+	}
+	order.PutUint16(b[:], uint16(r))
+	return append(buf, b[:]...)
+}
+
+type cp1251Reader struct {
+	src *bufio.Reader
+	out []byte
+}
+
+func (r *cp1251Reader) Read(p []byte) (int, error) {
+	for len(r.out) < len(p) {
+		b, err := r.src.ReadByte()
+		if err != nil {
+			if len(r.out) > 0 {
+				break
+			}
+			return 0, err
+		}
+		if b < 0x80 {
+			r.out = append(r.out, b)
+			continue
+		}
+		var buf [utf8.UTFMax]byte
+		n := utf8.EncodeRune(buf[:], cp1251[b-0x80])
+		r.out = append(r.out, buf[:n]...)
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func appendCP1251(buf []byte, r rune) ([]byte, error) {
+	if r < 0x80 {
+		return append(buf, byte(r)), nil
+	}
+	for i, c := range cp1251 {
+		if c == r && c != utf8.RuneError {
+			return append(buf, byte(0x80+i)), nil
+		}
+	}
+	return nil, fmt.Errorf("%q can't be written in %s: %w", r, EncodingWindows1251, ErrInvalidEncoding)
+}
+
+// runeWriter encodes runes of utf-8 text, rune split between writes is kept till the next write.
+type runeWriter struct {
+	dst     io.Writer
+	encode  func([]byte, rune) ([]byte, error)
+	pending []byte
+}
+
+func (w *runeWriter) Write(p []byte) (int, error) {
+	text := append(w.pending, p...)
+	var out []byte
+	for len(text) > 0 && utf8.FullRune(text) {
+		r, size := utf8.DecodeRune(text)
+		var err error
+		if out, err = w.encode(out, r); err != nil {
+			return 0, err
+		}
+		text = text[size:]
+	}
+	w.pending = append([]byte(nil), text...)
+	if _, err := w.dst.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// cp1251 are runes of windows-1251 bytes from 0x80.
+var cp1251 = [128]rune{
+	'Ђ', 'Ѓ', '‚', 'ѓ', '„', '…', '†', '‡', '€', '‰', 'Љ', '‹', 'Њ', 'Ќ', 'Ћ', 'Џ',
+	'ђ', '‘', '’', '“', '”', '•', '–', '—', utf8.RuneError, '™', 'љ', '›', 'њ', 'ќ', 'ћ', 'џ',
+	' ', 'Ў', 'ў', 'Ј', '¤', 'Ґ', '¦', '§', 'Ё', '©', 'Є', '«', '¬', '­', '®', 'Ї',
+	'°', '±', 'І', 'і', 'ґ', 'µ', '¶', '·', 'ё', '№', 'є', '»', 'ј', 'Ѕ', 'ѕ', 'ї',
+	'А', 'Б', 'В', 'Г', 'Д', 'Е', 'Ж', 'З', 'И', 'Й', 'К', 'Л', 'М', 'Н', 'О', 'П',
+	'Р', 'С', 'Т', 'У', 'Ф', 'Х', 'Ц', 'Ч', 'Ш', 'Щ', 'Ъ', 'Ы', 'Ь', 'Э', 'Ю', 'Я',
+	'а', 'б', 'в', 'г', 'д', 'е', 'ж', 'з', 'и', 'й', 'к', 'л', 'м', 'н', 'о', 'п',
+	'р', 'с', 'т', 'у', 'ф', 'х', 'ц', 'ч', 'ш', 'щ', 'ъ', 'ы', 'ь', 'э', 'ю', 'я',
+}
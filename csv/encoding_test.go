@@ -0,0 +1,167 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEncoding(t *testing.T) {
+	for s, expected := range map[string]Encoding{
+		"utf-8": EncodingUTF8, "UTF8": EncodingUTF8, "utf-16": EncodingUTF16, "UTF-16LE": EncodingUTF16LE,
+		"utf-16be": EncodingUTF16BE, "windows-1251": EncodingWindows1251, "cp1251": EncodingWindows1251,
+	} {
+		e, err := ParseEncoding(s)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, e, s)
+	}
+	_, err := ParseEncoding("koi8-r")
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestDecodeReader(t *testing.T) {
+	text := "name;ru\nhello;Привет, мир 😀\n"
+	utf16le := []byte{0xFF, 0xFE}
+	for _, r := range text {
+		utf16le = appendUTF16(utf16le, r, binary.LittleEndian)
+	}
+
+	cases := []struct {
+		encoding Encoding
+		src      []byte
+	}{
+		{EncodingUTF8, []byte(text)},
+		{EncodingUTF8, append([]byte{0xEF, 0xBB, 0xBF}, text...)},
+		{EncodingUTF8, utf16le},
+		{EncodingUTF16, utf16le},
+		{EncodingUTF16LE, utf16le},
+		{EncodingUTF16LE, utf16le[2:]},
+		{EncodingWindows1251, []byte("name;ru\nhello;\xcf\xf0\xe8\xe2\xe5\xf2 \xb9\xa8\n")},
+	}
+	for i, c := range cases {
+		raw, err := ioutil.ReadAll(decodeReader(bytes.NewReader(c.src), c.encoding))
+		require.NoError(t, err, i)
+		if c.encoding == EncodingWindows1251 {
+			require.Equal(t, "name;ru\nhello;Привет №Ё\n", string(raw))
+			continue
+		}
+		require.Equal(t, text, string(raw), i)
+	}
+}
+
+func TestEncodeWriter(t *testing.T) {
+	text := "name,ru\nhello,Привет, мир 😀\n"
+	for _, encoding := range []Encoding{EncodingUTF8, EncodingUTF16, EncodingUTF16LE, EncodingUTF16BE} {
+		for _, bom := range []bool{false, true} {
+			var buf bytes.Buffer
+			w, err := encodeWriter(&buf, encoding, bom)
+			require.NoError(t, err)
+			// runes are split between writes
+			for _, b := range []byte(text) {
+				_, err := w.Write([]byte{b})
+				require.NoError(t, err)
+			}
+
+			// UTF-16 is detected by BOM only, EncodingUTF16 is always written with BOM
+			decoding := EncodingUTF8
+			if !bom {
+				decoding = encoding
+			}
+			raw, err := ioutil.ReadAll(decodeReader(&buf, decoding))
+			require.NoError(t, err)
+			require.Equal(t, text, string(raw), encoding)
+		}
+	}
+
+	var buf bytes.Buffer
+	w, err := encodeWriter(&buf, EncodingWindows1251, true)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("Ёлка №1"))
+	require.NoError(t, err)
+	require.Equal(t, "\xa8\xeb\xea\xe0 \xb91", buf.String())
+
+	_, err = w.Write([]byte("中文"))
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestCsvDialect(t *testing.T) {
+	params := Params{ColumnName: ColName, DefaultCulture: "en"}
+
+	cases := []struct {
+		params   Params
+		src      string
+		expected string
+	}{
+		{Params{}, "\xef\xbb\xbfname,en\nhello,Hello\n", "Hello"},
+		{Params{Delimiter: ';'}, "\xef\xbb\xbfname;en\r\nhello;Hello, world\r\n", "Hello, world"},
+		{Params{Delimiter: '\t'}, "name\ten\nhello\tHello\n", "Hello"},
+		{Params{Comment: "#"}, "# header\nname,en\n#note,\"\nhello,Hello\n", "Hello"},
+		{Params{Comment: "//"}, "name,en\n// todo,\nhello,Hello\n//hello2,Hello\n", "Hello"},
+		{Params{LazyQuotes: true}, "name,en\nhello,Hello \"world\"\n", "Hello \"world\""},
+	}
+	for i, c := range cases {
+		p := c.params
+		p.ColumnName, p.DefaultCulture = params.ColumnName, params.DefaultCulture
+		arbData, err := convertCsvToArb(createLogger(), newCsvReader(strings.NewReader(c.src), p), p)
+		require.NoError(t, err, i)
+		require.Equal(t, []string{"hello"}, arbData.Keys, i)
+		require.Equal(t, c.expected, arbData.Items["hello"].Cultures["en"], i)
+	}
+
+	_, err := convertCsvToArb(createLogger(), newCsvReader(strings.NewReader("name,en\nhello,Hello \"world\"\n"), params), params)
+	require.Error(t, err)
+
+	for _, p := range []Params{{Delimiter: '"'}, {Delimiter: '\n'}, {Comment: ","}, {Delimiter: ';', Comment: ";"}, {Encoding: "koi8-r"}} {
+		p.ColumnName, p.DefaultCulture = params.ColumnName, params.DefaultCulture
+		_, err := convertCsvToArb(createLogger(), newCsvReader(strings.NewReader("name,en\n"), p), p)
+		require.ErrorIs(t, err, ErrInvalidCsvParams)
+	}
+}
+
+func TestSaveArbExcel(t *testing.T) {
+	arbData := &arb.Data{
+		Cultures: []string{"en", "ru"},
+		Items: map[string]*arb.Item{
+			"hello": {Cultures: map[string]string{"en": "Hello", "ru": "Привет"}},
+		},
+		Keys: []string{"hello"},
+	}
+
+	dir, err := ioutil.TempDir("", "csv")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	csvPath := path.Join(dir, "data.csv")
+
+	csvParams := Params{
+		ColumnName:        ColName,
+		ColumnDescription: ColDescr,
+		ColumnParameters:  ColParams,
+		DefaultCulture:    "en",
+		Delimiter:         ';',
+		Excel:             true,
+	}
+	require.NoError(t, SaveArb(createLogger(), csvPath, csvParams, arbData))
+	buf, err := ioutil.ReadFile(csvPath)
+	require.NoError(t, err)
+	require.Equal(t, "\xef\xbb\xbfname;description;parameters;en;ru\r\nhello;;;Hello;Привет\r\n", string(buf))
+
+	csvParams.Encoding = EncodingWindows1251
+	require.NoError(t, SaveArb(createLogger(), csvPath, csvParams, arbData))
+	buf, err = ioutil.ReadFile(csvPath)
+	require.NoError(t, err)
+	require.Equal(t, "name;description;parameters;en;ru\r\nhello;;;Hello;\xcf\xf0\xe8\xe2\xe5\xf2\r\n", string(buf))
+
+	loaded, err := LoadArbFromFile(createLogger(), csvPath, csvParams)
+	require.NoError(t, err)
+	require.Equal(t, "Привет", loaded.Items["hello"].Cultures["ru"])
+
+	arbData.Items["hello"].Cultures["ru"] = "你好"
+	require.ErrorIs(t, SaveArb(createLogger(), csvPath, csvParams, arbData), ErrInvalidEncoding)
+}
@@ -0,0 +1,86 @@
+package csv
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// TabRangeSeparator separates gid of google sheets tab and range of cells in Params.Tabs, as in A1 notation.
+const TabRangeSeparator = "!"
+
+var (
+	// sheetsHost is a host of google sheets links, other hosts are not rewritten (tests change it to local server).
+	sheetsHost       = "docs.google.com"
+	sheetsPathRegexp = regexp.MustCompile(`^/spreadsheets/d/(e/)?([a-zA-Z0-9_-]+)(/.*)?$`)
+	gidRegexp        = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// sheetsExportURLs rewrites google sheets url (edit, view, share or publish link) to csv export urls of tabs.
+// Tabs are gids with optional ranges ("123", "123!A1:F200"), tab and range of url are used if tabs are empty.
+// The second result is false if url is not a google sheets url: its host is not sheetsHost or path is not of a spreadsheet.
+func sheetsExportURLs(rawURL, host string, tabs []string) ([]string, bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, false, err
+	}
+	if !strings.EqualFold(u.Host, host) {
+		return nil, false, nil
+	}
+	m := sheetsPathRegexp.FindStringSubmatch(u.Path)
+	if m == nil {
+		return nil, false, nil
+	}
+	published, id := m[1] != "", m[2]
+
+	if len(tabs) == 0 {
+		// gid and range are in query or in fragment of link: edit#gid=123&range=A1:F200
+		values := u.Query()
+		if fragment, err := url.ParseQuery(u.Fragment); err == nil {
+			for k, v := range fragment {
+				if values.Get(k) == "" {
+					values[k] = v
+				}
+			}
+		}
+		tab := values.Get("gid")
+		if r := values.Get("range"); r != "" {
+			tab += TabRangeSeparator + r
+		}
+		tabs = []string{tab}
+	}
+
+	urls := make([]string, 0, len(tabs))
+	for _, tab := range tabs {
+		gid, cellRange := tab, ""
+		if i := strings.Index(tab, TabRangeSeparator); i >= 0 {
+			gid, cellRange = tab[:i], tab[i+1:]
+		}
+		if gid != "" && !gidRegexp.MatchString(gid) {
+			return nil, true, fmt.Errorf("invalid gid of google sheets tab %q: %w", tab, ErrInvalidCsvParams)
+		}
+
+		values := url.Values{}
+		export := &url.URL{Scheme: u.Scheme, Host: u.Host}
+		if published {
+			export.Path = "/spreadsheets/d/e/" + id + "/pub"
+			values.Set("output", "csv")
+			if gid != "" {
+				values.Set("single", "true")
+			}
+		} else {
+			export.Path = "/spreadsheets/d/" + id + "/export"
+			values.Set("format", "csv")
+		}
+		if gid != "" {
+			values.Set("gid", gid)
+		}
+		if cellRange != "" {
+			values.Set("range", cellRange)
+		}
+		export.RawQuery = values.Encode()
+		urls = append(urls, export.String())
+	}
+	return urls, true, nil
+}
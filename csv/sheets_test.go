@@ -0,0 +1,94 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSheetsExportURLs(t *testing.T) {
+	const base = "https://docs.google.com/spreadsheets/d/1AbC-d_9"
+	for link, expected := range map[string]string{
+		base + "/edit#gid=123":            base + "/export?format=csv&gid=123",
+		base + "/edit?usp=sharing":        base + "/export?format=csv",
+		base + "/edit?gid=5#gid=5":        base + "/export?format=csv&gid=5",
+		base + "/edit#gid=7&range=A1:D20": base + "/export?format=csv&gid=7&range=A1%3AD20",
+		base + "/export?format=csv&gid=1": base + "/export?format=csv&gid=1",
+		base:                              base + "/export?format=csv",
+		"https://docs.google.com/spreadsheets/d/e/2PACX-1v/pubhtml?gid=3": "https://docs.google.com/spreadsheets/d/e/2PACX-1v/pub?gid=3&output=csv&single=true",
+	} {
+		urls, ok, err := sheetsExportURLs(link, sheetsHost, nil)
+		require.NoError(t, err, link)
+		require.True(t, ok, link)
+		require.Equal(t, []string{expected}, urls, link)
+	}
+
+	urls, ok, err := sheetsExportURLs(base+"/edit#gid=123", sheetsHost, []string{"0", "42!B2:E100"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []string{base + "/export?format=csv&gid=0", base + "/export?format=csv&gid=42&range=B2%3AE100"}, urls)
+
+	_, _, err = sheetsExportURLs(base+"/edit", sheetsHost, []string{"main"})
+	require.ErrorIs(t, err, ErrInvalidCsvParams)
+
+	for _, link := range []string{"https://example.com/l10n.csv", "https://git.example.com/spreadsheets/d/abc/raw/data.csv"} {
+		_, ok, err = sheetsExportURLs(link, sheetsHost, nil)
+		require.NoError(t, err, link)
+		require.False(t, ok, link)
+	}
+}
+
+func TestLoadArbFromSheets(t *testing.T) {
+	tabs := map[string]string{
+		"0":  "name,en,ru\n@@x-project,demo,\nhello,Hello,Привет\n",
+		"10": "name,en,de\nbye,Bye,Tschüss\n",
+		"20": "name,en\nhello,Hello again\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/spreadsheets/d/doc/export":
+			csv, ok := tabs[r.URL.Query().Get("gid")]
+			if !ok || r.URL.Query().Get("format") != "csv" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "text/csv")
+			fmt.Fprint(w, csv)
+		case "/spreadsheets/d/private/export":
+			fmt.Fprint(w, "<!DOCTYPE html><html><head><title>Sign in</title></head></html>")
+		}
+	}))
+	defer server.Close()
+	defer func(host string) { sheetsHost = host }(sheetsHost)
+	sheetsHost = strings.TrimPrefix(server.URL, "http://")
+
+	params := Params{ColumnName: ColName, DefaultCulture: "en"}
+	arbData, err := LoadArbFromWeb(context.Background(), createLogger(), server.URL+"/spreadsheets/d/doc/edit#gid=0", params, WebParams{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"hello"}, arbData.Keys)
+
+	params.Tabs = []string{"0", "10"}
+	arbData, err = LoadArbFromWeb(context.Background(), createLogger(), server.URL+"/spreadsheets/d/doc/edit", params, WebParams{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "de", "ru"}, arbData.Cultures)
+	require.Equal(t, []string{"hello", "bye"}, arbData.Keys)
+	require.Equal(t, "Tschüss", arbData.Items["bye"].Cultures["de"])
+	require.Equal(t, map[string]map[string]string{"en": {"@@x-project": "demo"}}, arbData.Attributes)
+
+	params.Tabs = []string{"0", "20"}
+	_, err = LoadArbFromWeb(context.Background(), createLogger(), server.URL+"/spreadsheets/d/doc/edit", params, WebParams{})
+	require.ErrorIs(t, err, ErrInvalidCsvStructure)
+
+	params.Tabs = nil
+	_, err = LoadArbFromWeb(context.Background(), createLogger(), server.URL+"/spreadsheets/d/private/edit", params, WebParams{})
+	require.ErrorIs(t, err, ErrNotCsv)
+
+	params.Tabs = []string{"0"}
+	_, err = LoadArbFromWeb(context.Background(), createLogger(), server.URL+"/l10n.csv", params, WebParams{})
+	require.ErrorIs(t, err, ErrInvalidCsvParams)
+}
@@ -1,47 +1,133 @@
 package csv
 
 import (
-	"bytes"
+	"bufio"
+	"context"
 	"encoding/csv"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
-	"os"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/sirupsen/logrus"
 )
 
-func csvFromFile(logger *logrus.Logger, filePath string) (*csv.Reader, error) {
-	csvFile, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
+// newCsvReader returns reader of csv rows from stream in dialect of params, rows are read on demand
+// and stream is read by small chunks, so memory does not depend on size of csv.
+func newCsvReader(src io.Reader, csvParams Params) rowReader {
+	r := csv.NewReader(decodeReader(limitSize(src, csvParams.MaxSize), csvParams.Encoding))
+	// strings of fields are not shared between records, so only slice of fields is reused
+	r.ReuseRecord = true
+	r.LazyQuotes = csvParams.LazyQuotes
+	if csvParams.Delimiter != 0 {
+		r.Comma = csvParams.Delimiter
+	}
+	if utf8.RuneCountInString(csvParams.Comment) == 1 {
+		r.Comment, _ = utf8.DecodeRuneInString(csvParams.Comment)
+		return r
+	}
+	if csvParams.Comment != "" {
+		return &commentFilter{r: r, prefix: csvParams.Comment}
 	}
-	defer func() {
-		if err := csvFile.Close(); err != nil {
-			logger.Warningf("close csv file error: %v", err)
+	return r
+}
+
+// commentFilter skips rows with first field starting with prefix of several characters
+// (comments with one character prefix are skipped by csv.Reader).
+type commentFilter struct {
+	r      rowReader
+	prefix string
+}
+
+func (f *commentFilter) Read() ([]string, error) {
+	for {
+		row, err := f.r.Read()
+		if err != nil || len(row) == 0 || !strings.HasPrefix(row[0], f.prefix) {
+			return row, err
 		}
-	}()
+	}
+}
 
-	return csvFromReader(csvFile)
+// checkDialect checks delimiter, comment and encoding of params, csv.Reader checks them on first read only.
+func checkDialect(csvParams Params) error {
+	delimiter := csvParams.Delimiter
+	if delimiter == 0 {
+		delimiter = ','
+	}
+	if delimiter == '"' || delimiter == '\r' || delimiter == '\n' || delimiter == utf8.RuneError || !utf8.ValidRune(delimiter) {
+		return fmt.Errorf("invalid Delimiter %q: %w", delimiter, ErrInvalidCsvParams)
+	}
+	if strings.ContainsAny(csvParams.Comment, "\"\r\n") || strings.HasPrefix(csvParams.Comment, string(delimiter)) {
+		return fmt.Errorf("invalid Comment %q: %w", csvParams.Comment, ErrInvalidCsvParams)
+	}
+	if csvParams.Encoding != "" {
+		if _, err := ParseEncoding(string(csvParams.Encoding)); err != nil {
+			return fmt.Errorf("invalid Encoding (%v): %w", err, ErrInvalidCsvParams)
+		}
+	}
+	return nil
 }
 
-func csvFromWeb(logger *logrus.Logger, url string) (*csv.Reader, error) {
-	resp, err := http.Get(url)
+// openWeb returns body of csv url, it must be closed by caller.
+func openWeb(ctx context.Context, logger *logrus.Logger, client *webClient, url string) (io.ReadCloser, error) {
+	src, contentType, err := client.fetch(ctx, logger, url)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
+
+	body := bufio.NewReader(src)
+	if isHTML(contentType, body) {
+		if err := src.Close(); err != nil {
 			logger.Warningf("close response body error: %v", err)
 		}
-	}()
+		return nil, fmt.Errorf("%s returned html page, check that url is a csv export link and "+
+			"document is available for anyone with the link: %w", url, ErrNotCsv)
+	}
+	return &readCloser{Reader: body, Closer: src}, nil
+}
 
-	return csvFromReader(resp.Body)
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
-func csvFromReader(src io.Reader) (*csv.Reader, error) {
-	var csvRaw bytes.Buffer
-	if _, err := io.Copy(&csvRaw, src); err != nil {
-		return nil, err
+// isHTML reports whether response is html page (login page, page of document) by content type or content.
+func isHTML(contentType string, body *bufio.Reader) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "text/html" {
+		return true
+	}
+	head, _ := body.Peek(512)
+	return strings.HasPrefix(http.DetectContentType(head), "text/html")
+}
+
+// limitSize returns reader which fails with ErrTooLarge after maxSize bytes, zero maxSize means no limit.
+func limitSize(src io.Reader, maxSize int64) io.Reader {
+	if maxSize <= 0 {
+		return src
+	}
+	return &sizeLimitReader{src: src, remaining: maxSize, maxSize: maxSize}
+}
+
+type sizeLimitReader struct {
+	src       io.Reader
+	remaining int64
+	maxSize   int64
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, fmt.Errorf("more than %d bytes: %w", r.maxSize, ErrTooLarge)
+	}
+	// one byte more than limit is read to distinguish csv of max size from larger one
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.src.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n - 1, fmt.Errorf("more than %d bytes: %w", r.maxSize, ErrTooLarge)
 	}
-	return csv.NewReader(bytes.NewReader(csvRaw.Bytes())), nil
+	return n, err
 }
@@ -0,0 +1,102 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// endlessCsv generates csv rows without end.
+type endlessCsv struct {
+	rows    []string
+	pending []byte
+	row     int
+}
+
+func (e *endlessCsv) Read(p []byte) (int, error) {
+	if len(e.pending) == 0 {
+		if e.row < len(e.rows) {
+			e.pending = []byte(e.rows[e.row] + "\n")
+		} else {
+			e.pending = []byte(fmt.Sprintf("key%d,value %d\n", e.row, e.row))
+		}
+		e.row++
+	}
+	n := copy(p, e.pending)
+	e.pending = e.pending[n:]
+	return n, nil
+}
+
+func TestConvertCsvStream(t *testing.T) {
+	params := Params{ColumnName: ColName, DefaultCulture: "en"}
+
+	// csv is not buffered: invalid row stops reading of endless stream
+	src := &endlessCsv{rows: []string{"name,en", "hello,Hello", "broken,{count"}}
+	_, err := convertCsvToArb(createLogger(), newCsvReader(src, Params{}), params)
+	require.ErrorIs(t, err, ErrInvalidCsvStructure)
+	require.Less(t, src.row, 10000)
+
+	_, err = convertCsvToArb(createLogger(), newCsvReader(&endlessCsv{rows: []string{"name,en"}}, Params{MaxSize: 1 << 20}), params)
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	arbData, err := convertCsvToArb(createLogger(), newCsvReader(io.LimitReader(&endlessCsv{rows: []string{"name,en"}}, 1<<20), Params{}), params)
+	require.NoError(t, err)
+	require.Equal(t, "value 1", arbData.Items["key1"].Cultures["en"])
+	require.Equal(t, "value 100", arbData.Items["key100"].Cultures["en"])
+}
+
+func TestLimitSize(t *testing.T) {
+	for size, fails := range map[int64]bool{9: true, 10: false, 11: false, 0: false} {
+		raw, err := ioutil.ReadAll(limitSize(strings.NewReader("0123456789"), size))
+		if fails {
+			require.ErrorIs(t, err, ErrTooLarge, size)
+			require.Equal(t, "012345678", string(raw))
+			continue
+		}
+		require.NoError(t, err, size)
+		require.Equal(t, "0123456789", string(raw))
+	}
+}
+
+func TestLoadArbMaxSize(t *testing.T) {
+	// content is larger than buffer of reader, so download is stopped by limit
+	content := "name,en\n"
+	for i := 0; i < 1000; i++ {
+		content += fmt.Sprintf("key%d,value\n", i)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "csv")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	csvPath := path.Join(dir, "data.csv")
+	require.NoError(t, ioutil.WriteFile(csvPath, []byte(content), 0644))
+
+	params := Params{ColumnName: ColName, DefaultCulture: "en", MaxSize: int64(len(content))}
+	_, err = LoadArbFromFile(createLogger(), csvPath, params)
+	require.NoError(t, err)
+	_, err = LoadArbFromWeb(context.Background(), createLogger(), server.URL, params, WebParams{})
+	require.NoError(t, err)
+
+	params.MaxSize = 5000
+	_, err = LoadArbFromFile(createLogger(), csvPath, params)
+	require.ErrorIs(t, err, ErrTooLarge)
+	_, err = LoadArbFromWeb(context.Background(), createLogger(), server.URL, params, WebParams{CacheDir: dir})
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	// incomplete download is not cached
+	_, err = LoadArbFromWeb(context.Background(), createLogger(), server.URL, params, WebParams{CacheDir: dir, Offline: true})
+	require.ErrorIs(t, err, ErrNotCached)
+}
@@ -0,0 +1,205 @@
+package csv
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const defaultRetryDelay = time.Second
+
+// WebParams are settings of http requests of LoadArbFromWeb.
+type WebParams struct {
+	// BearerToken is sent as "Authorization: Bearer <token>".
+	BearerToken string
+	// Username and Password are sent as basic authorization if Username is not empty.
+	Username string
+	Password string
+	// Headers are added to every request.
+	Headers http.Header
+	// Timeout limits request with reading of response body, zero means no timeout.
+	Timeout time.Duration
+	// Retries is a count of retries of requests failed with network error or 5xx status.
+	Retries int
+	// RetryDelay is a delay before first retry, it is doubled for every next retry (1s by default).
+	RetryDelay time.Duration
+	// ProxyURL is a url of proxy, proxy from HTTPS_PROXY, HTTP_PROXY and NO_PROXY is used if it is empty.
+	ProxyURL string
+	// CAFile is a path of PEM file with certificates trusted in addition to system certificates.
+	CAFile string
+	// CacheDir is a folder with the last downloaded copies of urls, cache is not used if it is empty.
+	CacheDir string
+	// Offline uses cached copies without requests.
+	Offline bool
+}
+
+// HTTPError is an error of response with non 2xx status.
+type HTTPError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// webClient makes requests with authorization, headers and retries of WebParams.
+type webClient struct {
+	client *http.Client
+	params WebParams
+	cache  *webCache
+}
+
+func newWebClient(params WebParams) (*webClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if params.ProxyURL != "" {
+		proxyURL, err := url.Parse(params.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url (%v): %w", err, ErrInvalidCsvParams)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if params.CAFile != "" {
+		pem, err := ioutil.ReadFile(params.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in CA file %s: %w", params.CAFile, ErrInvalidCsvParams)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	if params.RetryDelay == 0 {
+		params.RetryDelay = defaultRetryDelay
+	}
+	c := &webClient{
+		client: &http.Client{Transport: transport, Timeout: params.Timeout},
+		params: params,
+	}
+	if params.CacheDir != "" {
+		c.cache = &webCache{dir: params.CacheDir}
+	} else if params.Offline {
+		return nil, fmt.Errorf("offline mode requires cache: %w", ErrInvalidCsvParams)
+	}
+	return c, nil
+}
+
+// fetch returns body of url and its content type. Downloaded body is saved to cache, cached copy is
+// revalidated with conditional request and is used without request in offline mode or with warning
+// if server is not available.
+func (c *webClient) fetch(ctx context.Context, logger *logrus.Logger, url string) (io.ReadCloser, string, error) {
+	if c.cache == nil {
+		resp, err := c.get(ctx, logger, url, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Body, resp.Header.Get("Content-Type"), nil
+	}
+
+	meta, cached := c.cache.meta(url)
+	if c.params.Offline {
+		if !cached {
+			return nil, "", fmt.Errorf("offline mode, %s: %w", url, ErrNotCached)
+		}
+		logger.Debugf("use cached copy of %s from %s", url, meta.Downloaded.Format(time.RFC3339))
+		body, err := c.cache.open(url)
+		return body, "", err
+	}
+
+	var header http.Header
+	if cached {
+		header = meta.conditionalHeader()
+	}
+	resp, err := c.get(ctx, logger, url, header)
+	if err != nil {
+		var httpErr *HTTPError
+		if !cached || ctx.Err() != nil || (errors.As(err, &httpErr) && httpErr.StatusCode < http.StatusInternalServerError) {
+			return nil, "", err
+		}
+		logger.Warningf("%v, use cached copy from %s", err, meta.Downloaded.Format(time.RFC3339))
+		body, err := c.cache.open(url)
+		return body, "", err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		logger.Debugf("%s is not modified, use cached copy", url)
+		body, err := c.cache.open(url)
+		return body, "", err
+	}
+	return c.cache.store(logger, url, resp), resp.Header.Get("Content-Type"), nil
+}
+
+// get returns response with 2xx status (or 304 status of conditional request), requests failed
+// with network error or 5xx status are retried. Response body must be closed by caller.
+func (c *webClient) get(ctx context.Context, logger *logrus.Logger, url string, header http.Header) (*http.Response, error) {
+	delay := c.params.RetryDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, url, header)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if httpErr, ok := err.(*HTTPError); ok && httpErr.StatusCode < http.StatusInternalServerError {
+			return nil, err
+		}
+		if attempt >= c.params.Retries {
+			return nil, err
+		}
+
+		logger.Warningf("%v, retry in %s", err, delay)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (c *webClient) do(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.params.Headers {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	switch {
+	case c.params.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.params.BearerToken)
+	case c.params.Username != "":
+		req.SetBasicAuth(c.params.Username, c.params.Password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && len(header) > 0 {
+		return resp, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, &HTTPError{URL: url, StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
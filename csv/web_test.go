@@ -0,0 +1,163 @@
+package csv
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const webCsv = "name,en\nhello,Hello\n"
+
+func TestLoadArbFromWebAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, basic := r.BasicAuth()
+		switch {
+		case r.Header.Get("X-Api-Key") != "key":
+			w.WriteHeader(http.StatusBadRequest)
+		case r.URL.Path == "/bearer" && r.Header.Get("Authorization") == "Bearer token":
+			fmt.Fprint(w, webCsv)
+		case r.URL.Path == "/basic" && basic && user == "user" && password == "secret":
+			fmt.Fprint(w, webCsv)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	params := Params{ColumnName: ColName, DefaultCulture: "en"}
+	headers := http.Header{"x-api-key": {"key"}}
+
+	arbData, err := LoadArbFromWeb(context.Background(), createLogger(), server.URL+"/bearer", params,
+		WebParams{BearerToken: "token", Headers: headers})
+	require.NoError(t, err)
+	require.Equal(t, []string{"hello"}, arbData.Keys)
+
+	_, err = LoadArbFromWeb(context.Background(), createLogger(), server.URL+"/basic", params,
+		WebParams{Username: "user", Password: "secret", Headers: headers})
+	require.NoError(t, err)
+
+	_, err = LoadArbFromWeb(context.Background(), createLogger(), server.URL+"/basic", params,
+		WebParams{Username: "user", Password: "wrong", Headers: headers, Retries: 3})
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	require.Equal(t, server.URL+"/basic", httpErr.URL)
+}
+
+func TestWebClientRetries(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		switch {
+		case r.URL.Path == "/flaky" && n < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/flaky":
+			fmt.Fprint(w, webCsv)
+		case r.URL.Path == "/missing":
+			http.NotFound(w, r)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client, err := newWebClient(WebParams{Retries: 2, RetryDelay: time.Millisecond})
+	require.NoError(t, err)
+
+	resp, err := client.get(context.Background(), createLogger(), server.URL+"/flaky", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&requests, 0)
+	_, err = client.get(context.Background(), createLogger(), server.URL+"/broken", nil)
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// 4xx statuses are not retried
+	atomic.StoreInt32(&requests, 0)
+	_, err = client.get(context.Background(), createLogger(), server.URL+"/missing", nil)
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestWebClientTimeoutAndCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := newWebClient(WebParams{Timeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+	_, err = client.get(context.Background(), createLogger(), server.URL, nil)
+	require.Error(t, err)
+
+	client, err = newWebClient(WebParams{Retries: 5})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.get(ctx, createLogger(), server.URL, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWebClientCAAndProxy(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, webCsv)
+	}))
+	defer server.Close()
+
+	client, err := newWebClient(WebParams{})
+	require.NoError(t, err)
+	_, err = client.get(context.Background(), createLogger(), server.URL, nil)
+	require.Error(t, err)
+
+	dir, err := ioutil.TempDir("", "ca")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	caFile := path.Join(dir, "ca.pem")
+	require.NoError(t, ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644))
+
+	client, err = newWebClient(WebParams{CAFile: caFile})
+	require.NoError(t, err)
+	resp, err := client.get(context.Background(), createLogger(), server.URL, nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.NoError(t, ioutil.WriteFile(caFile, []byte("no certificates"), 0644))
+	_, err = newWebClient(WebParams{CAFile: caFile})
+	require.ErrorIs(t, err, ErrInvalidCsvParams)
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "sheets.invalid" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, webCsv)
+	}))
+	defer proxy.Close()
+
+	client, err = newWebClient(WebParams{ProxyURL: proxy.URL})
+	require.NoError(t, err)
+	resp, err = client.get(context.Background(), createLogger(), "http://sheets.invalid/l10n.csv", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+}
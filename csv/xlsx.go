@@ -0,0 +1,477 @@
+package csv
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	xlsxExt          = ".xlsx"
+	xlsxDefaultSheet = "Sheet1"
+
+	relsNs      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	relComments = relsNs + "/comments"
+)
+
+// rowReader reads rows of table, it is implemented by csv.Reader and xlsx sheet.
+type rowReader interface {
+	Read() ([]string, error)
+}
+
+// rowWriter writes rows of table, it is implemented by csv.Writer and xlsx sheet.
+type rowWriter interface {
+	Write(record []string) error
+}
+
+// cellComments is implemented by tables with comments of cells.
+type cellComments interface {
+	// Comment returns comment of cell of the last read row.
+	Comment(col int) string
+}
+
+func isXlsx(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), xlsxExt)
+}
+
+type xlsxRelationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxText is a shared string, inline string or comment text: plain text or rich text runs.
+type xlsxText struct {
+	T    *string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t *xlsxText) String() string {
+	sb := &strings.Builder{}
+	if t.T != nil {
+		sb.WriteString(*t.T)
+	}
+	for _, r := range t.Runs {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Num   int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxComments struct {
+	Authors  []string `xml:"authors>author"`
+	Comments []struct {
+		Ref      string   `xml:"ref,attr"`
+		AuthorID int      `xml:"authorId,attr"`
+		Text     xlsxText `xml:"text"`
+	} `xml:"commentList>comment"`
+}
+
+// sheetReader reads rows of xlsx sheet, empty rows are skipped, rows are aligned to header width.
+type sheetReader struct {
+	rows     [][]string
+	rowNums  []int
+	comments map[int]map[int]string
+	next     int
+	width    int
+}
+
+func (s *sheetReader) Read() ([]string, error) {
+	for ; s.next < len(s.rows); s.next++ {
+		row := s.rows[s.next]
+		if isEmptyRow(row) {
+			continue
+		}
+		if s.width == 0 {
+			s.width = len(row)
+		}
+		aligned := make([]string, s.width)
+		copy(aligned, row)
+		s.next++
+		return aligned, nil
+	}
+	return nil, io.EOF
+}
+
+func (s *sheetReader) Comment(col int) string {
+	if s.next == 0 {
+		return ""
+	}
+	return s.comments[s.rowNums[s.next-1]][col]
+}
+
+func isEmptyRow(row []string) bool {
+	for _, v := range row {
+		if v != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// xlsxFromFile reads sheet of xlsx workbook, sheet is a name or number of sheet, first sheet is used by default.
+func xlsxFromFile(logger *logrus.Logger, filePath, sheet string, maxSize int64) (*sheetReader, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidCsvStructure)
+	}
+	defer func() {
+		if err := zr.Close(); err != nil {
+			logger.Warningf("close xlsx file error: %v", err)
+		}
+	}()
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	readPart := func(name string, v interface{}) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("%s: part %s not found: %w", filePath, name, ErrInvalidCsvStructure)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		if err := xml.NewDecoder(limitSize(rc, maxSize)).Decode(v); err != nil {
+			if errors.Is(err, ErrTooLarge) {
+				return fmt.Errorf("%s: part %s: %w", filePath, name, err)
+			}
+			return fmt.Errorf("%s: part %s (%v): %w", filePath, name, err, ErrInvalidCsvStructure)
+		}
+		return nil
+	}
+
+	var workbook xlsxWorkbook
+	if err := readPart("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var workbookRels xlsxRelationships
+	if err := readPart("xl/_rels/workbook.xml.rels", &workbookRels); err != nil {
+		return nil, err
+	}
+
+	rid := ""
+	for _, s := range workbook.Sheets {
+		if s.Name == sheet {
+			rid = s.RID
+		}
+	}
+	if rid == "" {
+		n, err := strconv.Atoi(sheet)
+		switch {
+		case sheet == "" && len(workbook.Sheets) > 0:
+			rid = workbook.Sheets[0].RID
+		case err == nil && n > 0 && n <= len(workbook.Sheets):
+			rid = workbook.Sheets[n-1].RID
+		default:
+			return nil, fmt.Errorf("%s: sheet %s not found: %w", filePath, sheet, ErrInvalidCsvParams)
+		}
+	}
+	sheetPart := ""
+	for _, r := range workbookRels.Items {
+		if r.ID == rid {
+			sheetPart = partPath("xl", r.Target)
+		}
+	}
+	logger.Tracef("read sheet %s", sheetPart)
+
+	var sharedStrings xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := readPart("xl/sharedStrings.xml", &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	var worksheet xlsxWorksheet
+	if err := readPart(sheetPart, &worksheet); err != nil {
+		return nil, err
+	}
+
+	s := &sheetReader{comments: make(map[int]map[int]string)}
+	for i, r := range worksheet.Rows {
+		num := r.Num
+		if num == 0 {
+			num = i + 1
+		}
+		var row []string
+		for _, c := range r.Cells {
+			col := len(row)
+			if c.Ref != "" {
+				if col, _, err = parseCellRef(c.Ref); err != nil {
+					return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidCsvStructure)
+				}
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("%s: cell %s has invalid shared string: %w", filePath, c.Ref, ErrInvalidCsvStructure)
+				}
+				row[col] = sharedStrings.Items[n].String()
+			case "inlineStr":
+				row[col] = c.Inline.String()
+			case "b":
+				row[col] = strings.ToUpper(strconv.FormatBool(c.Value == "1"))
+			default:
+				row[col] = c.Value
+			}
+		}
+		s.rows = append(s.rows, row)
+		s.rowNums = append(s.rowNums, num)
+	}
+
+	sheetRels := path.Join(path.Dir(sheetPart), "_rels", path.Base(sheetPart)+".rels")
+	if _, ok := files[sheetRels]; !ok {
+		return s, nil
+	}
+	var rels xlsxRelationships
+	if err := readPart(sheetRels, &rels); err != nil {
+		return nil, err
+	}
+	for _, r := range rels.Items {
+		if r.Type != relComments {
+			continue
+		}
+		var comments xlsxComments
+		if err := readPart(partPath(path.Dir(sheetPart), r.Target), &comments); err != nil {
+			return nil, err
+		}
+		for _, c := range comments.Comments {
+			col, num, err := parseCellRef(c.Ref)
+			if err != nil {
+				return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidCsvStructure)
+			}
+			if s.comments[num] == nil {
+				s.comments[num] = make(map[int]string)
+			}
+			s.comments[num][col] = commentText(c.Text, comments.Authors, c.AuthorID)
+		}
+	}
+	return s, nil
+}
+
+// commentText returns text of comment without "Author:" line which Excel adds to new comments.
+func commentText(t xlsxText, authors []string, authorID int) string {
+	text := t.String()
+	if authorID >= 0 && authorID < len(authors) && authors[authorID] != "" {
+		text = strings.TrimPrefix(text, authors[authorID]+":")
+	}
+	return strings.TrimSpace(text)
+}
+
+// partPath resolves target of relationship relative to folder of source part.
+func partPath(dir, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(dir, target)
+}
+
+// parseCellRef returns zero based column and row number of cell reference like "B12".
+func parseCellRef(ref string) (int, int, error) {
+	col, i := 0, 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+	}
+	num, err := strconv.Atoi(ref[i:])
+	if i == 0 || err != nil {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col - 1, num, nil
+}
+
+func cellRef(col, num int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(num)
+}
+
+// sheetWriter collects rows and writes them to xlsx workbook with one sheet.
+type sheetWriter struct {
+	rows [][]string
+}
+
+func (s *sheetWriter) Write(record []string) error {
+	s.rows = append(s.rows, append([]string(nil), record...))
+	return nil
+}
+
+// save writes workbook, header row is bold and frozen, sheet is protected:
+// only cells of key column (lockedCol) and header are locked. Workbook is written through temp file,
+// so an interrupted run does not truncate it.
+func (s *sheetWriter) save(logger *logrus.Logger, filePath, sheet string, lockedCol int) error {
+	// number selects sheet on reading, it is not a name of sheet
+	if _, err := strconv.Atoi(sheet); err == nil || sheet == "" {
+		sheet = xlsxDefaultSheet
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbookXML, escapeXML(sheet))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", s.worksheet(lockedCol)},
+	}
+	for _, p := range parts {
+		w, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, p.content); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	return arb.WriteFileIfChanged(logger, filePath, buf.Bytes())
+}
+
+// Styles of cells: default (locked), header (bold, locked) and unlocked.
+const (
+	styleHeader   = 1
+	styleUnlocked = 2
+)
+
+func (s *sheetWriter) worksheet(lockedCol int) string {
+	width := 0
+	for _, r := range s.rows {
+		if len(r) > width {
+			width = len(r)
+		}
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sb.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+	sb.WriteString(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	sb.WriteString(`<selection pane="bottomLeft"/></sheetView></sheetViews>`)
+
+	// style of columns is used for new cells, so translators can fill empty cells of unlocked columns
+	sb.WriteString(`<cols>`)
+	for col := 0; col < width; col++ {
+		style := styleUnlocked
+		if col == lockedCol {
+			style = 0
+		}
+		fmt.Fprintf(sb, `<col min="%d" max="%d" width="30" style="%d" customWidth="1"/>`, col+1, col+1, style)
+	}
+	sb.WriteString(`</cols>`)
+
+	sb.WriteString(`<sheetData>`)
+	for i, r := range s.rows {
+		fmt.Fprintf(sb, `<row r="%d">`, i+1)
+		for col, v := range r {
+			style := styleUnlocked
+			if i == 0 {
+				style = styleHeader
+			} else if col == lockedCol {
+				style = 0
+			}
+			if v == "" {
+				fmt.Fprintf(sb, `<c r="%s" s="%d"/>`, cellRef(col, i+1), style)
+				continue
+			}
+			fmt.Fprintf(sb, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+				cellRef(col, i+1), style, escapeXML(v))
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData>`)
+	sb.WriteString(`<sheetProtection sheet="1" objects="1" scenarios="1" formatColumns="0" formatRows="0" sort="0" autoFilter="0"/>`)
+	sb.WriteString(`</worksheet>`)
+	return sb.String()
+}
+
+func escapeXML(s string) string {
+	buf := &bytes.Buffer{}
+	_ = xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="` + relsNs + `/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbookXML = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + relsNs + `">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="` + relsNs + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="` + relsNs + `/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0" applyAlignment="1" applyProtection="1"><alignment wrapText="1"/><protection locked="0"/></xf>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,149 @@
+package csv
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCellRef(t *testing.T) {
+	for ref, cell := range map[string][2]int{"A1": {0, 1}, "Z10": {25, 10}, "AA2": {26, 2}, "BC300": {54, 300}} {
+		col, num, err := parseCellRef(ref)
+		require.NoError(t, err)
+		require.Equal(t, cell, [2]int{col, num})
+		require.Equal(t, ref, cellRef(col, num))
+	}
+
+	for _, ref := range []string{"", "12", "A", "a1"} {
+		_, _, err := parseCellRef(ref)
+		require.Error(t, err, ref)
+	}
+}
+
+func TestSaveAndLoadXlsx(t *testing.T) {
+	arbData := &arb.Data{
+		Cultures: []string{"en", "ru"},
+		Items: map[string]*arb.Item{
+			"b": {Description: "descr b", Cultures: map[string]string{"en": " en <b> & b\n", "ru": "ru b"}},
+			"a": {Cultures: map[string]string{"en": "en {x}"}, Parameters: map[string]*arb.Placeholder{"x": {}}},
+		},
+		Keys: []string{"b", "a"},
+	}
+
+	dir, err := ioutil.TempDir("", "xlsx")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	xlsxPath := path.Join(dir, "data.xlsx")
+
+	params := Params{
+		ColumnName:        ColName,
+		ColumnDescription: ColDescr,
+		ColumnParameters:  ColParams,
+		DefaultCulture:    "en",
+		Order:             arb.OrderSource,
+		Sheet:             "l10n",
+	}
+	require.NoError(t, SaveArb(createLogger(), xlsxPath, params, arbData))
+
+	zr, err := zip.OpenReader(xlsxPath)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	require.NoError(t, zr.Close())
+	require.Contains(t, names, "xl/worksheets/sheet1.xml")
+
+	// unchanged workbook is not rewritten, no temp files are left
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(xlsxPath, old, old))
+	require.NoError(t, SaveArb(createLogger(), xlsxPath, params, arbData))
+	fi, err := os.Stat(xlsxPath)
+	require.NoError(t, err)
+	require.Equal(t, old, fi.ModTime())
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	loaded, err := LoadArbFromFile(createLogger(), xlsxPath, params)
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "ru"}, loaded.Cultures)
+	require.Equal(t, []string{"b", "a"}, loaded.Keys)
+	require.Equal(t, arbData.Items["b"].Cultures, loaded.Items["b"].Cultures)
+	require.Equal(t, "descr b", loaded.Items["b"].Description)
+	require.Equal(t, map[string]string{"en": "en {x}", "ru": ""}, loaded.Items["a"].Cultures)
+
+	params.Sheet = "1"
+	_, err = LoadArbFromFile(createLogger(), xlsxPath, params)
+	require.NoError(t, err)
+
+	params.Sheet = "other"
+	_, err = LoadArbFromFile(createLogger(), xlsxPath, params)
+	require.ErrorIs(t, err, ErrInvalidCsvParams)
+}
+
+func TestLoadXlsxSharedStringsAndComments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xlsx")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	xlsxPath := path.Join(dir, "data.xlsx")
+
+	f, err := os.Create(xlsxPath)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Notes" sheetId="1" r:id="rId1"/><sheet name="Strings" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>name</t></si><si><t>en</t></si><si><r><t>Hello, </t></r><r><rPr><b/></rPr><t>{name}</t></r></si><si><t>hello</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2"/></row>
+<row r="4"><c r="A4" t="s"><v>3</v></c><c r="C4" t="s"><v>2</v></c></row>
+<row r="5"><c r="A5" t="inlineStr"><is><t>count</t></is></c><c r="C5"><v>42</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/_rels/sheet2.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments" Target="../comments1.xml"/>
+</Relationships>`,
+		"xl/comments1.xml": `<comments xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<authors><author>PM</author></authors><commentList>
+<comment ref="A4" authorId="0"><text><r><rPr><b/></rPr><t>PM:</t></r><r><t xml:space="preserve">
+Greeting on main screen</t></r></text></comment>
+</commentList></comments>`,
+	} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	params := Params{ColumnName: ColName, DefaultCulture: "en", Sheet: "Strings"}
+	loaded, err := LoadArbFromFile(createLogger(), xlsxPath, params)
+	require.NoError(t, err)
+	require.Equal(t, []string{"hello", "count"}, loaded.Keys)
+	require.Equal(t, "Hello, {name}", loaded.Items["hello"].Cultures["en"])
+	require.Equal(t, "Greeting on main screen", loaded.Items["hello"].Description)
+	require.Equal(t, "42", loaded.Items["count"].Cultures["en"])
+
+	params.Sheet = "2"
+	_, err = LoadArbFromFile(createLogger(), xlsxPath, params)
+	require.NoError(t, err)
+
+	// first sheet is empty
+	params.Sheet = ""
+	_, err = LoadArbFromFile(createLogger(), xlsxPath, params)
+	require.Error(t, err)
+}
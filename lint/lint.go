@@ -0,0 +1,289 @@
+// Package lint checks arb data: missing translations, keys which are not valid Dart identifiers,
+// metadata without messages, missing descriptions and keys absent in default culture.
+// Severity of every rule is configurable, rules are suppressed for key by "lint-ignore: <rule>, <rule>"
+// in key description.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
+)
+
+// Severity is a severity of rule findings.
+type Severity string
+
+const (
+	SeverityOff     Severity = "off"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Rules ids.
+const (
+	RuleMissingTranslation = "missing-translation"
+	RuleEmptyTranslation   = "empty-translation"
+	RuleInvalidKey         = "invalid-key"
+	RuleOrphanMetadata     = "orphan-metadata"
+	RuleMetaKey            = "meta-key"
+	RuleMissingDescription = "missing-description"
+	RuleUnknownKey         = "unknown-key"
+)
+
+var (
+	ErrInvalidSeverity = errors.New("invalid severity")
+	ErrUnknownRule     = errors.New("unknown rule")
+	ErrFindings        = errors.New("lint errors found")
+)
+
+// Rule describes check, Fixable rules have safe automatic fix.
+type Rule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	Fixable     bool
+}
+
+// rules are rules with default severities in order of output.
+var rules = []*Rule{
+	{ID: RuleMissingTranslation, Description: "translation of culture is missing", Severity: SeverityWarning},
+	{ID: RuleEmptyTranslation, Description: "translation of culture is empty", Severity: SeverityWarning},
+	{ID: RuleInvalidKey, Description: "key is not a valid Dart identifier", Severity: SeverityError},
+	{ID: RuleOrphanMetadata, Description: "@key metadata without message", Severity: SeverityWarning, Fixable: true},
+	{ID: RuleMetaKey, Description: "key starts with @ and is read as metadata from arb", Severity: SeverityError},
+	{ID: RuleMissingDescription, Description: "key has no description", Severity: SeverityInfo},
+	{ID: RuleUnknownKey, Description: "key is absent in default culture", Severity: SeverityError},
+}
+
+// ignoreDirective suppresses rules for key: "lint-ignore: missing-description, empty-translation".
+var ignoreDirective = regexp.MustCompile(`lint-ignore:\s*([a-z-]+(?:\s*,\s*[a-z-]+)*)`)
+
+var dartIdentifier = regexp.MustCompile(`^[a-zA-Z$][a-zA-Z0-9_$]*$`)
+
+// dartKeywords can't be names of getters and methods of generated localizations.
+var dartKeywords = map[string]struct{}{}
+
+func init() {
+	for _, k := range strings.Fields(`abstract as assert async await break case catch class const continue covariant
+		default deferred do dynamic else enum export extends extension external factory false final finally for
+		Function get hide if implements import in inout interface is late library mixin native new null of on
+		operator out part patch required rethrow return set show source static super switch sync this throw true
+		try typedef var void while with yield`) {
+		dartKeywords[k] = struct{}{}
+	}
+}
+
+type Params struct {
+	DefaultCulture string
+	// Severities override default severities of rules: rule -> severity.
+	Severities map[string]Severity
+	// Source is a path or url of linted data, it is written to reports.
+	Source string
+}
+
+// Finding is a rule violation of key, Culture is set for findings of translations.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Key      string   `json:"key"`
+	Culture  string   `json:"culture,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (f *Finding) String() string {
+	if f.Culture == "" {
+		return fmt.Sprintf("key %s: %s", f.Key, f.Message)
+	}
+	return fmt.Sprintf("key %s, culture %s: %s", f.Key, f.Culture, f.Message)
+}
+
+// Report is a result of Lint.
+type Report struct {
+	Source string
+	// Rules are enabled rules with effective severities.
+	Rules    []*Rule
+	Findings []*Finding
+}
+
+// Count returns count of findings with severity.
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Fixable returns count of findings of fixable rules.
+func (r *Report) Fixable() int {
+	fixable := make(map[string]bool)
+	for _, rule := range r.Rules {
+		fixable[rule.ID] = rule.Fixable
+	}
+	n := 0
+	for _, f := range r.Findings {
+		if fixable[f.Rule] {
+			n++
+		}
+	}
+	return n
+}
+
+func ParseSeverity(s string) (Severity, error) {
+	switch sv := Severity(strings.ToLower(s)); sv {
+	case SeverityOff, SeverityInfo, SeverityWarning, SeverityError:
+		return sv, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidSeverity)
+}
+
+// ParseSeverities parses severities of rules: missing-description=off,empty-translation=error.
+func ParseSeverities(s string) (map[string]Severity, error) {
+	severities := make(map[string]Severity)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i := strings.Index(part, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%s: expected <rule>=<severity>: %w", part, ErrInvalidSeverity)
+		}
+		id := strings.TrimSpace(part[:i])
+		if findRule(id) == nil {
+			return nil, fmt.Errorf("%s: %w", id, ErrUnknownRule)
+		}
+		severity, err := ParseSeverity(strings.TrimSpace(part[i+1:]))
+		if err != nil {
+			return nil, err
+		}
+		severities[id] = severity
+	}
+	return severities, nil
+}
+
+func findRule(id string) *Rule {
+	for _, r := range rules {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// Rules returns all rules with default severities.
+func Rules() []*Rule {
+	res := make([]*Rule, 0, len(rules))
+	for _, r := range rules {
+		rule := *r
+		res = append(res, &rule)
+	}
+	return res
+}
+
+// Lint checks arb data with enabled rules, findings are sorted by key, culture and rule.
+func Lint(arbData *arb.Data, params Params) (*Report, error) {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return nil, fmt.Errorf("default culture: %w", err)
+	}
+
+	report := &Report{Source: params.Source}
+	severities := make(map[string]Severity)
+	for _, rule := range Rules() {
+		if s, ok := params.Severities[rule.ID]; ok {
+			rule.Severity = s
+		}
+		if rule.Severity == SeverityOff {
+			continue
+		}
+		severities[rule.ID] = rule.Severity
+		report.Rules = append(report.Rules, rule)
+	}
+
+	add := func(ignored map[string]struct{}, rule, key, culture, message string) {
+		severity, ok := severities[rule]
+		if !ok {
+			return
+		}
+		if _, ok := ignored[rule]; ok {
+			return
+		}
+		report.Findings = append(report.Findings, &Finding{Rule: rule, Severity: severity, Key: key, Culture: culture, Message: message})
+	}
+
+	for key, item := range arbData.Items {
+		ignored, description := parseIgnored(item.Description)
+
+		if strings.HasPrefix(key, "@") {
+			add(ignored, RuleMetaKey, key, "", "key starts with @, it is read as metadata of arb")
+		} else if !isDartIdentifier(key) {
+			add(ignored, RuleInvalidKey, key, "", "key is not a valid Dart identifier")
+		}
+		if description == "" {
+			add(ignored, RuleMissingDescription, key, "", "description is missing")
+		}
+
+		if _, ok := item.Cultures[defaultCulture]; !ok {
+			add(ignored, RuleUnknownKey, key, "", fmt.Sprintf("key is absent in default culture %s", defaultCulture))
+			continue
+		}
+		for _, c := range arbData.Cultures {
+			value, ok := item.Cultures[c]
+			switch {
+			case !ok:
+				add(ignored, RuleMissingTranslation, key, c, "translation is missing")
+			case strings.TrimSpace(value) == "":
+				add(ignored, RuleEmptyTranslation, key, c, "translation is empty")
+			}
+		}
+	}
+
+	for c, keys := range arbData.OrphanMeta {
+		for _, key := range keys {
+			add(nil, RuleOrphanMetadata, key, c, fmt.Sprintf("metadata @%s has no message", key))
+		}
+	}
+
+	sort.Slice(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Culture != b.Culture {
+			return a.Culture < b.Culture
+		}
+		return a.Rule < b.Rule
+	})
+	return report, nil
+}
+
+// parseIgnored returns rules suppressed by description and description without directive.
+func parseIgnored(description string) (map[string]struct{}, string) {
+	m := ignoreDirective.FindStringSubmatch(description)
+	if m == nil {
+		return nil, strings.TrimSpace(description)
+	}
+	ignored := make(map[string]struct{})
+	for _, rule := range strings.Split(m[1], ",") {
+		ignored[strings.TrimSpace(rule)] = struct{}{}
+	}
+	return ignored, strings.TrimSpace(strings.Replace(description, m[0], "", 1))
+}
+
+// isDartIdentifier reports whether key can be a name of getter or method of generated localizations.
+func isDartIdentifier(key string) bool {
+	if !dartIdentifier.MatchString(key) {
+		return false
+	}
+	_, keyword := dartKeywords[key]
+	return !keyword
+}
@@ -0,0 +1,135 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/stretchr/testify/require"
+)
+
+func testData() *arb.Data {
+	return &arb.Data{
+		Cultures: []string{"en", "ru", "de"},
+		Items: map[string]*arb.Item{
+			"ok":      {Description: "ok", Cultures: map[string]string{"en": "OK", "ru": "ОК", "de": "OK"}},
+			"partial": {Description: "partial", Cultures: map[string]string{"en": "Partial", "ru": " "}},
+			"1st":     {Description: "first", Cultures: map[string]string{"en": "First", "ru": "Первый", "de": "Erste"}},
+			"class":   {Description: "class", Cultures: map[string]string{"en": "Class", "ru": "Класс", "de": "Klasse"}},
+			"@meta":   {Description: "meta", Cultures: map[string]string{"en": "Meta", "ru": "Мета", "de": "Meta"}},
+			"noDescr": {Cultures: map[string]string{"en": "No", "ru": "Нет", "de": "Nein"}},
+			"ignored": {Description: "lint-ignore: missing-description, empty-translation", Cultures: map[string]string{"en": "", "ru": "", "de": ""}},
+			"extra":   {Cultures: map[string]string{"ru": "Лишний"}},
+		},
+		OrphanMeta: map[string][]string{"en": {"removed"}},
+	}
+}
+
+func TestLint(t *testing.T) {
+	report, err := Lint(testData(), Params{DefaultCulture: "en"})
+	require.NoError(t, err)
+	require.Equal(t, []*Finding{
+		{Rule: RuleInvalidKey, Severity: SeverityError, Key: "1st", Message: "key is not a valid Dart identifier"},
+		{Rule: RuleMetaKey, Severity: SeverityError, Key: "@meta", Message: "key starts with @, it is read as metadata of arb"},
+		{Rule: RuleInvalidKey, Severity: SeverityError, Key: "class", Message: "key is not a valid Dart identifier"},
+		{Rule: RuleMissingDescription, Severity: SeverityInfo, Key: "extra", Message: "description is missing"},
+		{Rule: RuleUnknownKey, Severity: SeverityError, Key: "extra", Message: "key is absent in default culture en"},
+		{Rule: RuleMissingDescription, Severity: SeverityInfo, Key: "noDescr", Message: "description is missing"},
+		{Rule: RuleMissingTranslation, Severity: SeverityWarning, Key: "partial", Culture: "de", Message: "translation is missing"},
+		{Rule: RuleEmptyTranslation, Severity: SeverityWarning, Key: "partial", Culture: "ru", Message: "translation is empty"},
+		{Rule: RuleOrphanMetadata, Severity: SeverityWarning, Key: "removed", Culture: "en", Message: "metadata @removed has no message"},
+	}, report.Findings)
+	require.Equal(t, 4, report.Count(SeverityError))
+	require.Equal(t, 1, report.Fixable())
+
+	severities, err := ParseSeverities("missing-description=off, unknown-key=warning,orphan-metadata=off")
+	require.NoError(t, err)
+	report, err = Lint(testData(), Params{DefaultCulture: "en", Severities: severities})
+	require.NoError(t, err)
+	require.Len(t, report.Findings, 6)
+	require.Len(t, report.Rules, len(rules)-2)
+	require.Equal(t, SeverityWarning, report.Findings[3].Severity)
+	require.Equal(t, 0, report.Fixable())
+
+	_, err = ParseSeverities("typo=error")
+	require.ErrorIs(t, err, ErrUnknownRule)
+	_, err = ParseSeverities("missing-description=fatal")
+	require.ErrorIs(t, err, ErrInvalidSeverity)
+	_, err = ParseSeverities("missing-description")
+	require.ErrorIs(t, err, ErrInvalidSeverity)
+}
+
+func TestIsDartIdentifier(t *testing.T) {
+	for key, valid := range map[string]bool{
+		"hello": true, "helloWorld2": true, "Title": true, "snake_case": true, "$price": true,
+		"_private": false, "2fa": false, "kebab-case": false, "dotted.key": false, "with space": false,
+		"": false, "switch": false, "привет": false,
+	} {
+		require.Equal(t, valid, isDartIdentifier(key), key)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	report := &Report{
+		Source: "lib/l10n",
+		Rules: []*Rule{
+			{ID: RuleMissingTranslation, Description: "translation of culture is missing", Severity: SeverityWarning},
+			{ID: RuleInvalidKey, Description: "key is not a valid Dart identifier", Severity: SeverityError},
+		},
+		Findings: []*Finding{
+			{Rule: RuleMissingTranslation, Severity: SeverityWarning, Key: "a", Culture: "ru", Message: "translation is missing"},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.Write(buf, FormatText, ""))
+	require.Equal(t, "warning missing-translation: key a, culture ru: translation is missing\n0 error(s), 1 warning(s), 0 info\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, report.Write(buf, FormatJSON, ""))
+	require.JSONEq(t, `{
+		"source": "lib/l10n",
+		"findings": [{"rule": "missing-translation", "severity": "warning", "key": "a", "culture": "ru", "message": "translation is missing"}],
+		"summary": {"error": 0, "warning": 1, "info": 0}
+	}`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, report.Write(buf, FormatSARIF, "1.2.3"))
+	var sarif sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &sarif))
+	require.Equal(t, "2.1.0", sarif.Version)
+	require.Len(t, sarif.Runs, 1)
+	require.Equal(t, "1.2.3", sarif.Runs[0].Tool.Driver.Version)
+	require.Len(t, sarif.Runs[0].Tool.Driver.Rules, 2)
+	require.Equal(t, []sarifResult{{
+		RuleID:  RuleMissingTranslation,
+		Level:   "warning",
+		Message: sarifMessage{Text: "key a, culture ru: translation is missing"},
+		Locations: []sarifLocation{{
+			PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "lib/l10n"}},
+			LogicalLocations: []sarifLogicalLocation{{Name: "a", FullyQualifiedName: "ru/a", Kind: "member"}},
+		}},
+	}}, sarif.Runs[0].Results)
+
+	buf.Reset()
+	require.NoError(t, report.Write(buf, FormatJUnit, ""))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="arbc lint" tests="2" failures="1">
+  <testsuite name="missing-translation" tests="1" failures="1">
+    <testcase classname="missing-translation" name="key a, culture ru: translation is missing">
+      <failure type="warning" message="translation is missing">lib/l10n</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="invalid-key" tests="1" failures="0">
+    <testcase classname="invalid-key" name="key is not a valid Dart identifier"></testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+
+	f, err := ParseFormat("SARIF")
+	require.NoError(t, err)
+	require.Equal(t, FormatSARIF, f)
+	_, err = ParseFormat("html")
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
@@ -0,0 +1,263 @@
+package lint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is an output format of report.
+type Format string
+
+const (
+	FormatText  Format = "text"
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+	FormatJUnit Format = "junit"
+)
+
+const (
+	toolName = "arbc"
+	toolURI  = "https://github.com/evg1605/csv_arb"
+
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+var (
+	ErrInvalidFormat = errors.New("invalid lint format")
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatText, FormatJSON, FormatSARIF, FormatJUnit:
+		return f, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidFormat)
+}
+
+// Write writes report in format, version is a version of tool in SARIF report.
+func (r *Report) Write(w io.Writer, format Format, version string) error {
+	switch format {
+	case FormatJSON:
+		return r.writeJSON(w)
+	case FormatSARIF:
+		return r.writeSARIF(w, version)
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	}
+	return r.writeText(w)
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	sb := &strings.Builder{}
+	for _, f := range r.Findings {
+		fmt.Fprintf(sb, "%-7s %s: %s\n", f.Severity, f.Rule, f)
+	}
+	fmt.Fprintf(sb, "%d error(s), %d warning(s), %d info\n",
+		r.Count(SeverityError), r.Count(SeverityWarning), r.Count(SeverityInfo))
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (r *Report) writeJSON(w io.Writer) error {
+	findings := r.Findings
+	if findings == nil {
+		findings = []*Finding{}
+	}
+	return writeIndentedJSON(w, struct {
+		Source   string           `json:"source,omitempty"`
+		Findings []*Finding       `json:"findings"`
+		Summary  map[Severity]int `json:"summary"`
+	}{
+		Source:   r.Source,
+		Findings: findings,
+		Summary: map[Severity]int{
+			SeverityError:   r.Count(SeverityError),
+			SeverityWarning: r.Count(SeverityWarning),
+			SeverityInfo:    r.Count(SeverityInfo),
+		},
+	})
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevel converts severity to SARIF level.
+func sarifLevel(s Severity) string {
+	if s == SeverityInfo {
+		return "note"
+	}
+	return string(s)
+}
+
+func (r *Report) writeSARIF(w io.Writer, version string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			Version:        version,
+			InformationURI: toolURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	for _, rule := range r.Rules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rule.Severity)},
+		})
+	}
+
+	var physical *sarifPhysicalLocation
+	if r.Source != "" {
+		physical = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(r.Source)}}
+	}
+	for _, f := range r.Findings {
+		name := f.Key
+		if f.Culture != "" {
+			name = f.Culture + "/" + f.Key
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  f.Rule,
+			Level:   sarifLevel(f.Severity),
+			Message: sarifMessage{Text: f.String()},
+			Locations: []sarifLocation{{
+				PhysicalLocation: physical,
+				LogicalLocations: []sarifLogicalLocation{{Name: f.Key, FullyQualifiedName: name, Kind: "member"}},
+			}},
+		})
+	}
+	return writeIndentedJSON(w, &sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes test suite for every enabled rule, findings are failed test cases,
+// rule without findings is a single passed test case.
+func (r *Report) writeJUnit(w io.Writer) error {
+	suites := junitTestSuites{Name: toolName + " lint"}
+	for _, rule := range r.Rules {
+		suite := junitTestSuite{Name: rule.ID}
+		for _, f := range r.Findings {
+			if f.Rule != rule.ID {
+				continue
+			}
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				ClassName: rule.ID,
+				Name:      f.String(),
+				Failure:   &junitFailure{Type: string(f.Severity), Message: f.Message, Text: r.Source},
+			})
+		}
+		suite.Failures = len(suite.TestCases)
+		if suite.Failures == 0 {
+			suite.TestCases = append(suite.TestCases, junitTestCase{ClassName: rule.ID, Name: rule.Description})
+		}
+		suite.Tests = len(suite.TestCases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	buf, err := xml.MarshalIndent(&suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, buf)
+	return err
+}
+
+func writeIndentedJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
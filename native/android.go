@@ -0,0 +1,541 @@
+package native
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	androidValues  = "values"
+	androidStrings = "strings.xml"
+	androidHeader  = `<?xml version="1.0" encoding="utf-8"?>` + "\n" +
+		`<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">` + "\n"
+)
+
+// SaveAndroid writes values[-<locale>]/strings.xml file for every culture to res folder, default culture is written to values folder.
+// Arguments are written as <xliff:g id="name">%1$s</xliff:g>, so names of arguments are kept.
+// Keys are used as resource names, characters which are not allowed in names are replaced by "_".
+func SaveAndroid(logger *logrus.Logger, params Params, arbData *arb.Data) error {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
+	}
+
+	keys := arbData.OrderedKeys(params.Order)
+	for _, cn := range arbData.Cultures {
+		folder, err := androidFolder(cn, defaultCulture)
+		if err != nil {
+			return err
+		}
+
+		cultureKeys, messages, err := cultureKeys(logger, arbData, keys, cn)
+		if err != nil {
+			return err
+		}
+
+		buf := &bytes.Buffer{}
+		buf.WriteString(androidHeader)
+		names := make(map[string]string, len(cultureKeys))
+		for _, key := range cultureKeys {
+			name := androidName(key)
+			if other, ok := names[name]; ok {
+				return fmt.Errorf("keys %s and %s of culture %s have the same resource name %s: %w", other, key, cn, name, ErrInvalidAndroid)
+			}
+			names[name] = key
+
+			item := arbData.Items[key]
+			if item.Description != "" {
+				buf.WriteString("    <!-- " + commentText(item.Description) + " -->\n")
+			}
+			writeAndroidResource(logger, buf, name, key, item, messages[key], defaultCulture)
+		}
+		buf.WriteString("</resources>\n")
+
+		filePath := filepath.Join(params.FolderPath, folder, androidStrings)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+			return err
+		}
+		if err := arb.WriteFileIfChanged(logger, filePath, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeAndroidResource(logger *logrus.Logger, buf *bytes.Buffer, name, key string, item *arb.Item, m icu.Message, defaultCulture string) {
+	w := newPrintfWriter(androidVerbs, item.Parameters, positionalArgs(item, defaultCulture))
+	w.text = func(s string) string { return escapeXML(escapeAndroid(s)) }
+	w.arg = func(name, spec string) string {
+		return `<xliff:g id="` + escapeAttr(name) + `">` + spec + `</xliff:g>`
+	}
+	w.escapePercent = hasArguments(m)
+
+	if prefix, p, suffix := singlePlural(m); p != nil {
+		fmt.Fprintf(buf, "    <plurals name=\"%s\">\n", name)
+		for _, b := range flatPlural(logger, key, w, prefix, p, suffix, false) {
+			fmt.Fprintf(buf, "        <item quantity=\"%s\">%s</item>\n", b.category, androidValue(b.value))
+		}
+		buf.WriteString("    </plurals>\n")
+		return
+	}
+
+	s, err := w.write(m, "")
+	if err != nil {
+		logger.Warningf("key %s: %v, message is written as text", key, err)
+		w.escapePercent = false
+		s = w.text(m.String())
+	}
+	formatted := ""
+	if !w.escapePercent && strings.Contains(s, "%") {
+		formatted = ` formatted="false"`
+	}
+	fmt.Fprintf(buf, "    <string name=\"%s\"%s>%s</string>\n", name, formatted, androidValue(s))
+}
+
+// singlePlural returns plural argument with text before and after it, if message has one supported plural argument
+// and no select arguments.
+func singlePlural(m icu.Message) (icu.Message, *icu.Plural, icu.Message) {
+	index := -1
+	for i, n := range m {
+		switch n := n.(type) {
+		case *icu.Select:
+			return nil, nil, nil
+		case *icu.Plural:
+			if index >= 0 || !isPluralSupported(n) {
+				return nil, nil, nil
+			}
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, nil, nil
+	}
+	return m[:index], m[index].(*icu.Plural), m[index+1:]
+}
+
+// androidValue escapes "@" and "?" at the beginning of value, they mean references to resources.
+// Values with leading, trailing or repeated whitespaces are quoted, otherwise Android collapses them.
+func androidValue(s string) string {
+	if strings.HasPrefix(s, "@") || strings.HasPrefix(s, "?") {
+		s = `\` + s
+	}
+	if hasCollapsedSpaces(s) {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+func hasCollapsedSpaces(s string) bool {
+	if strings.TrimSpace(s) != s {
+		return true
+	}
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if space {
+				return true
+			}
+			space = true
+			continue
+		}
+		space = false
+	}
+	return false
+}
+
+func escapeAndroid(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return r.Replace(s)
+}
+
+func escapeXML(s string) string {
+	r := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return r.Replace(s)
+}
+
+func escapeAttr(s string) string {
+	return strings.ReplaceAll(escapeXML(s), `"`, "&quot;")
+}
+
+// androidName returns resource name of key.
+func androidName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// androidFolder returns values folder of culture: values-pt-rBR, values-b+zh+Hant+TW.
+func androidFolder(culture, defaultCulture string) (string, error) {
+	if culture == defaultCulture {
+		return androidValues, nil
+	}
+	t, err := locale.Parse(culture)
+	if err != nil {
+		return "", err
+	}
+	if t.Script != "" || len(t.Variants) > 0 || isNumericRegion(t.Region) {
+		return androidValues + "-b+" + strings.ReplaceAll(t.String(), "-", "+"), nil
+	}
+	folder := androidValues + "-" + t.Language
+	if t.Region != "" {
+		folder += "-r" + t.Region
+	}
+	return folder, nil
+}
+
+func isNumericRegion(region string) bool {
+	return region != "" && region[0] >= '0' && region[0] <= '9'
+}
+
+// androidCulture returns culture of values folder, folders with other qualifiers (values-night, values-v21) are skipped.
+func androidCulture(folder string) (string, bool) {
+	if folder == androidValues {
+		return "", true
+	}
+	qualifier := strings.TrimPrefix(folder, androidValues+"-")
+	if qualifier == folder {
+		return "", false
+	}
+
+	tag := qualifier
+	if strings.HasPrefix(qualifier, "b+") {
+		tag = strings.ReplaceAll(qualifier[2:], "+", "-")
+	} else {
+		parts := strings.Split(qualifier, "-")
+		switch {
+		case len(parts) == 1:
+		case len(parts) == 2 && len(parts[1]) == 3 && parts[1][0] == 'r':
+			tag = parts[0] + "-" + parts[1][1:]
+		default:
+			return "", false
+		}
+	}
+	culture, err := locale.Canonical(tag)
+	if err != nil {
+		return "", false
+	}
+	return culture, true
+}
+
+// LoadAndroid reads strings.xml files of values folders from res folder, values folder keeps default culture.
+// Arguments without <xliff:g> names are named by position: arg1, arg2.
+func LoadAndroid(logger *logrus.Logger, params Params) (*arb.Data, error) {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return nil, fmt.Errorf("default culture: %w", err)
+	}
+
+	infos, err := ioutil.ReadDir(params.FolderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	arbData := &arb.Data{Items: make(map[string]*arb.Item)}
+	files := make(map[string]string)
+	var folders []string
+	for _, fi := range infos {
+		if fi.IsDir() {
+			folders = append(folders, fi.Name())
+		}
+	}
+	// default culture goes first to keep its keys order
+	sort.SliceStable(folders, func(i, j int) bool { return folders[i] == androidValues && folders[j] != androidValues })
+
+	for _, folder := range folders {
+		culture, ok := androidCulture(folder)
+		if !ok {
+			logger.Debugf("skip folder %s", folder)
+			continue
+		}
+		if culture == "" {
+			culture = defaultCulture
+		}
+		filePath := filepath.Join(params.FolderPath, folder, androidStrings)
+		rawData, err := ioutil.ReadFile(filePath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if f, ok := files[culture]; ok {
+			return nil, fmt.Errorf("same cultures in [%s] and [%s]: %w", f, filePath, ErrInvalidAndroid)
+		}
+		files[culture] = filePath
+
+		logger.Tracef("load android file %s", filePath)
+		resources, err := parseAndroid(rawData)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidAndroid)
+		}
+		addCulture(arbData, culture)
+		for _, r := range resources {
+			msg, types := r.message()
+			if err := importedItem(arbData, r.name, culture, defaultCulture, msg, types); err != nil {
+				return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidAndroid)
+			}
+			if culture == defaultCulture {
+				arbData.Items[r.name].Description = r.comment
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no strings.xml files in %s: %w", params.FolderPath, ErrInvalidAndroid)
+	}
+	arbData.Cultures = arbData.OrderedCultures(defaultCulture)
+	return arbData, nil
+}
+
+// androidText is a content of string or plural item.
+type androidText struct {
+	value string
+	// ids keeps names of positional arguments from <xliff:g> elements
+	ids map[int]string
+}
+
+type androidResource struct {
+	name      string
+	comment   string
+	formatted bool
+	text      androidText
+	// plurals keeps items of <plurals> in file order
+	plurals []string
+	items   map[string]androidText
+}
+
+func parseAndroid(rawData []byte) ([]*androidResource, error) {
+	dec := xml.NewDecoder(bytes.NewReader(rawData))
+	var resources []*androidResource
+	comment := ""
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := t.(type) {
+		case xml.Comment:
+			comment = strings.TrimSpace(string(t))
+		case xml.StartElement:
+			if t.Name.Local == "resources" {
+				continue
+			}
+			r := &androidResource{name: attr(t, "name"), comment: comment, formatted: attr(t, "formatted") != "false"}
+			comment = ""
+			switch t.Name.Local {
+			case "string":
+				if r.text, err = readAndroidText(dec); err != nil {
+					return nil, err
+				}
+			case "plurals":
+				if err := readAndroidPlurals(dec, r); err != nil {
+					return nil, err
+				}
+			default:
+				// string-array, dimen and other resources
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if attr(t, "translatable") == "false" {
+				continue
+			}
+			resources = append(resources, r)
+		}
+	}
+	return resources, nil
+}
+
+func readAndroidPlurals(dec *xml.Decoder, r *androidResource) error {
+	r.items = make(map[string]androidText)
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			quantity := attr(t, "quantity")
+			text, err := readAndroidText(dec)
+			if err != nil {
+				return err
+			}
+			if _, ok := pluralCategories[quantity]; !ok {
+				return fmt.Errorf("plurals %s has invalid quantity %q", r.name, quantity)
+			}
+			r.plurals = append(r.plurals, quantity)
+			r.items[quantity] = text
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// readAndroidText reads content of element till its end, markup is removed, names of <xliff:g> arguments are collected.
+func readAndroidText(dec *xml.Decoder) (androidText, error) {
+	text := androidText{ids: make(map[int]string)}
+	sb := &strings.Builder{}
+	depth := 1
+	id := ""
+	idStart := 0
+	for depth > 0 {
+		t, err := dec.Token()
+		if err != nil {
+			return text, err
+		}
+		switch t := t.(type) {
+		case xml.CharData:
+			sb.Write(t)
+		case xml.StartElement:
+			depth++
+			if t.Name.Local == "g" {
+				id, idStart = attr(t, "id"), sb.Len()
+			}
+		case xml.EndElement:
+			depth--
+			if t.Name.Local == "g" && id != "" {
+				if m := printfRegexp.FindStringSubmatch(sb.String()[idStart:]); m != nil && m[1] != "" {
+					index, _ := strconv.Atoi(m[1])
+					text.ids[index] = id
+				}
+				id = ""
+			}
+		}
+	}
+	text.value = unescapeAndroid(sb.String())
+	return text, nil
+}
+
+// unescapeAndroid removes escaping and quotes, whitespaces out of quotes are collapsed.
+func unescapeAndroid(s string) string {
+	sb := &strings.Builder{}
+	src := []rune(strings.TrimSpace(s))
+	quoted := false
+	space := false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if !quoted && unicode.IsSpace(c) {
+			if !space {
+				sb.WriteRune(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'u':
+				if i+4 < len(src) {
+					if v, err := strconv.ParseUint(string(src[i+1:i+5]), 16, 32); err == nil {
+						sb.WriteRune(rune(v))
+						i += 4
+						continue
+					}
+				}
+				sb.WriteRune('u')
+			default:
+				sb.WriteRune(src[i])
+			}
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// message converts resource to icu message and returns types of its arguments.
+func (r *androidResource) message() (string, map[string]string) {
+	types := make(map[string]string)
+	if r.items == nil {
+		if !r.formatted {
+			return r.text.value, types
+		}
+		msg, _ := parsePrintf(r.text.value, func(index int, verb string) string {
+			name := r.text.name(index)
+			types[name] = placeholderType(verb)
+			return "{" + name + "}"
+		})
+		return msg, types
+	}
+
+	// plural argument is the first integer argument of items
+	pluralIndex := 0
+	pluralName := "count"
+	for _, q := range r.plurals {
+		text := r.items[q]
+		_, args := parsePrintf(text.value, func(int, string) string { return "" })
+		for _, a := range args {
+			if pluralIndex == 0 && placeholderType(a.verb) == typeInt {
+				pluralIndex, pluralName = a.index, text.name(a.index)
+			}
+		}
+	}
+	types[pluralName] = typeInt
+
+	sb := &strings.Builder{}
+	sb.WriteString("{" + pluralName + ", " + icu.TypePlural + ",")
+	for _, q := range r.plurals {
+		text := r.items[q]
+		branch, _ := parsePrintf(text.value, func(index int, verb string) string {
+			if index == pluralIndex {
+				return "#"
+			}
+			name := text.name(index)
+			types[name] = placeholderType(verb)
+			return "{" + name + "}"
+		})
+		sb.WriteString(" " + q + "{" + branch + "}")
+	}
+	if _, ok := r.items[locale.PluralOther]; !ok {
+		sb.WriteString(" " + locale.PluralOther + "{}")
+	}
+	sb.WriteString("}")
+	return sb.String(), types
+}
+
+func (t androidText) name(index int) string {
+	if id, ok := t.ids[index]; ok {
+		return id
+	}
+	return argName(index)
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,200 @@
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func createData() *arb.Data {
+	return &arb.Data{
+		Cultures: []string{"en", "pt-BR", "ru", "zh-Hant"},
+		Items: map[string]*arb.Item{
+			"hello": {
+				Description: "Greeting -- on main screen",
+				Cultures: map[string]string{
+					"en":      "Hello, {name}! It's {percent}% done",
+					"ru":      "Привет, {name}! Готово на {percent}%",
+					"pt-BR":   "Olá, {name}! \"{percent}%\"",
+					"zh-Hant": "",
+				},
+				Parameters: map[string]*arb.Placeholder{"name": {Type: "String"}, "percent": {Type: "int"}},
+			},
+			"items": {Cultures: map[string]string{
+				"en": "Found {count, plural, =0{no items} one{# item} other{# items}} in {place}",
+				"ru": "Найдено {count, plural, one{# предмет} few{# предмета} many{# предметов} other{# предмета}} в {place}",
+			}},
+			"gender": {Cultures: map[string]string{"en": "{g, select, male{He} other{They}}"}},
+			"plain":  {Cultures: map[string]string{"en": "@home 100%"}},
+		},
+		Keys: []string{"hello", "items", "gender", "plain"},
+	}
+}
+
+func TestAndroidFolder(t *testing.T) {
+	for culture, folder := range map[string]string{
+		"en":         "values",
+		"ru":         "values-ru",
+		"pt-BR":      "values-pt-rBR",
+		"zh-Hant-TW": "values-b+zh+Hant+TW",
+		"es-419":     "values-b+es+419",
+	} {
+		f, err := androidFolder(culture, "en")
+		require.NoError(t, err)
+		require.Equal(t, folder, f)
+
+		c, ok := androidCulture(folder)
+		require.True(t, ok)
+		if culture != "en" {
+			require.Equal(t, culture, c)
+		}
+	}
+
+	for _, folder := range []string{"values-night", "values-v21", "values-ru-rRU-land", "drawable"} {
+		_, ok := androidCulture(folder)
+		require.False(t, ok, folder)
+	}
+}
+
+func TestUnescapeAndroid(t *testing.T) {
+	require.Equal(t, `It's "a"  b\`+"\n", unescapeAndroid(` It\'s  \"a\"" "" b\\\n `))
+	require.Equal(t, "ab", unescapeAndroid(`ab`))
+	require.Equal(t, "  a  b ", unescapeAndroid(` "  a  b " `))
+}
+
+func TestSaveAndroidNameCollision(t *testing.T) {
+	dir, err := ioutil.TempDir("", "android")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	arbData := &arb.Data{
+		Cultures: []string{"en", "ru"},
+		Keys:     []string{"a.b", "a_b"},
+		Items: map[string]*arb.Item{
+			"a.b": {Cultures: map[string]string{"en": "Dot", "ru": "Точка"}},
+			"a_b": {Cultures: map[string]string{"en": "Underscore"}},
+		},
+	}
+	err = SaveAndroid(createLogger(), Params{FolderPath: dir, DefaultCulture: "en"}, arbData)
+	require.ErrorIs(t, err, ErrInvalidAndroid)
+	require.Contains(t, err.Error(), "a_b")
+
+	// names are unique for ru, because a_b has no translation
+	arbData.Items["a_b"].Cultures["en"] = ""
+	require.NoError(t, SaveAndroid(createLogger(), Params{FolderPath: dir, DefaultCulture: "en"}, arbData))
+}
+
+func TestSaveAndLoadAndroid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "android")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	params := Params{FolderPath: dir, DefaultCulture: "en"}
+	arbData := createData()
+	arbData.Items["spaced"] = &arb.Item{Cultures: map[string]string{"en": " Total:  {count} ", "ru": "Всего: {count}"}}
+	arbData.Keys = append(arbData.Keys, "spaced")
+	require.NoError(t, SaveAndroid(createLogger(), params, arbData))
+
+	en, err := ioutil.ReadFile(path.Join(dir, "values", "strings.xml"))
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <!-- Greeting - - on main screen -->
+    <string name="hello">Hello, <xliff:g id="name">%1$s</xliff:g>! It\'s <xliff:g id="percent">%2$d</xliff:g>%% done</string>
+    <plurals name="items">
+        <item quantity="one">Found <xliff:g id="count">%1$d</xliff:g> item in <xliff:g id="place">%2$s</xliff:g></item>
+        <item quantity="other">Found <xliff:g id="count">%1$d</xliff:g> items in <xliff:g id="place">%2$s</xliff:g></item>
+    </plurals>
+    <string name="gender">{g, select, male{He} other{They}}</string>
+    <string name="plain" formatted="false">\@home 100%</string>
+    <string name="spaced">" Total:  <xliff:g id="count">%1$s</xliff:g> "</string>
+</resources>
+`, string(en))
+
+	pt, err := ioutil.ReadFile(path.Join(dir, "values-pt-rBR", "strings.xml"))
+	require.NoError(t, err)
+	require.Contains(t, string(pt), `<string name="hello">Olá, <xliff:g id="name">%1$s</xliff:g>! \"<xliff:g id="percent">%2$d</xliff:g>%%\"</string>`)
+	require.FileExists(t, path.Join(dir, "values-b+zh+Hant", "strings.xml"))
+
+	loaded, err := LoadAndroid(createLogger(), params)
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "pt-BR", "ru", "zh-Hant"}, loaded.Cultures)
+	require.Equal(t, []string{"hello", "items", "gender", "plain", "spaced"}, loaded.Keys)
+
+	hello := loaded.Items["hello"]
+	require.Equal(t, "Greeting - - on main screen", hello.Description)
+	require.Equal(t, map[string]string{
+		"en":    "Hello, {name}! It's {percent}% done",
+		"ru":    "Привет, {name}! Готово на {percent}%",
+		"pt-BR": "Olá, {name}! \"{percent}%\"",
+	}, hello.Cultures)
+	require.Equal(t, map[string]*arb.Placeholder{"name": {Type: "String"}, "percent": {Type: "int"}}, hello.Parameters)
+
+	require.Equal(t, map[string]string{
+		"en": "{count, plural, one{Found # item in {place}} other{Found # items in {place}}}",
+		"ru": "{count, plural, one{Найдено # предмет в {place}} few{Найдено # предмета в {place}} many{Найдено # предметов в {place}} other{Найдено # предмета в {place}}}",
+	}, loaded.Items["items"].Cultures)
+	require.Equal(t, "{g, select, male{He} other{They}}", loaded.Items["gender"].Cultures["en"])
+	require.Equal(t, "@home 100%", loaded.Items["plain"].Cultures["en"])
+	require.Equal(t, map[string]string{"en": " Total:  {count} ", "ru": "Всего: {count}"}, loaded.Items["spaced"].Cultures)
+}
+
+func TestSaveAndroidUnchanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "android")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	params := Params{FolderPath: dir, DefaultCulture: "en"}
+	require.NoError(t, SaveAndroid(createLogger(), params, createData()))
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	enFile := path.Join(dir, "values", "strings.xml")
+	ruFile := path.Join(dir, "values-ru", "strings.xml")
+	require.NoError(t, os.Chtimes(enFile, old, old))
+	require.NoError(t, os.Chtimes(ruFile, old, old))
+
+	arbData := createData()
+	arbData.Items["plain"].Cultures["ru"] = "@дом"
+	require.NoError(t, SaveAndroid(createLogger(), params, arbData))
+	fi, err := os.Stat(enFile)
+	require.NoError(t, err)
+	require.Equal(t, old, fi.ModTime())
+	fi, err = os.Stat(ruFile)
+	require.NoError(t, err)
+	require.NotEqual(t, old, fi.ModTime())
+}
+
+func TestLoadAndroidPositional(t *testing.T) {
+	dir, err := ioutil.TempDir("", "android")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(path.Join(dir, "values-night"), 0777))
+	require.NoError(t, os.MkdirAll(path.Join(dir, "values"), 0777))
+	require.NoError(t, ioutil.WriteFile(path.Join(dir, "values", "strings.xml"), []byte(`<resources>
+    <string name="app_name" translatable="false">App</string>
+    <string name="welcome">Hi %s, you have <b>%d</b> messages</string>
+    <plurals name="files">
+        <item quantity="one">%d file</item>
+        <item quantity="other">%d files</item>
+    </plurals>
+</resources>`), 0644))
+
+	loaded, err := LoadAndroid(createLogger(), Params{FolderPath: dir, DefaultCulture: "en"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"welcome", "files"}, loaded.Keys)
+	require.Equal(t, "Hi {arg1}, you have {arg2} messages", loaded.Items["welcome"].Cultures["en"])
+	require.Equal(t, map[string]*arb.Placeholder{"arg1": {Type: "String"}, "arg2": {Type: "int"}}, loaded.Items["welcome"].Parameters)
+	require.Equal(t, "{arg1, plural, one{# file} other{# files}}", loaded.Items["files"].Cultures["en"])
+}
+
+func createLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.TraceLevel)
+	return logger
+}
@@ -0,0 +1,481 @@
+package native
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	lprojExt        = ".lproj"
+	iosStrings      = "Localizable.strings"
+	iosStringsdict  = "Localizable.stringsdict"
+	iosNoComment    = "No comment provided by engineer."
+	iosFormatKey    = "NSStringLocalizedFormatKey"
+	iosSpecTypeKey  = "NSStringFormatSpecTypeKey"
+	iosValueTypeKey = "NSStringFormatValueTypeKey"
+	iosPluralType   = "NSStringPluralRuleType"
+	plistHeader     = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n" +
+		`<plist version="1.0">` + "\n"
+)
+
+// SaveIos writes <locale>.lproj/Localizable.strings file for every culture, messages with plural arguments
+// are written to Localizable.stringsdict. Names of arguments are not kept by .strings files.
+func SaveIos(logger *logrus.Logger, params Params, arbData *arb.Data) error {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
+	}
+
+	keys := arbData.OrderedKeys(params.Order)
+	for _, cn := range arbData.Cultures {
+		cultureKeys, messages, err := cultureKeys(logger, arbData, keys, cn)
+		if err != nil {
+			return err
+		}
+
+		stringsBuf := &bytes.Buffer{}
+		dictBuf := &bytes.Buffer{}
+		for _, key := range cultureKeys {
+			item := arbData.Items[key]
+			m := messages[key]
+			if plurals := topPlurals(m); len(plurals) > 0 {
+				writeStringsdictEntry(logger, dictBuf, key, item, m, defaultCulture)
+				continue
+			}
+
+			w := newPrintfWriter(iosVerbs, item.Parameters, positionalArgs(item, defaultCulture))
+			w.text = escapeStrings
+			w.escapePercent = hasArguments(m)
+			s, err := w.write(m, "")
+			if err != nil {
+				logger.Warningf("key %s: %v, message is written as text", key, err)
+				s = escapeStrings(m.String())
+			}
+
+			if item.Description != "" {
+				stringsBuf.WriteString("/* " + commentText(item.Description) + " */\n")
+			}
+			stringsBuf.WriteString(`"` + escapeStrings(key) + `" = "` + s + "\";\n\n")
+		}
+
+		folder := filepath.Join(params.FolderPath, cn+lprojExt)
+		if err := os.MkdirAll(folder, 0777); err != nil {
+			return err
+		}
+		if err := arb.WriteFileIfChanged(logger, filepath.Join(folder, iosStrings), stringsBuf.Bytes()); err != nil {
+			return err
+		}
+		dictPath := filepath.Join(folder, iosStringsdict)
+		if dictBuf.Len() == 0 {
+			// stringsdict entries take precedence over strings, so previous plurals must not be kept
+			if err := os.Remove(dictPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		dict := append([]byte(plistHeader+"<dict>\n"), dictBuf.Bytes()...)
+		dict = append(dict, "</dict>\n</plist>\n"...)
+		if err := arb.WriteFileIfChanged(logger, dictPath, dict); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topPlurals returns plural arguments of message if all of them are supported and message has no select arguments.
+func topPlurals(m icu.Message) []*icu.Plural {
+	var plurals []*icu.Plural
+	for _, n := range m {
+		switch n := n.(type) {
+		case *icu.Select:
+			return nil
+		case *icu.Plural:
+			if !isPluralSupported(n) {
+				return nil
+			}
+			plurals = append(plurals, n)
+		}
+	}
+	return plurals
+}
+
+func writeStringsdictEntry(logger *logrus.Logger, buf *bytes.Buffer, key string, item *arb.Item, m icu.Message, defaultCulture string) {
+	w := newPrintfWriter(iosVerbs, item.Parameters, positionalArgs(item, defaultCulture))
+	w.text = escapeXML
+	w.escapePercent = true
+
+	format, plurals, branches := variableFormat(logger, key, w, m)
+
+	writePlistString(buf, 1, "", key)
+	buf.WriteString("    <dict>\n")
+	writePlistString(buf, 2, iosFormatKey, format)
+	for _, p := range plurals {
+		writePlistString(buf, 2, "", p.Name)
+		buf.WriteString("        <dict>\n")
+		writePlistString(buf, 3, iosSpecTypeKey, iosPluralType)
+		writePlistString(buf, 3, iosValueTypeKey, iosVerbs.integer)
+		for _, b := range branches[p.Name] {
+			writePlistString(buf, 3, b.category, b.value)
+		}
+		buf.WriteString("        </dict>\n")
+	}
+	buf.WriteString("    </dict>\n")
+}
+
+// writePlistString writes <key> element and <string> element if value is not empty, value must be escaped.
+func writePlistString(buf *bytes.Buffer, depth int, key, value string) {
+	indent := strings.Repeat("    ", depth)
+	if key == "" {
+		buf.WriteString(indent + "<key>" + escapeXML(value) + "</key>\n")
+		return
+	}
+	buf.WriteString(indent + "<key>" + escapeXML(key) + "</key>\n")
+	buf.WriteString(indent + "<string>" + value + "</string>\n")
+}
+
+func escapeStrings(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return r.Replace(s)
+}
+
+// LoadIos reads Localizable.strings and Localizable.stringsdict files of .lproj folders,
+// arguments are named by position (arg1, arg2), plural arguments keep names of stringsdict variables.
+func LoadIos(logger *logrus.Logger, params Params) (*arb.Data, error) {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return nil, fmt.Errorf("default culture: %w", err)
+	}
+
+	infos, err := ioutil.ReadDir(params.FolderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	type lproj struct {
+		folder, culture string
+	}
+	var folders []lproj
+	for _, fi := range infos {
+		if !fi.IsDir() || !strings.HasSuffix(fi.Name(), lprojExt) {
+			continue
+		}
+		culture, err := locale.Canonical(strings.TrimSuffix(fi.Name(), lprojExt))
+		if err != nil {
+			logger.Debugf("skip folder %s", fi.Name())
+			continue
+		}
+		folders = append(folders, lproj{folder: fi.Name(), culture: culture})
+	}
+	// default culture goes first to keep its keys order
+	sort.SliceStable(folders, func(i, j int) bool {
+		return folders[i].culture == defaultCulture && folders[j].culture != defaultCulture
+	})
+
+	arbData := &arb.Data{Items: make(map[string]*arb.Item)}
+	files := 0
+	for _, f := range folders {
+		if !addCulture(arbData, f.culture) {
+			return nil, fmt.Errorf("same cultures in several folders %s: %w", f.folder, ErrInvalidIos)
+		}
+
+		filePath := filepath.Join(params.FolderPath, f.folder, iosStrings)
+		if rawData, err := ioutil.ReadFile(filePath); err == nil {
+			files++
+			logger.Tracef("load ios file %s", filePath)
+			entries, err := parseStrings(decodeStrings(rawData))
+			if err != nil {
+				return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidIos)
+			}
+			for _, e := range entries {
+				types := make(map[string]string)
+				msg, _ := parsePrintf(e.value, func(index int, verb string) string {
+					types[argName(index)] = placeholderType(verb)
+					return "{" + argName(index) + "}"
+				})
+				if err := importedItem(arbData, e.key, f.culture, defaultCulture, msg, types); err != nil {
+					return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidIos)
+				}
+				if f.culture == defaultCulture && e.comment != iosNoComment {
+					arbData.Items[e.key].Description = e.comment
+				}
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		filePath = filepath.Join(params.FolderPath, f.folder, iosStringsdict)
+		if rawData, err := ioutil.ReadFile(filePath); err == nil {
+			files++
+			logger.Tracef("load ios file %s", filePath)
+			if err := loadStringsdict(arbData, rawData, f.culture, defaultCulture); err != nil {
+				return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidIos)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if files == 0 {
+		return nil, fmt.Errorf("no localizable files in %s: %w", params.FolderPath, ErrInvalidIos)
+	}
+	arbData.Cultures = arbData.OrderedCultures(defaultCulture)
+	return arbData, nil
+}
+
+// decodeStrings returns content of .strings file, files with BOM are decoded from UTF-16.
+func decodeStrings(rawData []byte) string {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(rawData, []byte{0xFF, 0xFE}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(rawData, []byte{0xFE, 0xFF}):
+		order = binary.BigEndian
+	default:
+		return strings.TrimPrefix(string(rawData), "\uFEFF")
+	}
+	units := make([]uint16, (len(rawData)-2)/2)
+	for i := range units {
+		units[i] = order.Uint16(rawData[2+i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+type stringsEntry struct {
+	key, value, comment string
+}
+
+// parseStrings parses "key" = "value"; pairs of .strings file, comment before pair is kept.
+func parseStrings(s string) ([]*stringsEntry, error) {
+	src := []rune(s)
+	pos := 0
+	line := func() int {
+		return strings.Count(string(src[:pos]), "\n") + 1
+	}
+	skipSpaces := func() {
+		for pos < len(src) && unicode.IsSpace(src[pos]) {
+			pos++
+		}
+	}
+
+	var entries []*stringsEntry
+	comment := ""
+	for {
+		skipSpaces()
+		if pos >= len(src) {
+			return entries, nil
+		}
+
+		rest := string(src[pos:])
+		switch {
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unclosed comment", line())
+			}
+			comment = strings.TrimSpace(rest[2:end])
+			pos += len([]rune(rest[:end+2]))
+			continue
+		case strings.HasPrefix(rest, "//"):
+			end := strings.Index(rest, "\n")
+			if end < 0 {
+				end = len(rest)
+			}
+			comment = strings.TrimSpace(rest[2:end])
+			pos += len([]rune(rest[:end]))
+			continue
+		}
+
+		e := &stringsEntry{comment: comment}
+		comment = ""
+		var err error
+		if e.key, err = readStringsToken(src, &pos); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line(), err)
+		}
+		skipSpaces()
+		if pos >= len(src) || src[pos] != '=' {
+			return nil, fmt.Errorf("line %d: '=' expected", line())
+		}
+		pos++
+		skipSpaces()
+		if e.value, err = readStringsToken(src, &pos); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line(), err)
+		}
+		skipSpaces()
+		if pos >= len(src) || src[pos] != ';' {
+			return nil, fmt.Errorf("line %d: ';' expected", line())
+		}
+		pos++
+		entries = append(entries, e)
+	}
+}
+
+// readStringsToken reads quoted string or unquoted word.
+func readStringsToken(src []rune, pos *int) (string, error) {
+	sb := &strings.Builder{}
+	if *pos < len(src) && src[*pos] != '"' {
+		for *pos < len(src) && (unicode.IsLetter(src[*pos]) || unicode.IsDigit(src[*pos]) || strings.ContainsRune("_.-", src[*pos])) {
+			sb.WriteRune(src[*pos])
+			*pos++
+		}
+		if sb.Len() == 0 {
+			return "", fmt.Errorf("string expected")
+		}
+		return sb.String(), nil
+	}
+
+	*pos++
+	for ; *pos < len(src); *pos++ {
+		c := src[*pos]
+		switch {
+		case c == '"':
+			*pos++
+			return sb.String(), nil
+		case c == '\\' && *pos+1 < len(src):
+			*pos++
+			switch src[*pos] {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			case 'u', 'U':
+				if *pos+4 < len(src) {
+					if v, err := strconv.ParseUint(string(src[*pos+1:*pos+5]), 16, 32); err == nil {
+						sb.WriteRune(rune(v))
+						*pos += 4
+						continue
+					}
+				}
+				sb.WriteRune(src[*pos])
+			default:
+				sb.WriteRune(src[*pos])
+			}
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return "", fmt.Errorf("unclosed string")
+}
+
+// plistValue is a string or dictionary value of property list, other values are ignored.
+type plistValue struct {
+	str  string
+	dict []*plistEntry
+}
+
+type plistEntry struct {
+	key   string
+	value *plistValue
+}
+
+func (v *plistValue) get(key string) *plistValue {
+	if v == nil {
+		return nil
+	}
+	for _, e := range v.dict {
+		if e.key == key {
+			return e.value
+		}
+	}
+	return nil
+}
+
+func (v *plistValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.str
+}
+
+func parsePlist(rawData []byte) (*plistValue, error) {
+	dec := xml.NewDecoder(bytes.NewReader(rawData))
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("dict expected")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e, ok := t.(xml.StartElement); ok && e.Name.Local == "dict" {
+			return readPlistValue(dec, e)
+		}
+	}
+}
+
+func readPlistValue(dec *xml.Decoder, start xml.StartElement) (*plistValue, error) {
+	v := &plistValue{}
+	if start.Name.Local != "dict" {
+		var s string
+		if err := dec.DecodeElement(&s, &start); err != nil {
+			return nil, err
+		}
+		v.str = s
+		return v, nil
+	}
+
+	key := ""
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "key" {
+				if err := dec.DecodeElement(&key, &t); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value, err := readPlistValue(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			v.dict = append(v.dict, &plistEntry{key: key, value: value})
+		case xml.EndElement:
+			return v, nil
+		}
+	}
+}
+
+// loadStringsdict adds plural messages of stringsdict file, %#@name@ variables become plural arguments.
+func loadStringsdict(arbData *arb.Data, rawData []byte, culture, defaultCulture string) error {
+	root, err := parsePlist(rawData)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range root.dict {
+		types := make(map[string]string)
+		msg := formatWithVariables(e.value.get(iosFormatKey).String(), types, func(variable string) []pluralBranch {
+			var branches []pluralBranch
+			for _, o := range e.value.get(variable).dict {
+				branches = append(branches, pluralBranch{category: o.key, value: o.value.String()})
+			}
+			return branches
+		})
+		if err := importedItem(arbData, e.key, culture, defaultCulture, msg, types); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStrings(t *testing.T) {
+	entries, err := parseStrings(`// header
+/* Greeting */
+"hello" = "Hello, \"%@\"\n";
+bye = "Bye \U263A";
+"a" = "b";`)
+	require.NoError(t, err)
+	require.Equal(t, []*stringsEntry{
+		{key: "hello", value: "Hello, \"%@\"\n", comment: "Greeting"},
+		{key: "bye", value: "Bye ☺"},
+		{key: "a", value: "b"},
+	}, entries)
+
+	_, err = parseStrings(`"a" = "b"`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "';' expected")
+
+	units := utf16.Encode([]rune(`"a" = "б";`))
+	raw := []byte{0xFF, 0xFE}
+	for _, u := range units {
+		raw = append(raw, byte(u), byte(u>>8))
+	}
+	require.Equal(t, `"a" = "б";`, decodeStrings(raw))
+}
+
+func TestSaveAndLoadIos(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ios")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	params := Params{FolderPath: dir, DefaultCulture: "en"}
+	require.NoError(t, SaveIos(createLogger(), params, createData()))
+
+	en, err := ioutil.ReadFile(path.Join(dir, "en.lproj", "Localizable.strings"))
+	require.NoError(t, err)
+	require.Equal(t, `/* Greeting - - on main screen */
+"hello" = "Hello, %1$@! It's %2$ld%% done";
+
+"gender" = "{g, select, male{He} other{They}}";
+
+"plain" = "@home 100%";
+
+`, string(en))
+
+	dict, err := ioutil.ReadFile(path.Join(dir, "en.lproj", "Localizable.stringsdict"))
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+    <key>items</key>
+    <dict>
+        <key>NSStringLocalizedFormatKey</key>
+        <string>Found %1$#@count@ in %2$@</string>
+        <key>count</key>
+        <dict>
+            <key>NSStringFormatSpecTypeKey</key>
+            <string>NSStringPluralRuleType</string>
+            <key>NSStringFormatValueTypeKey</key>
+            <string>ld</string>
+            <key>zero</key>
+            <string>no items</string>
+            <key>one</key>
+            <string>%1$ld item</string>
+            <key>other</key>
+            <string>%1$ld items</string>
+        </dict>
+    </dict>
+</dict>
+</plist>
+`, string(dict))
+	require.FileExists(t, path.Join(dir, "pt-BR.lproj", "Localizable.strings"))
+	require.NoFileExists(t, path.Join(dir, "pt-BR.lproj", "Localizable.stringsdict"))
+
+	loaded, err := LoadIos(createLogger(), params)
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "pt-BR", "ru", "zh-Hant"}, loaded.Cultures)
+	require.Equal(t, []string{"hello", "gender", "plain", "items"}, loaded.Keys)
+	require.Equal(t, "Greeting - - on main screen", loaded.Items["hello"].Description)
+	require.Equal(t, map[string]string{
+		"en":    "Hello, {arg1}! It's {arg2}% done",
+		"ru":    "Привет, {arg1}! Готово на {arg2}%",
+		"pt-BR": "Olá, {arg1}! \"{arg2}%\"",
+	}, loaded.Items["hello"].Cultures)
+	require.Equal(t, "{g, select, male{He} other{They}}", loaded.Items["gender"].Cultures["en"])
+	require.Equal(t, "@home 100%", loaded.Items["plain"].Cultures["en"])
+	require.Equal(t, map[string]string{
+		"en": "Found {count, plural, zero{no items} one{# item} other{# items}} in {arg2}",
+		"ru": "Найдено {count, plural, one{# предмет} few{# предмета} many{# предметов} other{# предмета}} в {arg2}",
+	}, loaded.Items["items"].Cultures)
+	require.Equal(t, "int", loaded.Items["items"].Parameters["count"].Type)
+}
+
+func TestSaveIosRemovesStringsdict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ios")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	params := Params{FolderPath: dir, DefaultCulture: "en"}
+	arbData := createData()
+	require.NoError(t, SaveIos(createLogger(), params, arbData))
+	require.FileExists(t, path.Join(dir, "ru.lproj", "Localizable.stringsdict"))
+
+	arbData.Items["items"].Cultures["en"] = "Found items in {place}"
+	arbData.Items["items"].Cultures["ru"] = "Найдены предметы в {place}"
+	require.NoError(t, SaveIos(createLogger(), params, arbData))
+	require.NoFileExists(t, path.Join(dir, "en.lproj", "Localizable.stringsdict"))
+	require.NoFileExists(t, path.Join(dir, "ru.lproj", "Localizable.stringsdict"))
+
+	loaded, err := LoadIos(createLogger(), params)
+	require.NoError(t, err)
+	require.Equal(t, "Найдены предметы в {arg1}", loaded.Items["items"].Cultures["ru"])
+}
@@ -0,0 +1,249 @@
+// Package native writes arb data to resources of native mobile applications and reads them back:
+// Android values-<locale>/strings.xml, iOS <locale>.lproj/Localizable.strings with Localizable.stringsdict
+// and Xcode string catalogs (.xcstrings).
+// Arguments of messages become positional printf arguments ("%1$s", "%1$@"), simple plural messages
+// become Android <plurals> and iOS stringsdict entries.
+package native
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidAndroid   = errors.New("invalid android resources")
+	ErrInvalidIos       = errors.New("invalid ios resources")
+	ErrInvalidXcstrings = errors.New("invalid xcstrings")
+)
+
+type Params struct {
+	// FolderPath is a path of Android res folder or folder with iOS .lproj folders
+	FolderPath     string
+	DefaultCulture string
+	Order          arb.Order
+}
+
+// pluralCategories are plural categories supported by Android and iOS.
+var pluralCategories = map[string]struct{}{
+	locale.PluralZero:  {},
+	locale.PluralOne:   {},
+	locale.PluralTwo:   {},
+	locale.PluralFew:   {},
+	locale.PluralMany:  {},
+	locale.PluralOther: {},
+}
+
+// isPluralSupported reports whether plural argument can be written as native plural:
+// cardinal plural without offset, branches without nested plural and select arguments.
+func isPluralSupported(p *icu.Plural) bool {
+	if p.Ordinal || p.PluralOffset != 0 {
+		return false
+	}
+	for _, o := range p.Options {
+		for _, n := range o.Value {
+			switch n.(type) {
+			case *icu.Plural, *icu.Select:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// pluralOptions returns options of plural with categories selectors. Exact selectors are not supported by
+// native plurals: "=0" becomes "zero" if zeroExact is set and message has no "zero" branch, others are skipped.
+func pluralOptions(logger *logrus.Logger, key string, p *icu.Plural, zeroExact bool) []*icu.Option {
+	selectors := make(map[string]struct{}, len(p.Options))
+	for _, o := range p.Options {
+		selectors[o.Selector] = struct{}{}
+	}
+
+	var options []*icu.Option
+	for _, o := range p.Options {
+		if _, ok := pluralCategories[o.Selector]; ok {
+			options = append(options, o)
+			continue
+		}
+		if _, ok := selectors[locale.PluralZero]; zeroExact && o.Selector == "=0" && !ok {
+			options = append(options, &icu.Option{Offset: o.Offset, Selector: locale.PluralZero, Value: o.Value})
+			continue
+		}
+		logger.Warningf("key %s: plural branch %s of %s is skipped", key, o.Selector, p.Name)
+	}
+	return options
+}
+
+// pluralBranch is a printf text of native plural category.
+type pluralBranch struct {
+	category, value string
+}
+
+// variableFormat converts message with supported plural arguments to printf format with %n$#@name@ variables
+// (stringsdict, xcstrings substitutions), branches keep printf texts of plural categories of every variable.
+func variableFormat(logger *logrus.Logger, key string, w *printfWriter, m icu.Message) (string, []*icu.Plural, map[string][]pluralBranch) {
+	format := &strings.Builder{}
+	var plurals []*icu.Plural
+	branches := make(map[string][]pluralBranch)
+	for _, n := range m {
+		if p, ok := n.(*icu.Plural); ok {
+			w.plurals[p.Name] = struct{}{}
+			fmt.Fprintf(format, "%%%d$#@%s@", w.index(p.Name), p.Name)
+			plurals = append(plurals, p)
+			for _, o := range pluralOptions(logger, key, p, true) {
+				s, _ := w.write(o.Value, p.Name)
+				branches[p.Name] = append(branches[p.Name], pluralBranch{category: o.Selector, value: s})
+			}
+			continue
+		}
+		s, _ := w.write(icu.Message{n}, "")
+		format.WriteString(s)
+	}
+	return format.String(), plurals, branches
+}
+
+// flatPlural returns printf texts of plural categories of message with single plural argument,
+// text before and after plural argument is copied to every branch (Android plurals, xcstrings variations).
+func flatPlural(logger *logrus.Logger, key string, w *printfWriter, prefix icu.Message, p *icu.Plural, suffix icu.Message, zeroExact bool) []pluralBranch {
+	w.plurals[p.Name] = struct{}{}
+	var branches []pluralBranch
+	for _, o := range pluralOptions(logger, key, p, zeroExact) {
+		var parts []string
+		for _, part := range []icu.Message{prefix, o.Value, suffix} {
+			s, _ := w.write(part, p.Name)
+			parts = append(parts, s)
+		}
+		branches = append(branches, pluralBranch{category: o.Selector, value: strings.Join(parts, "")})
+	}
+	return branches
+}
+
+// pluralArgument builds plural argument from native plural branches. Argument at position index is the plural value
+// and is written as "#", so is the first unpositioned integer argument of branch without positional arguments.
+// Branches of categories unknown for icu are skipped.
+func pluralArgument(variable string, index int, branches []pluralBranch, name func(int) string, types map[string]string) string {
+	sb := &strings.Builder{}
+	sb.WriteString("{" + variable + ", " + icu.TypePlural + ",")
+	hasOther := false
+	for _, b := range branches {
+		if _, ok := pluralCategories[b.category]; !ok {
+			continue
+		}
+		positional := strings.Contains(b.value, "$")
+		branch, _ := parsePrintf(b.value, func(i int, verb string) string {
+			if i == index || (i == 1 && !positional && placeholderType(verb) == typeInt) {
+				return "#"
+			}
+			types[name(i)] = placeholderType(verb)
+			return "{" + name(i) + "}"
+		})
+		sb.WriteString(" " + b.category + "{" + branch + "}")
+		hasOther = hasOther || b.category == locale.PluralOther
+	}
+	if !hasOther {
+		sb.WriteString(" " + locale.PluralOther + "{}")
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// formatWithVariables converts printf format with %#@name@ variables (stringsdict, xcstrings substitutions)
+// to icu message, branches returns plural branches of variable.
+func formatWithVariables(format string, types map[string]string, branches func(variable string) []pluralBranch) string {
+	// names of positions of variables are known before conversion of other arguments
+	names := make(map[int]string)
+	_, args := parsePrintf(format, func(int, string) string { return "" })
+	for _, a := range args {
+		if strings.HasPrefix(a.verb, "#@") {
+			names[a.index] = strings.Trim(a.verb, "#@")
+		}
+	}
+	name := func(index int) string {
+		if n, ok := names[index]; ok {
+			return n
+		}
+		return argName(index)
+	}
+
+	msg, _ := parsePrintf(format, func(index int, verb string) string {
+		if !strings.HasPrefix(verb, "#@") {
+			types[name(index)] = placeholderType(verb)
+			return "{" + name(index) + "}"
+		}
+		variable := strings.Trim(verb, "#@")
+		types[variable] = typeInt
+		return pluralArgument(variable, index, branches(variable), name, types)
+	})
+	return msg
+}
+
+// cultureKeys returns keys with non empty messages of culture and their parsed messages.
+func cultureKeys(logger *logrus.Logger, arbData *arb.Data, keys []string, culture string) ([]string, map[string]icu.Message, error) {
+	var res []string
+	messages := make(map[string]icu.Message)
+	for _, key := range keys {
+		msg := arbData.Items[key].Cultures[culture]
+		if msg == "" {
+			continue
+		}
+		m, err := icu.Parse(msg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid message %s for culture %s (%v): %w", key, culture, err, arb.ErrArbFile)
+		}
+		res = append(res, key)
+		messages[key] = m
+	}
+	logger.Debugf("culture %s has %d message(s)", culture, len(res))
+	return res, messages, nil
+}
+
+// positionalArgs returns positional arguments of item, they are the same for all cultures.
+func positionalArgs(item *arb.Item, defaultCulture string) []string {
+	m, err := icu.Parse(item.Cultures[defaultCulture])
+	if err != nil {
+		m = nil
+	}
+	return argumentNames(m, item.Parameters)
+}
+
+// importedItem adds message of culture to arb data, placeholders of default culture are declared with types of printf verbs.
+func importedItem(arbData *arb.Data, key, culture, defaultCulture, msg string, types map[string]string) error {
+	if err := icu.Validate(msg); err != nil {
+		return fmt.Errorf("invalid message %s for culture %s (%v)", key, culture, err)
+	}
+	item, ok := arbData.Items[key]
+	if !ok {
+		item = &arb.Item{Cultures: make(map[string]string)}
+		arbData.Items[key] = item
+		arbData.Keys = append(arbData.Keys, key)
+	}
+	item.Cultures[culture] = msg
+	if culture == defaultCulture && len(types) > 0 {
+		item.Parameters = make(map[string]*arb.Placeholder, len(types))
+		for name, t := range types {
+			item.Parameters[name] = &arb.Placeholder{Type: t}
+		}
+	}
+	return nil
+}
+
+func addCulture(arbData *arb.Data, culture string) bool {
+	for _, c := range arbData.Cultures {
+		if c == culture {
+			return false
+		}
+	}
+	arbData.Cultures = append(arbData.Cultures, culture)
+	return true
+}
+
+// commentText makes description safe for xml and c comments.
+func commentText(s string) string {
+	s = strings.ReplaceAll(s, "--", "- -")
+	return strings.ReplaceAll(s, "*/", "* /")
+}
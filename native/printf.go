@@ -0,0 +1,213 @@
+package native
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+)
+
+// verbs are printf verbs of platform for integer, floating point and other arguments.
+type verbs struct {
+	integer, float, object string
+}
+
+var (
+	androidVerbs = verbs{integer: "d", float: "f", object: "s"}
+	iosVerbs     = verbs{integer: "ld", float: "f", object: "@"}
+)
+
+// Placeholder types of arb (flutter gen-l10n).
+const (
+	typeInt    = "int"
+	typeDouble = "double"
+	typeNum    = "num"
+	typeString = "String"
+)
+
+// printfWriter converts icu messages to printf strings with positional arguments: "Hi {name}" -> "Hi %1$s".
+type printfWriter struct {
+	verbs  verbs
+	params map[string]*arb.Placeholder
+	// args keeps positional arguments, index of argument is its position minus one
+	args []string
+	// plurals keeps names of plural arguments, they are always integers
+	plurals map[string]struct{}
+	// text escapes literal text
+	text func(s string) string
+	// arg writes argument with printf specifier ("%1$s")
+	arg func(name, spec string) string
+	// escapePercent is set if message has arguments, "%" of text is written as "%%"
+	escapePercent bool
+}
+
+func newPrintfWriter(v verbs, params map[string]*arb.Placeholder, args []string) *printfWriter {
+	return &printfWriter{
+		verbs:   v,
+		params:  params,
+		args:    args,
+		plurals: make(map[string]struct{}),
+		text:    func(s string) string { return s },
+		arg:     func(_, spec string) string { return spec },
+	}
+}
+
+func (w *printfWriter) index(name string) int {
+	for i, a := range w.args {
+		if a == name {
+			return i + 1
+		}
+	}
+	w.args = append(w.args, name)
+	return len(w.args)
+}
+
+func (w *printfWriter) verb(name string) string {
+	if _, ok := w.plurals[name]; ok {
+		return w.verbs.integer
+	}
+	p := w.params[name]
+	if p == nil {
+		return w.verbs.object
+	}
+	switch {
+	case p.Type == typeInt && p.Format == "":
+		return w.verbs.integer
+	case (p.Type == typeDouble || p.Type == typeNum) && p.Format == "":
+		return w.verbs.float
+	}
+	return w.verbs.object
+}
+
+// spec returns positional printf specifier of argument.
+func (w *printfWriter) spec(name string) string {
+	return w.arg(name, fmt.Sprintf("%%%d$%s", w.index(name), w.verb(name)))
+}
+
+// write converts message without plural and select arguments, pound is a name of plural argument of branch.
+func (w *printfWriter) write(m icu.Message, pound string) (string, error) {
+	sb := &strings.Builder{}
+	for _, n := range m {
+		switch n := n.(type) {
+		case *icu.Text:
+			s := n.Value
+			if w.escapePercent {
+				s = strings.ReplaceAll(s, "%", "%%")
+			}
+			sb.WriteString(w.text(s))
+		case *icu.Argument:
+			sb.WriteString(w.spec(n.Name))
+		case *icu.Pound:
+			sb.WriteString(w.spec(pound))
+		default:
+			return "", fmt.Errorf("nested plural and select arguments are not supported")
+		}
+	}
+	return sb.String(), nil
+}
+
+// argumentNames returns names of arguments in order of first appearance in message,
+// declared but unused placeholders are appended in alphabetical order.
+func argumentNames(m icu.Message, params map[string]*arb.Placeholder) []string {
+	var names []string
+	known := make(map[string]struct{})
+	add := func(name string) {
+		if _, ok := known[name]; !ok {
+			known[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+
+	var walk func(m icu.Message)
+	walk = func(m icu.Message) {
+		for _, n := range m {
+			switch n := n.(type) {
+			case *icu.Argument:
+				add(n.Name)
+			case *icu.Plural:
+				add(n.Name)
+				for _, o := range n.Options {
+					walk(o.Value)
+				}
+			case *icu.Select:
+				add(n.Name)
+				for _, o := range n.Options {
+					walk(o.Value)
+				}
+			}
+		}
+	}
+	walk(m)
+
+	var unused []string
+	for name := range params {
+		if _, ok := known[name]; !ok {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return append(names, unused...)
+}
+
+// hasArguments reports whether message has any arguments.
+func hasArguments(m icu.Message) bool {
+	for _, n := range m {
+		if _, ok := n.(*icu.Text); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+var printfRegexp = regexp.MustCompile(`%(?:(\d+)\$)?(#@[^@]*@|[-#+0,(]*\d*(?:\.\d+)?(?:hh|h|ll|l|q|z|t|j|L)?[@dDiuUxXoOfFeEgGaAcCsSp%])`)
+
+// printfArg is an argument found by parsePrintf.
+type printfArg struct {
+	index int
+	verb  string
+}
+
+// parsePrintf converts printf string to icu message text, replace returns text of argument ("{name}", "#") by its position.
+// Arguments without position take positions in order of appearance, "%%" is converted to "%".
+func parsePrintf(s string, replace func(index int, verb string) string) (string, []printfArg) {
+	var args []printfArg
+	next := 0
+	res := printfRegexp.ReplaceAllStringFunc(s, func(spec string) string {
+		m := printfRegexp.FindStringSubmatch(spec)
+		if m[2] == "%" {
+			return "%"
+		}
+		index := next + 1
+		if m[1] != "" {
+			index, _ = strconv.Atoi(m[1])
+		}
+		next = index
+		verb := m[2]
+		if !strings.HasPrefix(verb, "#@") {
+			verb = strings.TrimLeft(verb, "-#+0,(.0123456789")
+		}
+		args = append(args, printfArg{index: index, verb: verb})
+		return replace(index, verb)
+	})
+	return res, args
+}
+
+// argName is a name of imported argument which has no name in native file.
+func argName(index int) string {
+	return fmt.Sprintf("arg%d", index)
+}
+
+// placeholderType returns arb placeholder type of printf verb.
+func placeholderType(verb string) string {
+	switch strings.TrimLeft(verb, "hlqztjL") {
+	case "d", "D", "i", "u", "U", "x", "X", "o", "O", "c", "C":
+		return typeInt
+	case "f", "F", "e", "E", "g", "G", "a", "A":
+		return typeDouble
+	}
+	return typeString
+}
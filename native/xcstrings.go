@@ -0,0 +1,364 @@
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+// States of xcstrings string units.
+const (
+	xcStateNew         = "new"
+	xcStateTranslated  = "translated"
+	xcStateNeedsReview = "needs_review"
+
+	xcVersion         = "1.0"
+	xcExtractionState = "manual"
+	xcPluralCount     = "count"
+)
+
+var xcVerbs = verbs{integer: "lld", float: "f", object: "@"}
+
+type xcCatalog struct {
+	SourceLanguage string               `json:"sourceLanguage"`
+	Strings        map[string]*xcString `json:"strings"`
+	Version        string               `json:"version"`
+}
+
+type xcString struct {
+	Comment         string                     `json:"comment,omitempty"`
+	ExtractionState string                     `json:"extractionState,omitempty"`
+	Localizations   map[string]*xcLocalization `json:"localizations,omitempty"`
+	ShouldTranslate *bool                      `json:"shouldTranslate,omitempty"`
+}
+
+type xcLocalization struct {
+	StringUnit    *xcStringUnit              `json:"stringUnit,omitempty"`
+	Substitutions map[string]*xcSubstitution `json:"substitutions,omitempty"`
+	Variations    *xcVariations              `json:"variations,omitempty"`
+}
+
+type xcStringUnit struct {
+	State string `json:"state"`
+	Value string `json:"value"`
+}
+
+type xcSubstitution struct {
+	ArgNum          int           `json:"argNum"`
+	FormatSpecifier string        `json:"formatSpecifier"`
+	Variations      *xcVariations `json:"variations"`
+}
+
+type xcVariations struct {
+	Plural map[string]*xcLocalization `json:"plural,omitempty"`
+}
+
+// SaveXcstrings writes Xcode string catalog with all cultures, default culture is a source language.
+// Descriptions become comments, messages with single plural argument become plural variations,
+// messages with several plural arguments use substitutions. Fuzzy translations have needs_review state.
+func SaveXcstrings(logger *logrus.Logger, filePath string, params Params, arbData *arb.Data) error {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
+	}
+
+	catalog := &xcCatalog{
+		SourceLanguage: defaultCulture,
+		Strings:        make(map[string]*xcString),
+		Version:        xcVersion,
+	}
+	keys := arbData.OrderedKeys(params.Order)
+	for _, cn := range arbData.Cultures {
+		cultureKeys, messages, err := cultureKeys(logger, arbData, keys, cn)
+		if err != nil {
+			return err
+		}
+		for _, key := range cultureKeys {
+			item := arbData.Items[key]
+			s, ok := catalog.Strings[key]
+			if !ok {
+				s = &xcString{
+					Comment:         item.Description,
+					ExtractionState: xcExtractionState,
+					Localizations:   make(map[string]*xcLocalization),
+				}
+				catalog.Strings[key] = s
+			}
+			s.Localizations[cn] = xcLocalizationOf(logger, key, item, messages[key], cn, defaultCulture)
+		}
+	}
+	// keys without messages are kept to be translated
+	for _, key := range keys {
+		if _, ok := catalog.Strings[key]; !ok {
+			catalog.Strings[key] = &xcString{Comment: arbData.Items[key].Description, ExtractionState: xcExtractionState}
+		}
+	}
+
+	rawData, err := json.Marshal(catalog)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+		return err
+	}
+	return arb.WriteFileIfChanged(logger, filePath, xcodeJSON(rawData))
+}
+
+func xcLocalizationOf(logger *logrus.Logger, key string, item *arb.Item, m icu.Message, culture, defaultCulture string) *xcLocalization {
+	state := xcStateTranslated
+	switch st := item.States[culture]; st {
+	case "":
+	case arb.StateFuzzy, arb.StateMachine:
+		state = xcStateNeedsReview
+	default:
+		state = st
+	}
+
+	w := newPrintfWriter(xcVerbs, item.Parameters, positionalArgs(item, defaultCulture))
+	w.escapePercent = hasArguments(m)
+	unit := func(value string) *xcLocalization {
+		return &xcLocalization{StringUnit: &xcStringUnit{State: state, Value: value}}
+	}
+
+	if prefix, p, suffix := singlePlural(m); p != nil {
+		l := &xcLocalization{Variations: &xcVariations{Plural: make(map[string]*xcLocalization)}}
+		for _, b := range flatPlural(logger, key, w, prefix, p, suffix, true) {
+			l.Variations.Plural[b.category] = unit(b.value)
+		}
+		return l
+	}
+
+	if plurals := topPlurals(m); len(plurals) > 0 {
+		format, plurals, branches := variableFormat(logger, key, w, m)
+		l := unit(format)
+		l.Substitutions = make(map[string]*xcSubstitution)
+		for _, p := range plurals {
+			sub := &xcSubstitution{
+				ArgNum:          w.index(p.Name),
+				FormatSpecifier: xcVerbs.integer,
+				Variations:      &xcVariations{Plural: make(map[string]*xcLocalization)},
+			}
+			for _, b := range branches[p.Name] {
+				sub.Variations.Plural[b.category] = unit(b.value)
+			}
+			l.Substitutions[p.Name] = sub
+		}
+		return l
+	}
+
+	s, err := w.write(m, "")
+	if err != nil {
+		logger.Warningf("key %s: %v, message is written as text", key, err)
+		s = m.String()
+	}
+	return unit(s)
+}
+
+// xcodeJSON formats compact json like Xcode does: two spaces indent and " : " separator, so catalogs edited
+// by Xcode and written by arbc have small diffs.
+func xcodeJSON(compact []byte) []byte {
+	buf := &bytes.Buffer{}
+	depth := 0
+	inString, escaped := false, false
+	newLine := func() {
+		buf.WriteByte('\n')
+		for i := 0; i < depth; i++ {
+			buf.WriteString("  ")
+		}
+	}
+
+	for i := 0; i < len(compact); i++ {
+		c := compact[i]
+		if inString {
+			buf.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+			buf.WriteByte(c)
+		case '{', '[':
+			buf.WriteByte(c)
+			if i+1 < len(compact) && (compact[i+1] == '}' || compact[i+1] == ']') {
+				i++
+				buf.WriteByte(compact[i])
+				continue
+			}
+			depth++
+			newLine()
+		case '}', ']':
+			depth--
+			newLine()
+			buf.WriteByte(c)
+		case ',':
+			buf.WriteByte(c)
+			newLine()
+		case ':':
+			buf.WriteString(" : ")
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// LoadXcstrings reads Xcode string catalog. Source language of catalog is used as default culture,
+// key is used as message of source language if catalog has no its localization.
+// Arguments are named by position (arg1, arg2), plural variations use "count" argument.
+func LoadXcstrings(logger *logrus.Logger, filePath string, params Params) (*arb.Data, error) {
+	logger.Tracef("load xcstrings file %s", filePath)
+	rawData, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog xcCatalog
+	if err := json.Unmarshal(rawData, &catalog); err != nil {
+		return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidXcstrings)
+	}
+
+	sourceLanguage := catalog.SourceLanguage
+	if sourceLanguage == "" {
+		sourceLanguage = params.DefaultCulture
+	}
+	defaultCulture, err := locale.Canonical(sourceLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("%s, source language (%v): %w", filePath, err, ErrInvalidXcstrings)
+	}
+
+	arbData := &arb.Data{Cultures: []string{defaultCulture}, Items: make(map[string]*arb.Item)}
+	keys := make([]string, 0, len(catalog.Strings))
+	for key := range catalog.Strings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := catalog.Strings[key]
+		cultures := make([]string, 0, len(s.Localizations))
+		byCulture := make(map[string]*xcLocalization, len(s.Localizations))
+		for c, l := range s.Localizations {
+			culture, err := locale.Canonical(c)
+			if err != nil {
+				return nil, fmt.Errorf("%s, key %s (%v): %w", filePath, key, err, ErrInvalidXcstrings)
+			}
+			cultures = append(cultures, culture)
+			byCulture[culture] = l
+		}
+		// default culture goes first to declare placeholders
+		sort.Slice(cultures, func(i, j int) bool {
+			if cultures[i] == defaultCulture || cultures[j] == defaultCulture {
+				return cultures[i] == defaultCulture && cultures[j] != defaultCulture
+			}
+			return cultures[i] < cultures[j]
+		})
+		if _, ok := byCulture[defaultCulture]; !ok {
+			if err := importedItem(arbData, key, defaultCulture, defaultCulture, key, nil); err != nil {
+				return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidXcstrings)
+			}
+		}
+
+		for _, culture := range cultures {
+			l := byCulture[culture]
+			types := make(map[string]string)
+			msg, state := xcMessage(l, types)
+			if err := importedItem(arbData, key, culture, defaultCulture, msg, types); err != nil {
+				return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidXcstrings)
+			}
+			addCulture(arbData, culture)
+
+			item := arbData.Items[key]
+			switch state {
+			case xcStateNeedsReview:
+				item.SetState(culture, arb.StateFuzzy)
+			case "", xcStateTranslated, xcStateNew:
+			default:
+				item.SetState(culture, state)
+			}
+		}
+
+		if item, ok := arbData.Items[key]; ok {
+			item.Description = s.Comment
+		} else {
+			arbData.Items[key] = &arb.Item{Description: s.Comment, Cultures: make(map[string]string)}
+			arbData.Keys = append(arbData.Keys, key)
+		}
+	}
+
+	arbData.Cultures = arbData.OrderedCultures(defaultCulture)
+	return arbData, nil
+}
+
+// xcMessage converts localization to icu message and returns state of its string unit.
+func xcMessage(l *xcLocalization, types map[string]string) (string, string) {
+	if l.Variations != nil && len(l.Variations.Plural) > 0 {
+		branches, state := xcBranches(l.Variations)
+		// plural argument is the first integer argument of branches
+		index := 0
+		for _, b := range branches {
+			_, args := parsePrintf(b.value, func(int, string) string { return "" })
+			for _, a := range args {
+				if index == 0 && placeholderType(a.verb) == typeInt {
+					index = a.index
+				}
+			}
+		}
+		name := func(i int) string {
+			if i == index {
+				return xcPluralCount
+			}
+			return argName(i)
+		}
+		types[xcPluralCount] = typeInt
+		return pluralArgument(xcPluralCount, index, branches, name, types), state
+	}
+
+	if l.StringUnit == nil {
+		return "", ""
+	}
+	msg := formatWithVariables(l.StringUnit.Value, types, func(variable string) []pluralBranch {
+		sub := l.Substitutions[variable]
+		if sub == nil || sub.Variations == nil {
+			return nil
+		}
+		branches, _ := xcBranches(sub.Variations)
+		return branches
+	})
+	return msg, l.StringUnit.State
+}
+
+// xcBranches returns plural branches in CLDR order and state of branches, any state except translated wins.
+func xcBranches(v *xcVariations) ([]pluralBranch, string) {
+	var branches []pluralBranch
+	state := ""
+	for _, c := range []string{locale.PluralZero, locale.PluralOne, locale.PluralTwo, locale.PluralFew, locale.PluralMany, locale.PluralOther} {
+		l, ok := v.Plural[c]
+		if !ok || l.StringUnit == nil {
+			continue
+		}
+		branches = append(branches, pluralBranch{category: c, value: l.StringUnit.Value})
+		if state == "" || state == xcStateTranslated {
+			state = l.StringUnit.State
+		}
+	}
+	return branches, state
+}
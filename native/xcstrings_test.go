@@ -0,0 +1,99 @@
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXcodeJSON(t *testing.T) {
+	require.Equal(t, `{
+  "a" : "x:{,}\"",
+  "b" : {},
+  "c" : [
+    1,
+    2
+  ]
+}
+`, string(xcodeJSON([]byte(`{"a":"x:{,}\"","b":{},"c":[1,2]}`))))
+}
+
+func TestSaveAndLoadXcstrings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xcstrings")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	arbData := createData()
+	arbData.Items["items"].SetState("ru", arb.StateFuzzy)
+	arbData.Items["files"] = &arb.Item{Cultures: map[string]string{
+		"en": "{files, plural, one{# file} other{# files}} in {folders, plural, one{# folder} other{# folders}}",
+	}}
+	arbData.Keys = append(arbData.Keys, "files")
+
+	filePath := path.Join(dir, "Localizable.xcstrings")
+	params := Params{DefaultCulture: "en"}
+	require.NoError(t, SaveXcstrings(createLogger(), filePath, params, arbData))
+
+	raw, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	catalog := string(raw)
+	require.Contains(t, catalog, `  "sourceLanguage" : "en",`)
+	require.Contains(t, catalog, `"comment" : "Greeting -- on main screen",`)
+	require.Contains(t, catalog, `"value" : "Hello, %1$@! It's %2$lld%% done"`)
+	require.Contains(t, catalog, `"value" : "%1$#@files@ in %2$#@folders@"`)
+	require.Contains(t, catalog, `"state" : "needs_review",`)
+
+	loaded, err := LoadXcstrings(createLogger(), filePath, params)
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "pt-BR", "ru"}, loaded.Cultures)
+	require.Equal(t, []string{"files", "gender", "hello", "items", "plain"}, loaded.Keys)
+	require.Equal(t, "Greeting -- on main screen", loaded.Items["hello"].Description)
+	require.Equal(t, map[string]string{
+		"en":    "Hello, {arg1}! It's {arg2}% done",
+		"ru":    "Привет, {arg1}! Готово на {arg2}%",
+		"pt-BR": "Olá, {arg1}! \"{arg2}%\"",
+	}, loaded.Items["hello"].Cultures)
+	require.Equal(t, map[string]string{
+		"en": "{count, plural, zero{Found no items in {arg2}} one{Found # item in {arg2}} other{Found # items in {arg2}}}",
+		"ru": "{count, plural, one{Найдено # предмет в {arg2}} few{Найдено # предмета в {arg2}} many{Найдено # предметов в {arg2}} other{Найдено # предмета в {arg2}}}",
+	}, loaded.Items["items"].Cultures)
+	require.Equal(t, map[string]string{"ru": arb.StateFuzzy}, loaded.Items["items"].States)
+	require.Equal(t, "{files, plural, one{# file} other{# files}} in {folders, plural, one{# folder} other{# folders}}",
+		loaded.Items["files"].Cultures["en"])
+	require.Equal(t, "int", loaded.Items["files"].Parameters["folders"].Type)
+	require.Equal(t, "@home 100%", loaded.Items["plain"].Cultures["en"])
+}
+
+func TestLoadXcstringsWithoutSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xcstrings")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := path.Join(dir, "Localizable.xcstrings")
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`{
+  "sourceLanguage" : "en",
+  "strings" : {
+    "Done" : {
+      "localizations" : {
+        "de" : { "stringUnit" : { "state" : "translated", "value" : "Fertig" } }
+      }
+    },
+    "Settings" : {}
+  },
+  "version" : "1.0"
+}`), 0644))
+
+	loaded, err := LoadXcstrings(createLogger(), filePath, Params{DefaultCulture: "en"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "de"}, loaded.Cultures)
+	require.Equal(t, map[string]string{"en": "Done", "de": "Fertig"}, loaded.Items["Done"].Cultures)
+	require.Equal(t, map[string]string{"en": "Settings"}, loaded.Items["Settings"].Cultures)
+
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`{"strings":`), 0644))
+	_, err = LoadXcstrings(createLogger(), filePath, Params{DefaultCulture: "en"})
+	require.ErrorIs(t, err, ErrInvalidXcstrings)
+}
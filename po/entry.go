@@ -0,0 +1,236 @@
+package po
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// entry is a message of po file.
+type entry struct {
+	// line is a line number of first line of entry in source file
+	line int
+
+	translatorComments []string
+	extractedComments  []string
+	references         []string
+	flags              []string
+
+	hasContext  bool
+	msgctxt     string
+	msgid       string
+	msgidPlural string
+	hasPlural   bool
+	msgstr      []string
+}
+
+func (e *entry) hasFlag(flag string) bool {
+	for _, f := range e.flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// flagValue returns value of flag with name like "icu-plural:count".
+func (e *entry) flagValue(name string) string {
+	for _, f := range e.flags {
+		if strings.HasPrefix(f, name+":") {
+			return f[len(name)+1:]
+		}
+	}
+	return ""
+}
+
+// header returns value of header field from msgstr of header entry (msgid "").
+func (e *entry) header(name string) string {
+	if len(e.msgstr) == 0 {
+		return ""
+	}
+	for _, l := range strings.Split(e.msgstr[0], "\n") {
+		if i := strings.Index(l, ":"); i > 0 && strings.EqualFold(strings.TrimSpace(l[:i]), name) {
+			return strings.TrimSpace(l[i+1:])
+		}
+	}
+	return ""
+}
+
+func writeEntry(w io.Writer, e *entry) error {
+	sb := &strings.Builder{}
+	for _, c := range e.translatorComments {
+		writeComment(sb, "#", c)
+	}
+	for _, c := range e.extractedComments {
+		writeComment(sb, "#.", c)
+	}
+	for _, r := range e.references {
+		sb.WriteString("#: " + r + "\n")
+	}
+	if len(e.flags) > 0 {
+		sb.WriteString("#, " + strings.Join(e.flags, ", ") + "\n")
+	}
+	if e.hasContext {
+		writeString(sb, "msgctxt", e.msgctxt)
+	}
+	writeString(sb, "msgid", e.msgid)
+	if e.hasPlural {
+		writeString(sb, "msgid_plural", e.msgidPlural)
+		for i, s := range e.msgstr {
+			writeString(sb, fmt.Sprintf("msgstr[%d]", i), s)
+		}
+	} else {
+		s := ""
+		if len(e.msgstr) > 0 {
+			s = e.msgstr[0]
+		}
+		writeString(sb, "msgstr", s)
+	}
+	sb.WriteString("\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeComment(sb *strings.Builder, prefix, comment string) {
+	for _, l := range strings.Split(comment, "\n") {
+		if l == "" {
+			sb.WriteString(prefix + "\n")
+			continue
+		}
+		sb.WriteString(prefix + " " + l + "\n")
+	}
+}
+
+// writeString writes keyword with quoted string, strings with line breaks are split to several lines.
+func writeString(sb *strings.Builder, keyword, s string) {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		sb.WriteString(keyword + " " + quote(s) + "\n")
+		return
+	}
+	sb.WriteString(keyword + " \"\"\n")
+	for _, l := range strings.SplitAfter(s, "\n") {
+		if l != "" {
+			sb.WriteString(quote(l) + "\n")
+		}
+	}
+}
+
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func unquote(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("quoted string expected")
+	}
+	return strconv.Unquote(s)
+}
+
+// parseEntries reads entries of po file, obsolete entries (#~) are skipped.
+func parseEntries(r io.Reader) ([]*entry, error) {
+	var entries []*entry
+	var e *entry
+	// field points to the last string field to append continuation lines
+	var field *string
+
+	flush := func() {
+		if e != nil {
+			entries = append(entries, e)
+		}
+		e, field = nil, nil
+	}
+	current := func(line int) *entry {
+		if e == nil {
+			e = &entry{line: line}
+		}
+		return e
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		l := strings.TrimSpace(sc.Text())
+		if line == 1 {
+			l = strings.TrimPrefix(l, "\uFEFF")
+		}
+
+		switch {
+		case l == "":
+			flush()
+		case strings.HasPrefix(l, "#~"), strings.HasPrefix(l, "#|"):
+			// obsolete entries and previous strings are not used
+		case strings.HasPrefix(l, "#."):
+			current(line).extractedComments = append(e.extractedComments, strings.TrimSpace(l[2:]))
+		case strings.HasPrefix(l, "#:"):
+			current(line).references = append(e.references, strings.Fields(l[2:])...)
+		case strings.HasPrefix(l, "#,"):
+			for _, f := range strings.Split(l[2:], ",") {
+				if f = strings.TrimSpace(f); f != "" {
+					current(line).flags = append(e.flags, f)
+				}
+			}
+		case strings.HasPrefix(l, "#"):
+			current(line).translatorComments = append(e.translatorComments, strings.TrimSpace(l[1:]))
+		case strings.HasPrefix(l, `"`):
+			if field == nil {
+				return nil, fmt.Errorf("line %d: unexpected string", line)
+			}
+			s, err := unquote(l)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			*field += s
+		default:
+			keyword, rest := l, ""
+			if i := strings.IndexAny(l, " \t"); i > 0 {
+				keyword, rest = l[:i], l[i+1:]
+			}
+			s, err := unquote(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+
+			if e != nil && (keyword == "msgctxt" || keyword == "msgid") && len(e.msgstr) > 0 {
+				// next entry without blank line
+				flush()
+			}
+			cur := current(line)
+			switch {
+			case keyword == "msgctxt":
+				cur.hasContext, cur.msgctxt = true, s
+				field = &cur.msgctxt
+			case keyword == "msgid":
+				cur.msgid = s
+				field = &cur.msgid
+			case keyword == "msgid_plural":
+				cur.hasPlural, cur.msgidPlural = true, s
+				field = &cur.msgidPlural
+			case keyword == "msgstr" || strings.HasPrefix(keyword, "msgstr["):
+				i := 0
+				if keyword != "msgstr" {
+					if i, err = strconv.Atoi(strings.TrimSuffix(keyword[len("msgstr["):], "]")); err != nil || i < 0 {
+						return nil, fmt.Errorf("line %d: invalid keyword %s", line, keyword)
+					}
+				}
+				for len(cur.msgstr) <= i {
+					cur.msgstr = append(cur.msgstr, "")
+				}
+				cur.msgstr[i] = s
+				field = &cur.msgstr[i]
+			default:
+				return nil, fmt.Errorf("line %d: unknown keyword %s", line, keyword)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return entries, nil
+}
@@ -0,0 +1,193 @@
+package po
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+// LoadPo reads po files matching template from folder (and pot file if it exists) and creates arb data.
+// Culture of file is taken from Language header or from file name, msgid is used as message of default culture.
+func LoadPo(logger *logrus.Logger, params Params) (*arb.Data, error) {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return nil, fmt.Errorf("default culture: %w", err)
+	}
+
+	pattern := filepath.Join(params.FolderPath, filepath.FromSlash(strings.ReplaceAll(params.FileTemplate, cultureTemplate, "*")))
+	filePaths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(filePaths) == 0 {
+		return nil, fmt.Errorf("no files match %s: %w", pattern, ErrInvalidPo)
+	}
+
+	arbData := &arb.Data{
+		Cultures: []string{defaultCulture},
+		Items:    make(map[string]*arb.Item),
+	}
+	l := &loader{logger: logger, data: arbData, defaultCulture: defaultCulture, files: make(map[string]string)}
+
+	if params.PotFile != "" {
+		potPath := filepath.Join(params.FolderPath, params.PotFile)
+		if _, err := os.Stat(potPath); err == nil {
+			if err := l.loadFile(potPath, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, filePath := range filePaths {
+		if err := l.loadFile(filePath, cultureFromPath(params, filePath)); err != nil {
+			return nil, err
+		}
+	}
+
+	arbData.Cultures = arbData.OrderedCultures(defaultCulture)
+	return arbData, nil
+}
+
+type loader struct {
+	logger         *logrus.Logger
+	data           *arb.Data
+	defaultCulture string
+	// files keeps file of every loaded culture
+	files map[string]string
+}
+
+// loadFile reads po file, empty culture means pot file: only messages of default culture are read.
+func (l *loader) loadFile(filePath, fileCulture string) error {
+	l.logger.Tracef("load po file %s", filePath)
+	rawData, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	entries, err := parseEntries(bytes.NewReader(rawData))
+	if err != nil {
+		return fmt.Errorf("%s, %v: %w", filePath, err, ErrInvalidPo)
+	}
+
+	culture := ""
+	if len(entries) > 0 && !entries[0].hasContext && entries[0].msgid == "" {
+		if language := entries[0].header("Language"); language != "" {
+			fileCulture = language
+		}
+		entries = entries[1:]
+	}
+	if fileCulture != "" {
+		if culture, err = locale.Canonical(fileCulture); err != nil {
+			return fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidPo)
+		}
+		if f, ok := l.files[culture]; ok {
+			return fmt.Errorf("same cultures in [%s] and [%s]: %w", f, filePath, ErrInvalidPo)
+		}
+		l.files[culture] = filePath
+		if culture != l.defaultCulture {
+			l.data.Cultures = append(l.data.Cultures, culture)
+		}
+	}
+
+	for _, e := range entries {
+		if err := l.addEntry(e, culture); err != nil {
+			return fmt.Errorf("%s, line %d, %v: %w", filePath, e.line, err, ErrInvalidPo)
+		}
+	}
+	return nil
+}
+
+func (l *loader) addEntry(e *entry, culture string) error {
+	key := e.msgid
+	if e.hasContext {
+		key = e.msgctxt
+	}
+	if key == "" {
+		return fmt.Errorf("entry without msgctxt and msgid")
+	}
+
+	item, ok := l.data.Items[key]
+	if !ok {
+		item = &arb.Item{Cultures: make(map[string]string)}
+		l.data.Items[key] = item
+		l.data.Keys = append(l.data.Keys, key)
+	}
+	if item.Description == "" {
+		item.Description = strings.Join(e.extractedComments, "\n")
+	}
+
+	pluralName := e.flagValue(flagPlural)
+	if pluralName == "" {
+		pluralName = defaultPluralName
+	}
+
+	source := e.msgid
+	if e.hasPlural {
+		source = joinPlural(pluralName, []string{locale.PluralOne, locale.PluralOther}, []string{e.msgid, e.msgidPlural})
+	}
+	if _, ok := item.Cultures[l.defaultCulture]; !ok {
+		if err := icu.Validate(source); err != nil {
+			return fmt.Errorf("invalid message %s for culture %s (%v)", key, l.defaultCulture, err)
+		}
+		item.Cultures[l.defaultCulture] = source
+	}
+	if culture == "" || (culture == l.defaultCulture && isEmpty(e.msgstr)) {
+		return nil
+	}
+
+	target := ""
+	if len(e.msgstr) > 0 {
+		target = e.msgstr[0]
+	}
+	if e.hasPlural {
+		target = ""
+		if !isEmpty(e.msgstr) {
+			categories := pluralCategories(culture)
+			if len(e.msgstr) != len(categories) {
+				return fmt.Errorf("key %s has %d plural forms, %d expected for culture %s", key, len(e.msgstr), len(categories), culture)
+			}
+			target = joinPlural(pluralName, categories, e.msgstr)
+		}
+	}
+	if err := icu.Validate(target); err != nil {
+		return fmt.Errorf("invalid message %s for culture %s (%v)", key, culture, err)
+	}
+
+	item.Cultures[culture] = target
+	if target != "" && e.hasFlag(flagFuzzy) {
+		item.SetState(culture, arb.StateFuzzy)
+	}
+	return nil
+}
+
+// cultureFromPath returns culture part of file path created by template or empty string.
+func cultureFromPath(params Params, filePath string) string {
+	rel, err := filepath.Rel(params.FolderPath, filePath)
+	if err != nil {
+		return ""
+	}
+	parts := strings.SplitN(params.FileTemplate, cultureTemplate, 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, parts[0]) || !strings.HasSuffix(rel, parts[1]) || len(rel) < len(parts[0])+len(parts[1]) {
+		return ""
+	}
+	return rel[len(parts[0]) : len(rel)-len(parts[1])]
+}
+
+func isEmpty(msgstr []string) bool {
+	for _, s := range msgstr {
+		if s != "" {
+			return false
+		}
+	}
+	return true
+}
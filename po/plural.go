@@ -0,0 +1,146 @@
+package po
+
+import (
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+)
+
+// pluralOrder is an order of CLDR plural categories in gettext plural forms.
+var pluralOrder = []string{
+	locale.PluralZero,
+	locale.PluralOne,
+	locale.PluralTwo,
+	locale.PluralFew,
+	locale.PluralMany,
+	locale.PluralOther,
+}
+
+// pluralCategories returns CLDR plural categories used by integer numbers of culture in gettext order,
+// msgstr[n] of po file keeps translation for n-th category.
+func pluralCategories(culture string) []string {
+	used := make(map[string]struct{})
+	for n := 0; n <= 1000; n++ {
+		o := locale.Operands{N: float64(n), I: int64(n)}
+		used[locale.CardinalCategory(culture, o)] = struct{}{}
+	}
+	var categories []string
+	for _, c := range pluralOrder {
+		if _, ok := used[c]; ok {
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
+
+// pluralForms returns value of Plural-Forms header, expressions give index of pluralCategories.
+func pluralForms(culture string) string {
+	t, err := locale.Parse(culture)
+	if err != nil {
+		return pluralFormsOther
+	}
+	if t.Language == "pt" && t.Region == "PT" {
+		return pluralFormsNotOne
+	}
+	if f, ok := pluralFormsByLanguage[t.Language]; ok {
+		return f
+	}
+	if len(pluralCategories(culture)) == 2 {
+		return pluralFormsNotOne
+	}
+	return pluralFormsOther
+}
+
+const (
+	pluralFormsOther  = "nplurals=1; plural=0;"
+	pluralFormsNotOne = "nplurals=2; plural=(n != 1);"
+)
+
+var pluralFormsByLanguage = make(map[string]string)
+
+func init() {
+	add := func(languages, forms string) {
+		for _, l := range strings.Fields(languages) {
+			pluralFormsByLanguage[l] = forms
+		}
+	}
+	add("am as bn doi fa gu hi kn pcm zu ff fr hy kab pt ak bho guw ln mg nso pa ti wa si", "nplurals=2; plural=(n > 1);")
+	add("da", pluralFormsNotOne)
+	add("is", "nplurals=2; plural=(n%10 != 1 || n%100 == 11);")
+	add("mk", "nplurals=2; plural=(n%10 == 1 && n%100 != 11 ? 0 : 1);")
+	add("fil tl", "nplurals=2; plural=(n%10 == 4 || n%10 == 6 || n%10 == 9);")
+	add("lv prg", "nplurals=3; plural=(n%10 == 0 || (n%100 >= 11 && n%100 <= 19) ? 0 : n%10 == 1 && n%100 != 11 ? 1 : 2);")
+	add("ru uk be bs hr sh sr", "nplurals=3; plural=(n%10 == 1 && n%100 != 11 ? 0 : n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) ? 1 : 2);")
+	add("pl", "nplurals=3; plural=(n == 1 ? 0 : n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) ? 1 : 2);")
+	add("cs sk", "nplurals=3; plural=(n == 1 ? 0 : n >= 2 && n <= 4 ? 1 : 2);")
+	add("lt", "nplurals=3; plural=(n%10 == 1 && (n%100 < 11 || n%100 > 19) ? 0 : n%10 >= 2 && (n%100 < 11 || n%100 > 19) ? 1 : 2);")
+	add("sl", "nplurals=4; plural=(n%100 == 1 ? 0 : n%100 == 2 ? 1 : n%100 == 3 || n%100 == 4 ? 2 : 3);")
+	add("mo ro", "nplurals=3; plural=(n == 1 ? 0 : n == 0 || (n%100 >= 1 && n%100 <= 19) ? 1 : 2);")
+	add("ar ars", "nplurals=6; plural=(n == 0 ? 0 : n == 1 ? 1 : n == 2 ? 2 : n%100 >= 3 && n%100 <= 10 ? 3 : n%100 >= 11 ? 4 : 5);")
+	add("he iw", "nplurals=3; plural=(n == 1 ? 0 : n == 2 ? 1 : 2);")
+	add("ga", "nplurals=5; plural=(n == 1 ? 0 : n == 2 ? 1 : n >= 3 && n <= 6 ? 2 : n >= 7 && n <= 10 ? 3 : 4);")
+	add("gd", "nplurals=4; plural=(n == 1 || n == 11 ? 0 : n == 2 || n == 12 ? 1 : n >= 3 && n <= 19 ? 2 : 3);")
+	add("cy", "nplurals=6; plural=(n == 0 ? 0 : n == 1 ? 1 : n == 2 ? 2 : n == 3 ? 3 : n == 6 ? 4 : 5);")
+	add("mt", "nplurals=5; plural=(n == 1 ? 0 : n == 2 ? 1 : n == 0 || (n%100 >= 3 && n%100 <= 10) ? 2 : n%100 >= 11 && n%100 <= 19 ? 3 : 4);")
+}
+
+// simplePlural returns plural argument if message consists of one cardinal plural argument
+// without offset and exact selectors, only such messages are written as msgid_plural entries.
+func simplePlural(msg string) *icu.Plural {
+	m, err := icu.Parse(strings.TrimSpace(msg))
+	if err != nil || len(m) != 1 {
+		return nil
+	}
+	p, ok := m[0].(*icu.Plural)
+	if !ok || p.Ordinal || p.PluralOffset != 0 {
+		return nil
+	}
+	for _, o := range p.Options {
+		if strings.HasPrefix(o.Selector, "=") {
+			return nil
+		}
+	}
+	return p
+}
+
+// pluralBranches returns text of plural branches for categories, missing categories use "other" branch.
+func pluralBranches(p *icu.Plural, categories []string) []string {
+	branches := make(map[string]string, len(p.Options))
+	for _, o := range p.Options {
+		branches[o.Selector] = o.Value.String()
+	}
+	res := make([]string, len(categories))
+	for i, c := range categories {
+		v, ok := branches[c]
+		if !ok {
+			v = branches[icu.OtherSelector]
+		}
+		res[i] = v
+	}
+	return res
+}
+
+// joinPlural builds plural message from branches of categories, "other" branch is added if categories have no it.
+func joinPlural(name string, categories, branches []string) string {
+	sb := &strings.Builder{}
+	sb.WriteString("{" + name + ", " + icu.TypePlural + ",")
+	hasOther := false
+	for i, c := range categories {
+		if i >= len(branches) {
+			break
+		}
+		sb.WriteString(" " + c + "{" + branches[i] + "}")
+		hasOther = hasOther || c == icu.OtherSelector
+	}
+	if !hasOther && len(branches) > 0 {
+		// gettext has no form for fractions ("other" of ru, pl), the last form is used
+		last := len(categories) - 1
+		if last >= len(branches) {
+			last = len(branches) - 1
+		}
+		sb.WriteString(" " + icu.OtherSelector + "{" + branches[last] + "}")
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
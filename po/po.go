@@ -0,0 +1,170 @@
+// Package po converts arb data to GNU gettext .pot/.po files and back.
+// Keys are written to msgctxt, descriptions to extracted comments (#.), simple ICU plurals
+// to msgid_plural/msgstr[n] entries with plural forms of culture and fuzzy state to "fuzzy" flag.
+package po
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cultureTemplate = "{culture}"
+
+	flagFuzzy = "fuzzy"
+	// flagPlural keeps name of plural argument: "icu-plural:count"
+	flagPlural = "icu-plural"
+
+	defaultPluralName = "count"
+
+	// potPluralForms is a plural forms placeholder of pot file, it is replaced by translators
+	potPluralForms = "nplurals=INTEGER; plural=EXPRESSION;"
+)
+
+var (
+	ErrInvalidPo = errors.New("invalid po")
+)
+
+type Params struct {
+	FolderPath string
+	// FileTemplate is a template of po files names: {culture}.po
+	FileTemplate string
+	// PotFile is a name of template file, empty name - pot file is not written
+	PotFile        string
+	DefaultCulture string
+	Order          arb.Order
+	LocaleStyle    locale.Style
+}
+
+// SavePot writes template file with messages of default culture and empty translations.
+func SavePot(logger *logrus.Logger, params Params, arbData *arb.Data) error {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
+	}
+
+	entries := []*entry{headerEntry("", potPluralForms)}
+	for _, key := range arbData.OrderedKeys(params.Order) {
+		item := arbData.Items[key]
+		source := item.Cultures[defaultCulture]
+		if source == "" {
+			logger.Debugf("skip key %s with empty source", key)
+			continue
+		}
+
+		e := newEntry(key, item)
+		if p := simplePlural(source); p != nil {
+			branches := pluralBranches(p, []string{locale.PluralOne, locale.PluralOther})
+			e.flags = append(e.flags, flagPlural+":"+p.Name)
+			e.hasPlural, e.msgid, e.msgidPlural = true, branches[0], branches[1]
+			e.msgstr = []string{"", ""}
+		} else {
+			e.msgid = source
+		}
+		entries = append(entries, e)
+	}
+	return writeFile(logger, filepath.Join(params.FolderPath, params.PotFile), entries)
+}
+
+// SavePo writes one po file per culture except default one, messages of default culture are used as msgid.
+func SavePo(logger *logrus.Logger, params Params, arbData *arb.Data) error {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
+	}
+
+	keys := arbData.OrderedKeys(params.Order)
+	for _, cn := range arbData.Cultures {
+		if cn == defaultCulture {
+			continue
+		}
+
+		entries := []*entry{headerEntry(locale.Format(cn, params.LocaleStyle), pluralForms(cn))}
+		for _, key := range keys {
+			item := arbData.Items[key]
+			source := item.Cultures[defaultCulture]
+			if source == "" {
+				logger.Debugf("skip key %s with empty source", key)
+				continue
+			}
+			entries = append(entries, cultureEntry(key, item, source, cn))
+		}
+
+		fileName := strings.ReplaceAll(params.FileTemplate, cultureTemplate, locale.Format(cn, params.LocaleStyle))
+		if err := writeFile(logger, filepath.Join(params.FolderPath, filepath.FromSlash(fileName)), entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newEntry(key string, item *arb.Item) *entry {
+	e := &entry{hasContext: true, msgctxt: key}
+	if item.Description != "" {
+		e.extractedComments = []string{item.Description}
+	}
+	return e
+}
+
+// cultureEntry creates entry of culture. Plural entry is created if both messages are simple plurals
+// (or translation is empty), otherwise messages are written as is.
+func cultureEntry(key string, item *arb.Item, source, culture string) *entry {
+	e := newEntry(key, item)
+	if st := item.States[culture]; st == arb.StateFuzzy || st == arb.StateMachine {
+		e.flags = append(e.flags, flagFuzzy)
+	}
+
+	target := item.Cultures[culture]
+	sourcePlural := simplePlural(source)
+	targetPlural := simplePlural(target)
+	if sourcePlural == nil || (target != "" && (targetPlural == nil || targetPlural.Name != sourcePlural.Name)) {
+		e.msgid = source
+		e.msgstr = []string{target}
+		return e
+	}
+
+	sourceBranches := pluralBranches(sourcePlural, []string{locale.PluralOne, locale.PluralOther})
+	e.flags = append(e.flags, flagPlural+":"+sourcePlural.Name)
+	e.hasPlural, e.msgid, e.msgidPlural = true, sourceBranches[0], sourceBranches[1]
+
+	categories := pluralCategories(culture)
+	if targetPlural == nil {
+		e.msgstr = make([]string, len(categories))
+		return e
+	}
+	e.msgstr = pluralBranches(targetPlural, categories)
+	return e
+}
+
+func headerEntry(language, forms string) *entry {
+	fields := []string{
+		"Language: " + language,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+		"Plural-Forms: " + forms,
+		"X-Generator: arbc",
+	}
+	return &entry{msgstr: []string{strings.Join(fields, "\n") + "\n"}}
+}
+
+func writeFile(logger *logrus.Logger, filePath string, entries []*entry) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	for _, e := range entries {
+		if err := writeEntry(buf, e); err != nil {
+			return err
+		}
+	}
+	return arb.WriteFileIfChanged(logger, filePath, bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,171 @@
+package po
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func createData() *arb.Data {
+	return &arb.Data{
+		Cultures: []string{"en", "pt-BR", "ru"},
+		Items: map[string]*arb.Item{
+			"hello": {
+				Description: "Greeting\nwith \"name\"",
+				Cultures:    map[string]string{"en": "Hello, {name}!", "ru": "Привет, {name}!", "pt-BR": "Olá, {name}!"},
+				States:      map[string]string{"ru": arb.StateFuzzy},
+			},
+			"items": {Cultures: map[string]string{
+				"en":    "{n, plural, one{# item} other{# items}}",
+				"ru":    "{n, plural, one{# предмет} few{# предмета} many{# предметов} other{# предмета}}",
+				"pt-BR": "",
+			}},
+			"gender": {Cultures: map[string]string{
+				"en":    "{g, select, male{He} other{They}}",
+				"ru":    "{g, select, male{Он} other{Они}}",
+				"pt-BR": "{g, select, male{Ele} other{Eles}}",
+			}},
+		},
+		Keys: []string{"hello", "items", "gender"},
+	}
+}
+
+func TestPluralCategories(t *testing.T) {
+	require.Equal(t, []string{"one", "other"}, pluralCategories("en"))
+	require.Equal(t, []string{"one", "few", "many"}, pluralCategories("ru"))
+	require.Equal(t, []string{"other"}, pluralCategories("ja"))
+	require.Equal(t, []string{"zero", "one", "two", "few", "many", "other"}, pluralCategories("ar"))
+	require.Equal(t, "nplurals=2; plural=(n > 1);", pluralForms("pt-BR"))
+	require.Equal(t, "nplurals=2; plural=(n != 1);", pluralForms("pt-PT"))
+	require.Equal(t, "nplurals=2; plural=(n != 1);", pluralForms("de"))
+	require.Equal(t, "nplurals=1; plural=0;", pluralForms("ja"))
+}
+
+func TestParseEntries(t *testing.T) {
+	entries, err := parseEntries(strings.NewReader(`# translator
+#. extracted
+#: main.c:10
+#, fuzzy, icu-plural:n
+msgctxt "key"
+msgid ""
+"multi\n"
+"line"
+msgid_plural "plural"
+msgstr[0] "a\t\"b\""
+msgstr[1] "c"
+#~ msgid "obsolete"
+#~ msgstr "old"
+msgid "next"
+msgstr "без пустой строки"
+`))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	e := entries[0]
+	require.Equal(t, 1, e.line)
+	require.Equal(t, []string{"translator"}, e.translatorComments)
+	require.Equal(t, []string{"extracted"}, e.extractedComments)
+	require.Equal(t, []string{"main.c:10"}, e.references)
+	require.True(t, e.hasFlag(flagFuzzy))
+	require.Equal(t, "n", e.flagValue(flagPlural))
+	require.Equal(t, "key", e.msgctxt)
+	require.Equal(t, "multi\nline", e.msgid)
+	require.Equal(t, []string{"a\t\"b\"", "c"}, e.msgstr)
+	require.Equal(t, &entry{line: 14, msgid: "next", msgstr: []string{"без пустой строки"}}, entries[1])
+
+	_, err = parseEntries(strings.NewReader("msgid \"a\"\nmsgstr b\n"))
+	require.Error(t, err)
+}
+
+func TestSaveAndLoadPo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "po")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	params := Params{
+		FolderPath:     dir,
+		FileTemplate:   "{culture}.po",
+		PotFile:        "messages.pot",
+		DefaultCulture: "en",
+	}
+	arbData := createData()
+	require.NoError(t, SavePot(createLogger(), params, arbData))
+	require.NoError(t, SavePo(createLogger(), params, arbData))
+	require.NoFileExists(t, path.Join(dir, "en.po"))
+
+	ru, err := ioutil.ReadFile(path.Join(dir, "ru.po"))
+	require.NoError(t, err)
+	require.Contains(t, string(ru), `"Language: ru\n"`)
+	require.Contains(t, string(ru), `#. Greeting
+#. with "name"
+#, fuzzy
+msgctxt "hello"
+msgid "Hello, {name}!"
+msgstr "Привет, {name}!"`)
+	require.Contains(t, string(ru), `#, icu-plural:n
+msgctxt "items"
+msgid "# item"
+msgid_plural "# items"
+msgstr[0] "# предмет"
+msgstr[1] "# предмета"
+msgstr[2] "# предметов"`)
+
+	pt, err := ioutil.ReadFile(path.Join(dir, "pt_BR.po"))
+	require.NoError(t, err)
+	require.Contains(t, string(pt), `"Plural-Forms: nplurals=2; plural=(n > 1);\n"`)
+	require.Contains(t, string(pt), `msgid_plural "# items"
+msgstr[0] ""
+msgstr[1] ""`)
+
+	pot, err := ioutil.ReadFile(path.Join(dir, "messages.pot"))
+	require.NoError(t, err)
+	require.Contains(t, string(pot), `msgctxt "gender"
+msgid "{g, select, male{He} other{They}}"
+msgstr ""`)
+
+	loaded, err := LoadPo(createLogger(), params)
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "pt-BR", "ru"}, loaded.Cultures)
+	require.Equal(t, []string{"hello", "items", "gender"}, loaded.Keys)
+	require.Equal(t, "Greeting\nwith \"name\"", loaded.Items["hello"].Description)
+	require.Equal(t, map[string]string{"ru": arb.StateFuzzy}, loaded.Items["hello"].States)
+	require.Equal(t, arbData.Items["gender"].Cultures, loaded.Items["gender"].Cultures)
+	require.Equal(t, map[string]string{
+		"en":    "{n, plural, one{# item} other{# items}}",
+		"ru":    "{n, plural, one{# предмет} few{# предмета} many{# предметов} other{# предметов}}",
+		"pt-BR": "",
+	}, loaded.Items["items"].Cultures)
+}
+
+func TestLoadPoErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "po")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	params := Params{FolderPath: dir, FileTemplate: "{culture}.po", DefaultCulture: "en"}
+	_, err = LoadPo(createLogger(), params)
+	require.ErrorIs(t, err, ErrInvalidPo)
+
+	require.NoError(t, ioutil.WriteFile(path.Join(dir, "ru.po"), []byte(`msgctxt "items"
+msgid "# item"
+msgid_plural "# items"
+msgstr[0] "# предмет"
+msgstr[1] "# предмета"
+`), 0644))
+	_, err = LoadPo(createLogger(), params)
+	require.ErrorIs(t, err, ErrInvalidPo)
+	require.Contains(t, err.Error(), "line 1")
+	require.Contains(t, err.Error(), "3 expected")
+}
+
+func createLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.TraceLevel)
+	return logger
+}
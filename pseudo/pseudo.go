@@ -0,0 +1,247 @@
+// Package pseudo generates pseudo localized cultures from default culture: accented (en-XA) and
+// right-to-left (ar-XB) texts with expanded length and bracket markers, which reveal truncated,
+// hard-coded and concatenated strings before real translations arrive.
+package pseudo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+// Style is a method of pseudo localization.
+type Style string
+
+const (
+	// StyleAccented replaces latin letters with accented ones: Settings -> Šéţţîñĝš.
+	StyleAccented Style = "accented"
+	// StyleBidi wraps every word in right-to-left override, so text is displayed mirrored in right-to-left layout.
+	StyleBidi Style = "bidi"
+)
+
+const (
+	DefaultAccented = "en-XA"
+	DefaultBidi     = "ar-XB"
+
+	// padding is appended to text to expand its length.
+	padding = '~'
+
+	// right-to-left mark, right-to-left override and pop directional formatting
+	rlm = "\u200f"
+	rlo = "\u202e"
+	pdf = "\u202c"
+)
+
+var (
+	ErrInvalidCulture = errors.New("invalid pseudo culture")
+)
+
+const (
+	plainLetters    = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	accentedLetters = "åƀçðéƒĝĥîĵķļɱñöþǫŕšţûṽŵẋýžÅƁÇÐÉƑĜĤÎĴĶĻṀÑÖÞǪŔŠŢÛṼŴẊÝŽ"
+)
+
+var accents = accentsMap()
+
+// markupRe matches markup tags and character entities which are kept as is.
+var markupRe = regexp.MustCompile(`<[^<>]*>|&#?\w+;`)
+
+// openTagRe matches tag which is not closed in text node, it continues after argument: <a href="{url}">.
+var openTagRe = regexp.MustCompile(`</?[a-zA-Z][^<>]*$`)
+
+var wordRe = regexp.MustCompile(`\S+`)
+
+// Params defines generated cultures, empty culture is not generated.
+type Params struct {
+	DefaultCulture string
+	Accented       string
+	Bidi           string
+	// Expansion is a ratio of added length of text: 0.3 makes text 30% longer.
+	Expansion float64
+	// Brackets wraps messages in [ ], so cut and concatenated messages are visible.
+	Brackets bool
+}
+
+// Generate adds pseudo cultures to arb data, messages are generated from default culture, existing
+// translations of pseudo cultures are replaced. Messages with invalid ICU syntax are copied as is.
+func Generate(logger *logrus.Logger, arbData *arb.Data, params Params) error {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
+	}
+
+	type pseudoCulture struct {
+		culture string
+		style   Style
+	}
+	var cultures []pseudoCulture
+	for _, pc := range []pseudoCulture{{params.Accented, StyleAccented}, {params.Bidi, StyleBidi}} {
+		if pc.culture == "" {
+			continue
+		}
+		c, err := locale.Canonical(pc.culture)
+		if err != nil {
+			return fmt.Errorf("%s (%v): %w", pc.culture, err, ErrInvalidCulture)
+		}
+		if c == defaultCulture {
+			return fmt.Errorf("%s is default culture: %w", c, ErrInvalidCulture)
+		}
+		cultures = append(cultures, pseudoCulture{c, pc.style})
+	}
+
+	for _, key := range arbData.OrderedKeys(arb.OrderSource) {
+		item := arbData.Items[key]
+		if item.Cultures == nil {
+			item.Cultures = make(map[string]string)
+		}
+		source := item.Cultures[defaultCulture]
+		for _, pc := range cultures {
+			msg, err := Localize(source, pc.style, params.Expansion, params.Brackets)
+			if err != nil {
+				logger.Warningf("key %s: %v, message is copied to %s as is", key, err, pc.culture)
+				msg = source
+			}
+			item.Cultures[pc.culture] = msg
+			item.SetState(pc.culture, "")
+		}
+	}
+
+	for _, pc := range cultures {
+		if !hasCulture(arbData.Cultures, pc.culture) {
+			arbData.Cultures = append(arbData.Cultures, pc.culture)
+		}
+	}
+	return nil
+}
+
+// Localize returns pseudo localized ICU message: text of message and of every plural and select branch
+// is transformed and expanded, arguments, ICU syntax and markup are kept as is. Empty message stays empty.
+func Localize(msg string, style Style, expansion float64, brackets bool) (string, error) {
+	if msg == "" {
+		return "", nil
+	}
+	m, err := icu.Parse(msg)
+	if err != nil {
+		return "", err
+	}
+	s := localizeMessage(m, style, expansion).String()
+	if brackets {
+		s = "[" + s + "]"
+	}
+	return s, nil
+}
+
+// localizeMessage transforms text nodes of message and appends padding for expansion of its text,
+// branches of plural and select arguments are expanded by own text.
+func localizeMessage(m icu.Message, style Style, expansion float64) icu.Message {
+	res := make(icu.Message, 0, len(m)+1)
+	length := 0
+	inTag := false
+	for _, n := range m {
+		switch n := n.(type) {
+		case *icu.Text:
+			var text string
+			var l int
+			text, l, inTag = localizeText(n.Value, style, inTag)
+			length += l
+			res = append(res, &icu.Text{Offset: n.Offset, Value: text})
+		case *icu.Plural:
+			p := *n
+			p.Options = localizeOptions(n.Options, style, expansion)
+			res = append(res, &p)
+		case *icu.Select:
+			s := *n
+			s.Options = localizeOptions(n.Options, style, expansion)
+			res = append(res, &s)
+		default:
+			res = append(res, n)
+		}
+	}
+	if pad := int(math.Ceil(float64(length) * expansion)); pad > 0 {
+		res = append(res, &icu.Text{Value: strings.Repeat(string(padding), pad)})
+	}
+	return res
+}
+
+func localizeOptions(options []*icu.Option, style Style, expansion float64) []*icu.Option {
+	res := make([]*icu.Option, len(options))
+	for i, o := range options {
+		res[i] = &icu.Option{Offset: o.Offset, Selector: o.Selector, Value: localizeMessage(o.Value, style, expansion)}
+	}
+	return res
+}
+
+// localizeText transforms text outside of markup and returns it with length of transformed text in runes.
+// Tags may contain arguments, so inTag tells that text starts inside of tag opened by previous text node,
+// the returned flag tells that tag is not closed at the end of text.
+func localizeText(s string, style Style, inTag bool) (string, int, bool) {
+	sb := &strings.Builder{}
+	length := 0
+	prev := 0
+	if inTag {
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return s, 0, true
+		}
+		sb.WriteString(s[:end+1])
+		prev = end + 1
+	}
+	write := func(text string) {
+		length += utf8.RuneCountInString(text)
+		switch style {
+		case StyleAccented:
+			sb.WriteString(strings.Map(accent, text))
+		case StyleBidi:
+			sb.WriteString(wordRe.ReplaceAllString(text, rlm+rlo+"${0}"+pdf+rlm))
+		default:
+			sb.WriteString(text)
+		}
+	}
+	start := prev
+	for _, loc := range markupRe.FindAllStringIndex(s[start:], -1) {
+		write(s[prev : start+loc[0]])
+		sb.WriteString(s[start+loc[0] : start+loc[1]])
+		prev = start + loc[1]
+	}
+	rest := s[prev:]
+	if loc := openTagRe.FindStringIndex(rest); loc != nil {
+		write(rest[:loc[0]])
+		sb.WriteString(rest[loc[0]:])
+		return sb.String(), length, true
+	}
+	write(rest)
+	return sb.String(), length, false
+}
+
+func accent(r rune) rune {
+	if a, ok := accents[r]; ok {
+		return a
+	}
+	return r
+}
+
+func accentsMap() map[rune]rune {
+	plain, accented := []rune(plainLetters), []rune(accentedLetters)
+	m := make(map[rune]rune, len(plain))
+	for i, r := range plain {
+		m[r] = accented[i]
+	}
+	return m
+}
+
+func hasCulture(cultures []string, culture string) bool {
+	for _, c := range cultures {
+		if c == culture {
+			return true
+		}
+	}
+	return false
+}
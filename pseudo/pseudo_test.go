@@ -0,0 +1,84 @@
+package pseudo
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalize(t *testing.T) {
+	require.Equal(t, utf8.RuneCountInString(plainLetters), utf8.RuneCountInString(accentedLetters))
+
+	tests := []struct {
+		msg       string
+		style     Style
+		expansion float64
+		brackets  bool
+		expected  string
+	}{
+		{"", StyleAccented, 0.3, true, ""},
+		{"Settings", StyleAccented, 0, false, "Šéţţîñĝš"},
+		{"Settings", StyleAccented, 0.3, true, "[Šéţţîñĝš~~~]"},
+		{"Hello {name}!", StyleAccented, 0.5, true, "[Ĥéļļö {name}!~~~~]"},
+		{"{count, plural, =0{No items} one{# item} other{# items in {place}}}", StyleAccented, 0.5, false,
+			"{count, plural, =0{Ñö îţéɱš~~~~} one{# îţéɱ~~~} other{# îţéɱš îñ {place}~~~~~}}"},
+		{"{gender, select, male{He} other{They}} liked it", StyleAccented, 0, true,
+			"[{gender, select, male{Ĥé} other{Ţĥéý}} ļîķéð îţ]"},
+		{"Read <a href=\"https://example.com\">terms</a> &amp; {date, date, short}", StyleAccented, 0, false,
+			"Ŕéåð <a href=\"https://example.com\">ţéŕɱš</a> &amp; {date, date, short}"},
+		{"Open <a href=\"{url}\" class=\"{style} link\">link</a> now", StyleAccented, 0, false,
+			"Öþéñ <a href=\"{url}\" class=\"{style} link\">ļîñķ</a> ñöŵ"},
+		{"<a href=\"{url}\">Go</a>", StyleBidi, 0, false, "<a href=\"{url}\">" + rlm + rlo + "Go" + pdf + rlm + "</a>"},
+		{"Hello, {name}", StyleBidi, 0, true, "[" + rlm + rlo + "Hello," + pdf + rlm + " {name}]"},
+		{"<b>Bold</b> text", StyleBidi, 0, false, "<b>" + rlm + rlo + "Bold" + pdf + rlm + "</b> " + rlm + rlo + "text" + pdf + rlm},
+	}
+	for _, tt := range tests {
+		actual, err := Localize(tt.msg, tt.style, tt.expansion, tt.brackets)
+		require.NoError(t, err, tt.msg)
+		require.Equal(t, tt.expected, actual, tt.msg)
+		require.NoError(t, icu.Validate(actual), tt.msg)
+	}
+
+	_, err := Localize("Hello {name", StyleAccented, 0, false)
+	require.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	arbData := &arb.Data{
+		Cultures: []string{"en", "ru", "en-XA"},
+		Keys:     []string{"title", "broken", "empty"},
+		Items: map[string]*arb.Item{
+			"title":  {Cultures: map[string]string{"en": "Title", "ru": "Заголовок", "en-XA": "old"}, States: map[string]string{"en-XA": arb.StateFuzzy}},
+			"broken": {Cultures: map[string]string{"en": "Hello {name"}},
+			"empty":  {Cultures: map[string]string{"ru": "Пусто"}},
+		},
+	}
+	err := Generate(logrus.New(), arbData, Params{
+		DefaultCulture: "en",
+		Accented:       "en_xa",
+		Bidi:           DefaultBidi,
+		Expansion:      0.4,
+		Brackets:       true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"en", "ru", "en-XA", "ar-XB"}, arbData.Cultures)
+	require.Equal(t, map[string]string{
+		"en":    "Title",
+		"ru":    "Заголовок",
+		"en-XA": "[Ţîţļé~~]",
+		"ar-XB": "[" + rlm + rlo + "Title" + pdf + rlm + "~~]",
+	}, arbData.Items["title"].Cultures)
+	require.Empty(t, arbData.Items["title"].States)
+	require.Equal(t, "Hello {name", arbData.Items["broken"].Cultures["en-XA"])
+	require.Equal(t, "", arbData.Items["empty"].Cultures["ar-XB"])
+
+	err = Generate(logrus.New(), arbData, Params{DefaultCulture: "en", Accented: "en"})
+	require.ErrorIs(t, err, ErrInvalidCulture)
+	err = Generate(logrus.New(), arbData, Params{DefaultCulture: "en", Bidi: "xx-YY"})
+	require.ErrorIs(t, err, ErrInvalidCulture)
+}
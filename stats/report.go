@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// Format is an output format of report.
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+	// FormatSVG writes coverage badge of every culture to file.
+	FormatSVG Format = "svg"
+)
+
+var (
+	ErrInvalidFormat = errors.New("invalid stats format")
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatText, FormatJSON, FormatMarkdown, FormatSVG:
+		return f, nil
+	case "md":
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidFormat)
+}
+
+// Write writes report as text, json or markdown, badges are written by SaveBadges.
+func (r *Report) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatMarkdown:
+		return r.writeMarkdown(w)
+	case FormatText:
+		return r.writeText(w)
+	}
+	return fmt.Errorf("%s: %w", format, ErrInvalidFormat)
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	fmt.Fprintf(w, "keys: %d, source words: %d, source chars: %d\n", r.Keys, r.SourceWords, r.SourceChars)
+	fmt.Fprintf(w, "keys with placeholders: %d, plurals: %d, selects: %d\n\n", r.Placeholders, r.Plurals, r.Selects)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "culture\tcoverage\ttranslated\tmissing\tmissing words\tmissing chars")
+	for _, cs := range r.Cultures {
+		fmt.Fprintf(tw, "%s\t%s%%\t%d\t%d\t%d\t%d\n", cs.Culture, formatPercent(cs.Coverage),
+			cs.Translated, cs.Missing, cs.MissingWords, cs.MissingChars)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Prefixes) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "prefix\tkeys")
+	for _, cs := range r.Cultures {
+		fmt.Fprintf(tw, "\t%s missing", cs.Culture)
+	}
+	fmt.Fprintln(tw)
+	for _, ps := range r.Prefixes {
+		fmt.Fprintf(tw, "%s\t%d", ps.Prefix, ps.Keys)
+		for _, cs := range r.Cultures {
+			fmt.Fprintf(tw, "\t%d", ps.Missing[cs.Culture])
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func (r *Report) writeMarkdown(w io.Writer) error {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "Keys: %d, source words: %d, source chars: %d. ", r.Keys, r.SourceWords, r.SourceChars)
+	fmt.Fprintf(sb, "Keys with placeholders: %d, plurals: %d, selects: %d.\n\n", r.Placeholders, r.Plurals, r.Selects)
+
+	sb.WriteString("| Culture | Coverage | Translated | Missing | Missing words | Missing chars |\n")
+	sb.WriteString("|---|---:|---:|---:|---:|---:|\n")
+	for _, cs := range r.Cultures {
+		fmt.Fprintf(sb, "| %s | %s%% | %d | %d | %d | %d |\n", cs.Culture, formatPercent(cs.Coverage),
+			cs.Translated, cs.Missing, cs.MissingWords, cs.MissingChars)
+	}
+
+	if len(r.Prefixes) > 0 {
+		sb.WriteString("\n| Prefix | Keys |")
+		for _, cs := range r.Cultures {
+			fmt.Fprintf(sb, " %s missing |", cs.Culture)
+		}
+		sb.WriteString("\n|---|---:|" + strings.Repeat("---:|", len(r.Cultures)) + "\n")
+		for _, ps := range r.Prefixes {
+			fmt.Fprintf(sb, "| %s | %d |", strings.ReplaceAll(ps.Prefix, "|", `\|`), ps.Keys)
+			for _, cs := range r.Cultures {
+				fmt.Fprintf(sb, " %d |", ps.Missing[cs.Culture])
+			}
+			sb.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// SaveBadges writes coverage badge of every culture to folder as <culture>.svg and returns paths of files.
+func (r *Report) SaveBadges(folderPath string) ([]string, error) {
+	if err := os.MkdirAll(folderPath, 0777); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, cs := range r.Cultures {
+		filePath := filepath.Join(folderPath, cs.Culture+".svg")
+		if err := ioutil.WriteFile(filePath, Badge(cs.Culture, cs.Coverage), 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, filePath)
+	}
+	return paths, nil
+}
+
+// Badge returns svg badge "<label> | <coverage>%" colored by coverage.
+func Badge(label string, coverage float64) []byte {
+	value := formatPercent(coverage) + "%"
+	color := "#e05d44"
+	switch {
+	case coverage >= 100:
+		color = "#4c1"
+	case coverage >= 90:
+		color = "#97ca00"
+	case coverage >= 75:
+		color = "#dfb317"
+	case coverage >= 50:
+		color = "#fe7d37"
+	}
+
+	// width of Verdana 11px text is about 7px per character
+	labelWidth := utf8.RuneCountInString(label)*7 + 10
+	valueWidth := utf8.RuneCountInString(value)*7 + 10
+	width := labelWidth + valueWidth
+	label, value = html.EscapeString(label), html.EscapeString(value)
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[2]s: %[3]s">
+  <title>%[2]s: %[3]s</title>
+  <linearGradient id="s" x2="0" y2="100%%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="%[1]d" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%[4]d" height="20" fill="#555"/>
+    <rect x="%[4]d" width="%[5]d" height="20" fill="%[6]s"/>
+    <rect width="%[1]d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%[7]d" y="14">%[2]s</text>
+    <text x="%[8]d" y="14">%[3]s</text>
+  </g>
+</svg>
+`, width, label, value, labelWidth, valueWidth, color, labelWidth/2, labelWidth+valueWidth/2))
+}
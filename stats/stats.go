@@ -0,0 +1,255 @@
+// Package stats computes translation coverage of arb data: completion of cultures, size of missing
+// source text, usage of placeholders and plurals and coverage by key prefixes.
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+)
+
+var (
+	ErrInvalidMinCoverage = errors.New("invalid min coverage")
+	ErrCoverage           = errors.New("coverage is below minimum")
+)
+
+// Report is a result of Compute. Only keys with non empty message of default culture are counted.
+type Report struct {
+	DefaultCulture string `json:"defaultCulture"`
+	Keys           int    `json:"keys"`
+	// SourceWords and SourceChars are sizes of text of default culture messages without placeholders,
+	// all plural and select branches are counted.
+	SourceWords int `json:"sourceWords"`
+	SourceChars int `json:"sourceChars"`
+	// Placeholders, Plurals and Selects are counts of keys with placeholders, plural (selectordinal)
+	// and select arguments.
+	Placeholders int `json:"placeholders"`
+	Plurals      int `json:"plurals"`
+	Selects      int `json:"selects"`
+
+	Cultures []*CultureStats `json:"cultures"`
+	Prefixes []*PrefixStats  `json:"prefixes"`
+}
+
+// CultureStats is a coverage of culture, MissingWords and MissingChars are size of source text of missing translations.
+type CultureStats struct {
+	Culture      string  `json:"culture"`
+	Translated   int     `json:"translated"`
+	Missing      int     `json:"missing"`
+	Coverage     float64 `json:"coverage"`
+	MissingWords int     `json:"missingWords"`
+	MissingChars int     `json:"missingChars"`
+}
+
+// PrefixStats is a count of keys with prefix (see arb.KeyPrefix) and missing translations of every culture.
+type PrefixStats struct {
+	Prefix  string         `json:"prefix"`
+	Keys    int            `json:"keys"`
+	Missing map[string]int `json:"missing"`
+}
+
+// MinCoverage is a minimal coverage in percent of every culture, zero means no minimum.
+type MinCoverage struct {
+	Default  float64
+	Cultures map[string]float64
+}
+
+// For returns minimal coverage of culture.
+func (m MinCoverage) For(culture string) float64 {
+	if v, ok := m.Cultures[culture]; ok {
+		return v
+	}
+	return m.Default
+}
+
+// ParseMinCoverage parses comma separated list of coverages: "90,ru=95,de=80", item without culture
+// sets coverage of other cultures.
+func ParseMinCoverage(s string) (MinCoverage, error) {
+	minCoverage := MinCoverage{Cultures: make(map[string]float64)}
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		culture, value := "", part
+		if eq := strings.Index(part, "="); eq >= 0 {
+			c, err := locale.Canonical(strings.TrimSpace(part[:eq]))
+			if err != nil {
+				return minCoverage, fmt.Errorf("%s (%v): %w", part, err, ErrInvalidMinCoverage)
+			}
+			culture, value = c, part[eq+1:]
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+		if err != nil || v < 0 || v > 100 {
+			return minCoverage, fmt.Errorf("%s: %w", part, ErrInvalidMinCoverage)
+		}
+		if culture == "" {
+			minCoverage.Default = v
+		} else {
+			minCoverage.Cultures[culture] = v
+		}
+	}
+	return minCoverage, nil
+}
+
+// Compute returns statistics of arb data, keys without message of default culture are not counted.
+func Compute(arbData *arb.Data, defaultCulture string) (*Report, error) {
+	defaultCulture, err := locale.Canonical(defaultCulture)
+	if err != nil {
+		return nil, fmt.Errorf("default culture: %w", err)
+	}
+
+	report := &Report{DefaultCulture: defaultCulture}
+	cultures := arbData.OrderedCultures(defaultCulture)
+	byCulture := make(map[string]*CultureStats, len(cultures))
+	for _, c := range cultures {
+		byCulture[c] = &CultureStats{Culture: c}
+		report.Cultures = append(report.Cultures, byCulture[c])
+	}
+	byPrefix := make(map[string]*PrefixStats)
+
+	for key, item := range arbData.Items {
+		source := item.Cultures[defaultCulture]
+		if strings.TrimSpace(source) == "" {
+			continue
+		}
+		report.Keys++
+
+		usage := messageUsage(source)
+		report.SourceWords += usage.words
+		report.SourceChars += usage.chars
+		if usage.placeholders || len(item.Parameters) > 0 {
+			report.Placeholders++
+		}
+		if usage.plurals {
+			report.Plurals++
+		}
+		if usage.selects {
+			report.Selects++
+		}
+
+		prefix := arb.KeyPrefix(key)
+		ps, ok := byPrefix[prefix]
+		if !ok {
+			ps = &PrefixStats{Prefix: prefix, Missing: make(map[string]int)}
+			byPrefix[prefix] = ps
+		}
+		ps.Keys++
+
+		for _, c := range cultures {
+			cs := byCulture[c]
+			if strings.TrimSpace(item.Cultures[c]) != "" {
+				cs.Translated++
+				continue
+			}
+			cs.Missing++
+			cs.MissingWords += usage.words
+			cs.MissingChars += usage.chars
+			ps.Missing[c]++
+		}
+	}
+
+	for _, cs := range report.Cultures {
+		cs.Coverage = 100
+		if report.Keys > 0 {
+			cs.Coverage = float64(cs.Translated) * 100 / float64(report.Keys)
+		}
+	}
+	for _, ps := range byPrefix {
+		report.Prefixes = append(report.Prefixes, ps)
+	}
+	sort.Slice(report.Prefixes, func(i, j int) bool {
+		return report.Prefixes[i].Prefix < report.Prefixes[j].Prefix
+	})
+	return report, nil
+}
+
+// CheckCoverage returns error with cultures which coverage is below minimum,
+// cultures of minCoverage which are absent in report have zero coverage.
+func (r *Report) CheckCoverage(minCoverage MinCoverage) error {
+	var failed []string
+	reported := make(map[string]bool, len(r.Cultures))
+	for _, cs := range r.Cultures {
+		reported[cs.Culture] = true
+		if min := minCoverage.For(cs.Culture); cs.Coverage < min {
+			failed = append(failed, fmt.Sprintf("%s %s%% < %s%%", cs.Culture, formatPercent(cs.Coverage), formatPercent(min)))
+		}
+	}
+	var absent []string
+	for c, min := range minCoverage.Cultures {
+		if !reported[c] && min > 0 {
+			absent = append(absent, c)
+		}
+	}
+	sort.Strings(absent)
+	for _, c := range absent {
+		failed = append(failed, fmt.Sprintf("%s is absent, %s%% < %s%%", c, formatPercent(0), formatPercent(minCoverage.Cultures[c])))
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", strings.Join(failed, ", "), ErrCoverage)
+}
+
+type usage struct {
+	words, chars     int
+	placeholders     bool
+	plurals, selects bool
+}
+
+// messageUsage returns size of text and usage of arguments of message, invalid message is counted as text.
+func messageUsage(msg string) usage {
+	m, err := icu.Parse(msg)
+	if err != nil {
+		return usage{words: countWords(msg), chars: utf8.RuneCountInString(msg)}
+	}
+	u := usage{}
+	u.add(m)
+	return u
+}
+
+func (u *usage) add(m icu.Message) {
+	for _, n := range m {
+		switch n := n.(type) {
+		case *icu.Text:
+			u.words += countWords(n.Value)
+			u.chars += utf8.RuneCountInString(n.Value)
+		case *icu.Argument:
+			u.placeholders = true
+		case *icu.Plural:
+			u.placeholders, u.plurals = true, true
+			for _, o := range n.Options {
+				u.add(o.Value)
+			}
+		case *icu.Select:
+			u.placeholders, u.selects = true, true
+			for _, o := range n.Options {
+				u.add(o.Value)
+			}
+		}
+	}
+}
+
+// countWords counts words with letters or digits, so punctuation around placeholders is not counted.
+func countWords(s string) int {
+	n := 0
+	for _, f := range strings.Fields(s) {
+		if strings.IndexFunc(f, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// formatPercent formats coverage rounded down to one decimal, so incomplete culture is never 100%.
+func formatPercent(v float64) string {
+	return strconv.FormatFloat(float64(int64(v*10))/10, 'f', 1, 64)
+}
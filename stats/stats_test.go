@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/stretchr/testify/require"
+)
+
+func testData() *arb.Data {
+	return &arb.Data{
+		Cultures: []string{"ru", "en", "de"},
+		Items: map[string]*arb.Item{
+			"homeTitle": {Cultures: map[string]string{"en": "Home page", "ru": "Главная", "de": "Startseite"}},
+			"homeGreeting": {
+				Cultures:   map[string]string{"en": "Hello {name}, welcome!", "ru": "Привет {name}!", "de": ""},
+				Parameters: map[string]*arb.Placeholder{"name": {}},
+			},
+			"cart_items": {Cultures: map[string]string{
+				"en": "{count, plural, one{# item} other{# items}} in {gender, select, male{his} other{their}} cart",
+			}},
+			"empty": {Cultures: map[string]string{"en": "", "ru": "Пусто"}},
+		},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	report, err := Compute(testData(), "en")
+	require.NoError(t, err)
+
+	require.Equal(t, "en", report.DefaultCulture)
+	require.Equal(t, 3, report.Keys)
+	// Home page (2) + Hello, welcome (2) + item, items, in, his, their, cart (6)
+	require.Equal(t, 10, report.SourceWords)
+	require.Equal(t, 2, report.Placeholders)
+	require.Equal(t, 1, report.Plurals)
+	require.Equal(t, 1, report.Selects)
+
+	require.Equal(t, []*CultureStats{
+		{Culture: "en", Translated: 3, Coverage: 100},
+		{Culture: "de", Translated: 1, Missing: 2, Coverage: 100.0 / 3, MissingWords: 8, MissingChars: report.SourceChars - 9},
+		{Culture: "ru", Translated: 2, Missing: 1, Coverage: 200.0 / 3, MissingWords: 6, MissingChars: report.SourceChars - 9 - 16},
+	}, report.Cultures)
+	require.Equal(t, []*PrefixStats{
+		{Prefix: "cart", Keys: 1, Missing: map[string]int{"de": 1, "ru": 1}},
+		{Prefix: "home", Keys: 2, Missing: map[string]int{"de": 1}},
+	}, report.Prefixes)
+
+	minCoverage, err := ParseMinCoverage("ru=60, de=30%")
+	require.NoError(t, err)
+	require.NoError(t, report.CheckCoverage(minCoverage))
+
+	minCoverage, err = ParseMinCoverage("50,ru=90")
+	require.NoError(t, err)
+	err = report.CheckCoverage(minCoverage)
+	require.ErrorIs(t, err, ErrCoverage)
+	require.Contains(t, err.Error(), "de 33.3% < 50.0%, ru 66.6% < 90.0%")
+
+	minCoverage, err = ParseMinCoverage("ru=60,fr=80,it=0")
+	require.NoError(t, err)
+	err = report.CheckCoverage(minCoverage)
+	require.ErrorIs(t, err, ErrCoverage)
+	require.Equal(t, "fr is absent, 0.0% < 80.0%: "+ErrCoverage.Error(), err.Error())
+
+	for _, s := range []string{"ru=101", "xx-invalid-=90", "ru=much"} {
+		_, err = ParseMinCoverage(s)
+		require.ErrorIs(t, err, ErrInvalidMinCoverage, s)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	report, err := Compute(&arb.Data{
+		Cultures: []string{"en", "ru"},
+		Items: map[string]*arb.Item{
+			"a": {Cultures: map[string]string{"en": "One two", "ru": "Один два"}},
+			"b": {Cultures: map[string]string{"en": "Three"}},
+		},
+	}, "en")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.Write(buf, FormatText))
+	require.Equal(t, `keys: 2, source words: 3, source chars: 12
+keys with placeholders: 0, plurals: 0, selects: 0
+
+culture  coverage  translated  missing  missing words  missing chars
+en       100.0%    2           0        0              0
+ru       50.0%     1           1        1              5
+
+prefix  keys  en missing  ru missing
+a       1     0           0
+b       1     0           1
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, report.Write(buf, FormatMarkdown))
+	require.Equal(t, `Keys: 2, source words: 3, source chars: 12. Keys with placeholders: 0, plurals: 0, selects: 0.
+
+| Culture | Coverage | Translated | Missing | Missing words | Missing chars |
+|---|---:|---:|---:|---:|---:|
+| en | 100.0% | 2 | 0 | 0 | 0 |
+| ru | 50.0% | 1 | 1 | 1 | 5 |
+
+| Prefix | Keys | en missing | ru missing |
+|---|---:|---:|---:|
+| a | 1 | 0 | 0 |
+| b | 1 | 0 | 1 |
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, report.Write(buf, FormatJSON))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, report, &decoded)
+
+	dir, err := ioutil.TempDir("", "stats")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	paths, err := report.SaveBadges(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{path.Join(dir, "en.svg"), path.Join(dir, "ru.svg")}, paths)
+	svg, err := ioutil.ReadFile(paths[1])
+	require.NoError(t, err)
+	require.Contains(t, string(svg), `aria-label="ru: 50.0%"`)
+	require.Contains(t, string(svg), `fill="#fe7d37"`)
+
+	f, err := ParseFormat("SVG")
+	require.NoError(t, err)
+	require.Equal(t, FormatSVG, f)
+	_, err = ParseFormat("pdf")
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
@@ -0,0 +1,127 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb/locale"
+)
+
+// maxResponseSize limits size of provider response.
+const maxResponseSize = 32 << 20
+
+// LibreTranslate is a provider of LibreTranslate compatible API (POST <URL>/translate), texts are sent
+// in html format, so <x id="N"/> tags are kept. Text outside of these tags is escaped, so "&", "<" and markup
+// of messages are translated as text and unescaped in translations. Cultures are sent as language subtags: pt-BR -> pt.
+type LibreTranslate struct {
+	// URL is a base url of API: http://localhost:5000.
+	URL    string
+	APIKey string
+	// Client is used for requests, http.DefaultClient is used if it is nil.
+	Client *http.Client
+}
+
+type libreRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type libreResponse struct {
+	TranslatedText json.RawMessage `json:"translatedText"`
+	Error          string          `json:"error"`
+}
+
+func (p *LibreTranslate) Translate(ctx context.Context, texts []string, from, to string) ([]string, error) {
+	escaped := make([]string, len(texts))
+	for i, text := range texts {
+		escaped[i] = libreEscape(text)
+	}
+	body, err := json.Marshal(libreRequest{
+		Q:      escaped,
+		Source: libreLanguage(from),
+		Target: libreLanguage(to),
+		Format: "html",
+		APIKey: p.APIKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	url := strings.TrimSuffix(p.URL, "/") + "/translate"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	var res libreResponse
+	jsonErr := json.Unmarshal(data, &res)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if jsonErr == nil && res.Error != "" {
+			return nil, fmt.Errorf("%s: %s", resp.Status, res.Error)
+		}
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	if jsonErr != nil {
+		return nil, fmt.Errorf("invalid response (%v)", jsonErr)
+	}
+
+	var translations []string
+	if err := json.Unmarshal(res.TranslatedText, &translations); err != nil {
+		// some servers return string for single text
+		var s string
+		if len(texts) != 1 || json.Unmarshal(res.TranslatedText, &s) != nil {
+			return nil, fmt.Errorf("invalid translatedText (%v)", err)
+		}
+		translations = []string{s}
+	}
+	for i, text := range translations {
+		translations[i] = html.UnescapeString(text)
+	}
+	return translations, nil
+}
+
+// libreEscape escapes text outside of <x id="N"/> tags.
+func libreEscape(s string) string {
+	sb := &strings.Builder{}
+	prev := 0
+	for _, loc := range tagRe.FindAllStringIndex(s, -1) {
+		sb.WriteString(html.EscapeString(s[prev:loc[0]]))
+		sb.WriteString(s[loc[0]:loc[1]])
+		prev = loc[1]
+	}
+	sb.WriteString(html.EscapeString(s[prev:]))
+	return sb.String()
+}
+
+func libreLanguage(culture string) string {
+	t, err := locale.Parse(culture)
+	if err != nil {
+		return culture
+	}
+	return t.Language
+}
@@ -0,0 +1,67 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibreTranslate(t *testing.T) {
+	var received libreRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/translate", r.URL.Path)
+		received = libreRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case received.APIKey != "key":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"Invalid API key"}`))
+		case len(received.Q) == 1:
+			json.NewEncoder(w).Encode(map[string]string{"translatedText": strings.ToUpper(received.Q[0])})
+		default:
+			res := make([]string, len(received.Q))
+			for i, q := range received.Q {
+				res[i] = strings.ToUpper(q)
+			}
+			json.NewEncoder(w).Encode(map[string][]string{"translatedText": res})
+		}
+	}))
+	defer server.Close()
+
+	provider := &LibreTranslate{URL: server.URL + "/", APIKey: "key"}
+	translations, err := provider.Translate(context.Background(), []string{"one", "two"}, "en", "pt-BR")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ONE", "TWO"}, translations)
+	require.Equal(t, libreRequest{Q: []string{"one", "two"}, Source: "en", Target: "pt", Format: "html", APIKey: "key"}, received)
+
+	translations, err = provider.Translate(context.Background(), []string{"one"}, "en", "ru")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ONE"}, translations)
+
+	provider.APIKey = ""
+	_, err = provider.Translate(context.Background(), []string{"one"}, "en", "ru")
+	require.EqualError(t, err, "403 Forbidden: Invalid API key")
+}
+
+func TestLibreTranslateEscaping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req libreRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, []string{`Tom &amp; &lt;b&gt;Jerry&lt;/b&gt; <x id="0"/> &#34;&amp;amp;&#34;`}, req.Q)
+		// html translator keeps tags and entities
+		json.NewEncoder(w).Encode(map[string][]string{"translatedText": {strings.Replace(req.Q[0], "Tom", "Tomás", 1)}})
+	}))
+	defer server.Close()
+
+	provider := &LibreTranslate{URL: server.URL}
+	translations, err := provider.Translate(context.Background(), []string{`Tom & <b>Jerry</b> <x id="0"/> "&amp;"`}, "en", "es")
+	require.NoError(t, err)
+	require.Equal(t, []string{`Tomás & <b>Jerry</b> <x id="0"/> "&amp;"`}, translations)
+}
@@ -0,0 +1,400 @@
+// Package translate fills missing translations of arb data by machine translation provider.
+// Placeholders and ICU syntax are not sent to provider: text of message and of every plural and select
+// branch is translated separately, arguments are replaced by <x id="N"/> tags which are restored after translation.
+package translate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultBatchSize is a count of texts sent to provider in one request.
+const DefaultBatchSize = 50
+
+var (
+	ErrInvalidCulture = errors.New("invalid culture")
+	ErrProvider       = errors.New("translation provider error")
+	ErrLostTags       = errors.New("translation lost placeholders")
+)
+
+// Provider translates texts from culture to culture. Texts contain <x id="N"/> tags (and markup tags of
+// messages) which must be kept in translations, translations are returned in order of texts.
+type Provider interface {
+	Translate(ctx context.Context, texts []string, from, to string) ([]string, error)
+}
+
+type Params struct {
+	DefaultCulture string
+	// Cultures are filled cultures, all cultures except default are filled if it is empty.
+	Cultures []string
+	// BatchSize is a count of texts in one request to provider, DefaultBatchSize is used if it is zero.
+	BatchSize int
+}
+
+// Fill describes translation filled by provider.
+type Fill struct {
+	Key     string
+	Culture string
+}
+
+func (f *Fill) String() string {
+	return fmt.Sprintf("key %s, culture %s: machine translated", f.Key, f.Culture)
+}
+
+var tagRe = regexp.MustCompile(`(?i)<x\s+id\s*=\s*"(\d+)"\s*/>`)
+
+// segment is a text of message or of plural (select) branch, nodes are arguments replaced by tags.
+type segment struct {
+	msg   *icu.Message
+	text  string
+	nodes []icu.Node
+}
+
+// job is a message translated to culture.
+type job struct {
+	key      string
+	root     icu.Message
+	segments []*segment
+}
+
+// Translate fills empty translations of cultures from default culture and marks them with arb.StateMachine.
+// Messages which translations lost placeholders or are not valid ICU messages are skipped with warning.
+func Translate(ctx context.Context, logger *logrus.Logger, arbData *arb.Data, provider Provider, params Params) ([]*Fill, error) {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return nil, fmt.Errorf("default culture: %w", err)
+	}
+	cultures, err := targetCultures(arbData, defaultCulture, params.Cultures)
+	if err != nil {
+		return nil, err
+	}
+	batchSize := params.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	var fills []*Fill
+	for _, culture := range cultures {
+		categories := &pluralSet{cardinal: pluralCategories(culture, false), ordinal: pluralCategories(culture, true)}
+		var jobs []*job
+		for _, key := range arbData.OrderedKeys(arb.OrderSource) {
+			item := arbData.Items[key]
+			source := item.Cultures[defaultCulture]
+			if strings.TrimSpace(source) == "" || strings.TrimSpace(item.Cultures[culture]) != "" {
+				continue
+			}
+			root, err := icu.Parse(source)
+			if err != nil {
+				logger.Warningf("key %s: %v, message is not translated", key, err)
+				continue
+			}
+			addPluralCategories(root, categories)
+			j := &job{key: key, root: root}
+			j.segments = splitMessage(&j.root, nil)
+			jobs = append(jobs, j)
+		}
+		if len(jobs) == 0 {
+			continue
+		}
+
+		if err := translateJobs(ctx, provider, jobs, defaultCulture, culture, batchSize); err != nil {
+			return fills, err
+		}
+
+		for _, j := range jobs {
+			if err := j.restore(); err != nil {
+				logger.Warningf("key %s, culture %s: %v, message is not translated", j.key, culture, err)
+				continue
+			}
+			msg := j.root.String()
+			if err := icu.Validate(msg); err != nil {
+				logger.Warningf("key %s, culture %s: invalid translation %q (%v)", j.key, culture, msg, err)
+				continue
+			}
+
+			item := arbData.Items[j.key]
+			if item.Cultures == nil {
+				item.Cultures = make(map[string]string)
+			}
+			item.Cultures[culture] = msg
+			item.SetState(culture, arb.StateMachine)
+			fill := &Fill{Key: j.key, Culture: culture}
+			logger.Debugln(fill)
+			fills = append(fills, fill)
+		}
+	}
+	return fills, nil
+}
+
+func targetCultures(arbData *arb.Data, defaultCulture string, cultures []string) ([]string, error) {
+	if len(cultures) == 0 {
+		var res []string
+		for _, c := range arbData.OrderedCultures(defaultCulture) {
+			if c != defaultCulture {
+				res = append(res, c)
+			}
+		}
+		return res, nil
+	}
+
+	res := make([]string, 0, len(cultures))
+	for _, c := range cultures {
+		culture, err := locale.Canonical(c)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%v): %w", c, err, ErrInvalidCulture)
+		}
+		if culture == defaultCulture {
+			return nil, fmt.Errorf("%s is default culture: %w", c, ErrInvalidCulture)
+		}
+		res = append(res, culture)
+		addCulture(arbData, culture)
+	}
+	return res, nil
+}
+
+// translateJobs sends segments with text to provider in batches, segments without letters are kept as is.
+func translateJobs(ctx context.Context, provider Provider, jobs []*job, from, to string, batchSize int) error {
+	var segments []*segment
+	for _, j := range jobs {
+		for _, s := range j.segments {
+			if strings.IndexFunc(tagRe.ReplaceAllString(s.text, ""), unicode.IsLetter) >= 0 {
+				segments = append(segments, s)
+			}
+		}
+	}
+
+	for start := 0; start < len(segments); start += batchSize {
+		batch := segments[start:min(start+batchSize, len(segments))]
+		texts := make([]string, len(batch))
+		for i, s := range batch {
+			texts[i] = strings.TrimSpace(s.text)
+		}
+
+		translations, err := provider.Translate(ctx, texts, from, to)
+		if err != nil {
+			return fmt.Errorf("%s -> %s (%v): %w", from, to, err, ErrProvider)
+		}
+		if len(translations) != len(texts) {
+			return fmt.Errorf("%s -> %s: %d translations of %d texts: %w", from, to, len(translations), len(texts), ErrProvider)
+		}
+		for i, s := range batch {
+			// providers trim spaces around text, they are significant between arguments
+			s.text = leadingSpace(s.text) + strings.TrimSpace(translations[i]) + trailingSpace(s.text)
+		}
+	}
+	return nil
+}
+
+// splitMessage replaces arguments of message by tags and returns segments of message and of its branches.
+func splitMessage(m *icu.Message, segments []*segment) []*segment {
+	s := &segment{msg: m}
+	segments = append(segments, s)
+	sb := &strings.Builder{}
+	for _, n := range *m {
+		switch n := n.(type) {
+		case *icu.Text:
+			sb.WriteString(n.Value)
+			continue
+		case *icu.Plural:
+			for _, o := range n.Options {
+				segments = splitMessage(&o.Value, segments)
+			}
+		case *icu.Select:
+			for _, o := range n.Options {
+				segments = splitMessage(&o.Value, segments)
+			}
+		}
+		fmt.Fprintf(sb, `<x id="%d"/>`, len(s.nodes))
+		s.nodes = append(s.nodes, n)
+	}
+	s.text = sb.String()
+	return segments
+}
+
+// pluralSet keeps plural categories of culture.
+type pluralSet struct {
+	cardinal, ordinal []string
+}
+
+// pluralOrder is an order of CLDR plural categories in messages.
+var pluralOrder = []string{
+	locale.PluralZero, locale.PluralOne, locale.PluralTwo, locale.PluralFew, locale.PluralMany, locale.PluralOther,
+}
+
+// pluralCategories returns CLDR plural categories used by integer and decimal numbers of culture.
+func pluralCategories(culture string, ordinal bool) []string {
+	used := make(map[string]bool)
+	samples := []string{"1000000"}
+	for n := 0; n <= 1000; n++ {
+		samples = append(samples, strconv.Itoa(n))
+	}
+	if !ordinal {
+		for n := 0; n < 30; n++ {
+			samples = append(samples, fmt.Sprintf("%d.%d", n/10, n%10))
+		}
+	}
+	for _, sample := range samples {
+		o, _ := locale.ParseOperands(sample)
+		if ordinal {
+			used[locale.OrdinalCategory(culture, o)] = true
+		} else {
+			used[locale.CardinalCategory(culture, o)] = true
+		}
+	}
+	var categories []string
+	for _, c := range pluralOrder {
+		if used[c] {
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
+
+// addPluralCategories adds plural categories of target culture which are absent in plural arguments of
+// source message, they are filled by copies of "other" branch and are translated as separate texts.
+func addPluralCategories(m icu.Message, categories *pluralSet) {
+	for _, n := range m {
+		switch n := n.(type) {
+		case *icu.Plural:
+			if n.Ordinal {
+				n.Options = addOptions(n.Options, categories.ordinal)
+			} else {
+				n.Options = addOptions(n.Options, categories.cardinal)
+			}
+			for _, o := range n.Options {
+				addPluralCategories(o.Value, categories)
+			}
+		case *icu.Select:
+			for _, o := range n.Options {
+				addPluralCategories(o.Value, categories)
+			}
+		}
+	}
+}
+
+func addOptions(options []*icu.Option, categories []string) []*icu.Option {
+	otherIndex := -1
+	selectors := make(map[string]bool, len(options))
+	for i, o := range options {
+		selectors[o.Selector] = true
+		if o.Selector == locale.PluralOther {
+			otherIndex = i
+		}
+	}
+	if otherIndex < 0 {
+		return options
+	}
+
+	other := options[otherIndex]
+	res := make([]*icu.Option, 0, len(options)+len(categories))
+	res = append(res, options[:otherIndex]...)
+	for _, c := range categories {
+		if !selectors[c] {
+			res = append(res, &icu.Option{Selector: c, Value: cloneMessage(other.Value)})
+		}
+	}
+	return append(res, options[otherIndex:]...)
+}
+
+// cloneMessage copies message with its plural and select arguments, they are changed by translation.
+func cloneMessage(m icu.Message) icu.Message {
+	res := make(icu.Message, len(m))
+	for i, n := range m {
+		switch n := n.(type) {
+		case *icu.Plural:
+			p := *n
+			p.Options = cloneOptions(n.Options)
+			res[i] = &p
+		case *icu.Select:
+			s := *n
+			s.Options = cloneOptions(n.Options)
+			res[i] = &s
+		default:
+			res[i] = n
+		}
+	}
+	return res
+}
+
+func cloneOptions(options []*icu.Option) []*icu.Option {
+	res := make([]*icu.Option, len(options))
+	for i, o := range options {
+		res[i] = &icu.Option{Offset: o.Offset, Selector: o.Selector, Value: cloneMessage(o.Value)}
+	}
+	return res
+}
+
+// restore replaces messages of segments by translated texts with restored arguments.
+func (j *job) restore() error {
+	for _, s := range j.segments {
+		m, err := s.restore()
+		if err != nil {
+			return err
+		}
+		*s.msg = m
+	}
+	return nil
+}
+
+func (s *segment) restore() (icu.Message, error) {
+	var m icu.Message
+	used := make([]bool, len(s.nodes))
+	prev := 0
+	addText := func(text string) {
+		if text != "" {
+			m = append(m, &icu.Text{Value: text})
+		}
+	}
+	for _, loc := range tagRe.FindAllStringSubmatchIndex(s.text, -1) {
+		id, err := strconv.Atoi(s.text[loc[2]:loc[3]])
+		if err != nil || id >= len(s.nodes) || used[id] {
+			return nil, fmt.Errorf("unexpected tag %s: %w", s.text[loc[0]:loc[1]], ErrLostTags)
+		}
+		used[id] = true
+		addText(s.text[prev:loc[0]])
+		m = append(m, s.nodes[id])
+		prev = loc[1]
+	}
+	addText(s.text[prev:])
+
+	for id, ok := range used {
+		if !ok {
+			return nil, fmt.Errorf("tag %d is lost in %q: %w", id, s.text, ErrLostTags)
+		}
+	}
+	return m, nil
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeftFunc(s, unicode.IsSpace))]
+}
+
+func trailingSpace(s string) string {
+	return s[len(strings.TrimRightFunc(s, unicode.IsSpace)):]
+}
+
+func addCulture(arbData *arb.Data, culture string) {
+	for _, c := range arbData.Cultures {
+		if c == culture {
+			return
+		}
+	}
+	arbData.Cultures = append(arbData.Cultures, culture)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
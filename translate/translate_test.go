@@ -0,0 +1,111 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+var markupRe = regexp.MustCompile(`<[^>]*>`)
+
+// upperProvider translates texts to upper case and keeps tags, texts with "lose" lose tags.
+type upperProvider struct {
+	requests [][]string
+	err      error
+}
+
+func (p *upperProvider) Translate(ctx context.Context, texts []string, from, to string) ([]string, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.requests = append(p.requests, texts)
+	res := make([]string, len(texts))
+	for i, text := range texts {
+		if strings.Contains(text, "lose") {
+			text = tagRe.ReplaceAllString(text, "")
+		}
+		res[i] = " " + markupRe.ReplaceAllStringFunc(strings.ToUpper(text), strings.ToLower) + " "
+	}
+	return res, nil
+}
+
+func TestTranslate(t *testing.T) {
+	arbData := &arb.Data{
+		Cultures: []string{"en", "ru"},
+		Keys:     []string{"greeting", "items", "done", "count", "lost", "broken"},
+		Items: map[string]*arb.Item{
+			"greeting": {Cultures: map[string]string{"en": "Hello, {name}! Read <b>terms</b>", "ru": ""}},
+			"items": {Cultures: map[string]string{
+				"en": "{count, plural, =0{No items} one{# item in {place}} other{# items}} for {gender, select, male{him} other{them}}",
+			}},
+			"done":   {Cultures: map[string]string{"en": "Done", "ru": "Готово"}},
+			"count":  {Cultures: map[string]string{"en": "{count} {total}"}},
+			"lost":   {Cultures: map[string]string{"en": "lose {name}"}},
+			"broken": {Cultures: map[string]string{"en": "Hello {name"}},
+		},
+	}
+
+	provider := &upperProvider{}
+	fills, err := Translate(context.Background(), logrus.New(), arbData, provider, Params{
+		DefaultCulture: "en",
+		Cultures:       []string{"ru", "de_de"},
+		BatchSize:      4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "ru", "de-DE"}, arbData.Cultures)
+
+	require.Equal(t, "HELLO, {name}! READ <b>TERMS</b>", arbData.Items["greeting"].Cultures["ru"])
+	require.Equal(t, "{count, plural, =0{NO ITEMS} one{# ITEM IN {place}} other{# ITEMS}} FOR {gender, select, male{HIM} other{THEM}}",
+		arbData.Items["items"].Cultures["de-DE"])
+	// few and many categories of ru are copied from other
+	require.Equal(t, "{count, plural, =0{NO ITEMS} one{# ITEM IN {place}} few{# ITEMS} many{# ITEMS} other{# ITEMS}} FOR {gender, select, male{HIM} other{THEM}}",
+		arbData.Items["items"].Cultures["ru"])
+	require.Equal(t, "Готово", arbData.Items["done"].Cultures["ru"])
+	require.Equal(t, "{count} {total}", arbData.Items["count"].Cultures["ru"])
+	require.Equal(t, "", arbData.Items["lost"].Cultures["ru"])
+	require.Equal(t, "", arbData.Items["broken"].Cultures["ru"])
+	require.Equal(t, map[string]string{"ru": arb.StateMachine, "de-DE": arb.StateMachine}, arbData.Items["greeting"].States)
+	require.Equal(t, map[string]string{"de-DE": arb.StateMachine}, arbData.Items["done"].States)
+
+	// greeting, items (8 segments with text for ru, 6 for de-DE), lost and done (de-DE) in batches of 4
+	require.Equal(t, []string{
+		`Hello, <x id="0"/>! Read <b>terms</b>`, `<x id="0"/> for <x id="1"/>`, `No items`, `<x id="0"/> item in <x id="1"/>`,
+	}, provider.requests[0])
+	require.Len(t, provider.requests, 6)
+	require.Equal(t, []*Fill{
+		{"greeting", "ru"}, {"items", "ru"}, {"count", "ru"},
+		{"greeting", "de-DE"}, {"items", "de-DE"}, {"done", "de-DE"}, {"count", "de-DE"},
+	}, fills)
+
+	require.Equal(t, []string{locale.PluralOne, locale.PluralFew, locale.PluralMany, locale.PluralOther}, pluralCategories("ru", false))
+	require.Equal(t, []string{locale.PluralOne, locale.PluralTwo, locale.PluralFew, locale.PluralOther}, pluralCategories("en", true))
+	require.Equal(t, []string{locale.PluralOther}, pluralCategories("ja", false))
+
+	_, err = Translate(context.Background(), logrus.New(), arbData, provider, Params{DefaultCulture: "en", Cultures: []string{"en"}})
+	require.ErrorIs(t, err, ErrInvalidCulture)
+
+	arbData.Items["done"].Cultures["ru"] = ""
+	_, err = Translate(context.Background(), logrus.New(), arbData, &upperProvider{err: errors.New("unavailable")}, Params{DefaultCulture: "en"})
+	require.ErrorIs(t, err, ErrProvider)
+}
+
+func TestAddPluralCategories(t *testing.T) {
+	m, err := icu.Parse("{n, plural, one{# day} other{{g, select, male{# his days} other{# days}}}} {p, selectordinal, other{#th}}")
+	require.NoError(t, err)
+	addPluralCategories(m, &pluralSet{cardinal: pluralCategories("pl", false), ordinal: pluralCategories("en", true)})
+	require.Equal(t, "{n, plural, one{# day} few{{g, select, male{# his days} other{# days}}} many{{g, select, male{# his days} other{# days}}} "+
+		"other{{g, select, male{# his days} other{# days}}}} {p, selectordinal, one{#th} two{#th} few{#th} other{#th}}", m.String())
+
+	// copies of other are translated separately
+	few := m[0].(*icu.Plural).Options[1].Value[0].(*icu.Select)
+	few.Options[0].Value = icu.Message{&icu.Text{Value: "changed"}}
+	require.Contains(t, m.String(), "other{{g, select, male{# his days}")
+}
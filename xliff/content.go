@@ -0,0 +1,161 @@
+package xliff
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb/icu"
+)
+
+// toInline converts message to inline content of source or target element:
+// text is escaped, simple arguments become placeholder elements (<x/> for 1.2, <ph/> for 2.0),
+// plural and select arguments are kept as text with placeholders inside branches.
+func toInline(msg string, version Version) (string, error) {
+	m, err := icu.Parse(msg)
+	if err != nil {
+		return "", err
+	}
+	w := &inlineWriter{version: version, ids: make(map[string]int)}
+	w.write(m)
+	return w.sb.String(), nil
+}
+
+type inlineWriter struct {
+	version Version
+	sb      strings.Builder
+	// ids counts arguments with the same name to keep ids of placeholders unique
+	ids map[string]int
+}
+
+func (w *inlineWriter) write(m icu.Message) {
+	for _, n := range m {
+		switch n := n.(type) {
+		case *icu.Text:
+			w.text(n.Value)
+		case *icu.Pound:
+			w.text("#")
+		case *icu.Argument:
+			w.placeholder(n)
+		case *icu.Plural:
+			argType := icu.TypePlural
+			if n.Ordinal {
+				argType = icu.TypeSelectOrdinal
+			}
+			w.text(fmt.Sprintf("{%s, %s,", n.Name, argType))
+			if n.PluralOffset != 0 {
+				w.text(fmt.Sprintf(" offset:%d", n.PluralOffset))
+			}
+			w.options(n.Options)
+		case *icu.Select:
+			w.text(fmt.Sprintf("{%s, %s,", n.Name, icu.TypeSelect))
+			w.options(n.Options)
+		}
+	}
+}
+
+func (w *inlineWriter) options(options []*icu.Option) {
+	for _, o := range options {
+		w.text(" " + o.Selector + "{")
+		w.write(o.Value)
+		w.text("}")
+	}
+	w.text("}")
+}
+
+func (w *inlineWriter) text(s string) {
+	_ = xml.EscapeText(&w.sb, []byte(s))
+}
+
+func (w *inlineWriter) placeholder(n *icu.Argument) {
+	w.ids[n.Name]++
+	id := n.Name
+	if c := w.ids[n.Name]; c > 1 {
+		id = fmt.Sprintf("%s-%d", n.Name, c)
+	}
+
+	equiv := &strings.Builder{}
+	_ = xml.EscapeText(equiv, []byte(n.String()))
+	if w.version == Version20 {
+		fmt.Fprintf(&w.sb, `<ph id="%s" equiv="%s" disp="%s"/>`, id, equiv, equiv)
+		return
+	}
+	fmt.Fprintf(&w.sb, `<x id="%s" equiv-text="%s"/>`, id, equiv)
+}
+
+// fromInline converts inline content back to message. Placeholders are replaced by their equiv-text (equiv) attribute
+// or by {id}, markup of other inline elements is removed.
+func fromInline(content string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader("<c>" + content + "</c>"))
+	sb := &strings.Builder{}
+	// skip is depth of element which content is not a part of message (<bpt>, <ept>, <it>)
+	skip := 0
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := t.(type) {
+		case xml.CharData:
+			if skip == 0 {
+				sb.Write(t)
+			}
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+			switch t.Name.Local {
+			case "x", "ph":
+				sb.WriteString(placeholderText(t))
+				if err := dec.Skip(); err != nil {
+					return "", err
+				}
+			case "bpt", "ept", "it", "sc", "ec":
+				skip = 1
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+func placeholderText(e xml.StartElement) string {
+	id := ""
+	for _, a := range e.Attr {
+		switch a.Name.Local {
+		case "equiv-text", "equiv":
+			if strings.HasPrefix(a.Value, "{") {
+				return a.Value
+			}
+		case "id":
+			id = a.Value
+		}
+	}
+	// ids of repeated placeholders have suffix: name-2
+	if i := strings.LastIndex(id, "-"); i > 0 && isDigits(id[i+1:]) {
+		id = id[:i]
+	}
+	return "{" + id + "}"
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// innerXML returns raw content of element, it is used to keep inline markup of source and target.
+type innerXML struct {
+	Content string `xml:",innerxml"`
+}
+
+func (c *innerXML) isEmpty() bool {
+	return c == nil || strings.TrimSpace(c.Content) == ""
+}
@@ -0,0 +1,243 @@
+package xliff
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/icu"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+// Unit is a translation unit of xliff file with message converted back from inline content.
+type Unit struct {
+	ID     string
+	Source string
+	Target string
+}
+
+// Document is a content of xliff file of any version.
+type Document struct {
+	Version        Version
+	SourceLanguage string
+	TargetLanguage string
+	Units          []*Unit
+}
+
+// LoadXliff reads xliff 1.2 or 2.0 file, version is detected by root element.
+func LoadXliff(logger *logrus.Logger, filePath string) (*Document, error) {
+	logger.Tracef("load xliff file %s", filePath)
+	rawData, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := detectVersion(rawData)
+	if err != nil {
+		return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidXliff)
+	}
+
+	doc := &Document{Version: version}
+	dec := xml.NewDecoder(bytes.NewReader(rawData))
+	if version == Version20 {
+		dec.DefaultSpace = ns20
+		var x xliff20
+		if err := dec.Decode(&x); err != nil {
+			return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidXliff)
+		}
+		doc.SourceLanguage, doc.TargetLanguage = x.SrcLang, x.TrgLang
+		for _, u := range x.File.Units {
+			unit := &Unit{ID: u.ID}
+			for _, s := range u.Segments {
+				source, target, err := segmentContent(&s.Source, s.Target)
+				if err != nil {
+					return nil, fmt.Errorf("%s, unit %s (%v): %w", filePath, u.ID, err, ErrInvalidXliff)
+				}
+				unit.Source += source
+				unit.Target += target
+			}
+			doc.Units = append(doc.Units, unit)
+		}
+		return doc, nil
+	}
+
+	dec.DefaultSpace = ns12
+	var x xliff12
+	if err := dec.Decode(&x); err != nil {
+		return nil, fmt.Errorf("%s (%v): %w", filePath, err, ErrInvalidXliff)
+	}
+	doc.SourceLanguage, doc.TargetLanguage = x.File.SourceLanguage, x.File.TargetLanguage
+	for _, u := range x.File.Units {
+		var target *innerXML
+		if u.Target != nil {
+			target = &innerXML{Content: u.Target.Content}
+		}
+		source, targetText, err := segmentContent(&u.Source, target)
+		if err != nil {
+			return nil, fmt.Errorf("%s, unit %s (%v): %w", filePath, u.ID, err, ErrInvalidXliff)
+		}
+		doc.Units = append(doc.Units, &Unit{ID: u.ID, Source: source, Target: targetText})
+	}
+	return doc, nil
+}
+
+func detectVersion(rawData []byte) (Version, error) {
+	dec := xml.NewDecoder(bytes.NewReader(rawData))
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		e, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if e.Name.Local != "xliff" {
+			return "", fmt.Errorf("root element must be xliff, but found %s", e.Name.Local)
+		}
+		for _, a := range e.Attr {
+			if a.Name.Local == "version" {
+				return ParseVersion(a.Value)
+			}
+		}
+		return "", fmt.Errorf("version is not set")
+	}
+}
+
+func segmentContent(source, target *innerXML) (string, string, error) {
+	sourceText, err := fromInline(source.Content)
+	if err != nil {
+		return "", "", err
+	}
+	if target.isEmpty() {
+		return sourceText, "", nil
+	}
+	targetText, err := fromInline(target.Content)
+	if err != nil {
+		return "", "", err
+	}
+	return sourceText, targetText, nil
+}
+
+// MergeXliff reads xliff files matching template from folder and puts their translations to arb data.
+// Units must have ids of existing keys and targets must use the same placeholders as default culture message,
+// otherwise nothing is merged. Cultures are taken from target language of files, empty targets are skipped.
+func MergeXliff(logger *logrus.Logger, arbData *arb.Data, params Params) error {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
+	}
+
+	pattern := filepath.Join(params.FolderPath, filepath.FromSlash(strings.ReplaceAll(params.FileTemplate, cultureTemplate, "*")))
+	filePaths, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	if len(filePaths) == 0 {
+		return fmt.Errorf("no files match %s: %w", pattern, ErrInvalidXliff)
+	}
+
+	type translation struct {
+		key, culture, value string
+	}
+	var translations []translation
+	var issues []string
+	for _, filePath := range filePaths {
+		doc, err := LoadXliff(logger, filePath)
+		if err != nil {
+			return err
+		}
+
+		if c, err := locale.Canonical(doc.SourceLanguage); err != nil || c != defaultCulture {
+			issues = append(issues, fmt.Sprintf("%s: source language %s is not default culture %s", filePath, doc.SourceLanguage, defaultCulture))
+			continue
+		}
+		culture, err := locale.Canonical(doc.TargetLanguage)
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("%s: invalid target language %s (%v)", filePath, doc.TargetLanguage, err))
+			continue
+		}
+
+		for _, u := range doc.Units {
+			item, ok := arbData.Items[u.ID]
+			if !ok {
+				issues = append(issues, fmt.Sprintf("%s: unknown id %s", filePath, u.ID))
+				continue
+			}
+			if u.Target == "" {
+				continue
+			}
+			if issue := checkTarget(item.Cultures[defaultCulture], u.Target); issue != "" {
+				issues = append(issues, fmt.Sprintf("%s: id %s: %s", filePath, u.ID, issue))
+				continue
+			}
+			translations = append(translations, translation{key: u.ID, culture: culture, value: u.Target})
+		}
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%w, %d issue(s):\n%s", ErrInvalidXliff, len(issues), strings.Join(issues, "\n"))
+	}
+
+	cultures := make(map[string]struct{}, len(arbData.Cultures))
+	for _, cn := range arbData.Cultures {
+		cultures[cn] = struct{}{}
+	}
+	for _, t := range translations {
+		item := arbData.Items[t.key]
+		if item.Cultures == nil {
+			item.Cultures = make(map[string]string)
+		}
+		item.Cultures[t.culture] = t.value
+		if _, ok := cultures[t.culture]; !ok {
+			cultures[t.culture] = struct{}{}
+			arbData.Cultures = append(arbData.Cultures, t.culture)
+		}
+	}
+	arbData.Cultures = arbData.OrderedCultures(defaultCulture)
+	logger.Debugf("merged %d translation(s) from %d file(s)", len(translations), len(filePaths))
+	return nil
+}
+
+// checkTarget returns description of problem with target message or empty string.
+func checkTarget(source, target string) string {
+	targetMsg, err := icu.Parse(target)
+	if err != nil {
+		return fmt.Sprintf("invalid target %q (%v)", target, err)
+	}
+	sourceMsg, err := icu.Parse(source)
+	if err != nil {
+		return fmt.Sprintf("invalid source %q (%v)", source, err)
+	}
+
+	sourceArgs, targetArgs := sourceMsg.Arguments(), targetMsg.Arguments()
+	var missing, unexpected []string
+	for a := range sourceArgs {
+		if _, ok := targetArgs[a]; !ok {
+			missing = append(missing, "{"+a+"}")
+		}
+	}
+	for a := range targetArgs {
+		if _, ok := sourceArgs[a]; !ok {
+			unexpected = append(unexpected, "{"+a+"}")
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+
+	switch {
+	case len(missing) > 0 && len(unexpected) > 0:
+		return fmt.Sprintf("target misses placeholders %s and has unknown placeholders %s", strings.Join(missing, ", "), strings.Join(unexpected, ", "))
+	case len(missing) > 0:
+		return fmt.Sprintf("target misses placeholders %s", strings.Join(missing, ", "))
+	case len(unexpected) > 0:
+		return fmt.Sprintf("target has unknown placeholders %s", strings.Join(unexpected, ", "))
+	}
+	return ""
+}
@@ -0,0 +1,242 @@
+// Package xliff converts arb data to XLIFF 1.2 / 2.0 files for translation vendors and merges translated files back.
+package xliff
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/evg1605/csv_arb/arb/locale"
+	"github.com/sirupsen/logrus"
+)
+
+// Version is XLIFF version.
+type Version string
+
+const (
+	Version12 Version = "1.2"
+	Version20 Version = "2.0"
+
+	ns12 = "urn:oasis:names:tc:xliff:document:1.2"
+	ns20 = "urn:oasis:names:tc:xliff:document:2.0"
+
+	cultureTemplate = "{culture}"
+)
+
+// Target states.
+const (
+	state12New        = "new"
+	state12Translated = "translated"
+	state20Initial    = "initial"
+	state20Translated = "translated"
+)
+
+var (
+	ErrInvalidVersion = errors.New("invalid xliff version")
+	ErrInvalidXliff   = errors.New("invalid xliff")
+)
+
+type Params struct {
+	FolderPath     string
+	FileTemplate   string
+	DefaultCulture string
+	Version        Version
+	Order          arb.Order
+	LocaleStyle    locale.Style
+}
+
+func ParseVersion(s string) (Version, error) {
+	switch v := Version(s); v {
+	case Version12, Version20:
+		return v, nil
+	}
+	return "", fmt.Errorf("%s: %w", s, ErrInvalidVersion)
+}
+
+type xliff12 struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string   `xml:"version,attr"`
+	File    file12   `xml:"file"`
+}
+
+type file12 struct {
+	Original       string   `xml:"original,attr"`
+	SourceLanguage string   `xml:"source-language,attr"`
+	TargetLanguage string   `xml:"target-language,attr"`
+	Datatype       string   `xml:"datatype,attr"`
+	Units          []unit12 `xml:"body>trans-unit"`
+}
+
+type unit12 struct {
+	ID     string    `xml:"id,attr"`
+	Source innerXML  `xml:"source"`
+	Target *target12 `xml:"target"`
+	Notes  []string  `xml:"note"`
+}
+
+type target12 struct {
+	State   string `xml:"state,attr,omitempty"`
+	Content string `xml:",innerxml"`
+}
+
+type xliff20 struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string   `xml:"version,attr"`
+	SrcLang string   `xml:"srcLang,attr"`
+	TrgLang string   `xml:"trgLang,attr"`
+	File    file20   `xml:"file"`
+}
+
+type file20 struct {
+	ID    string   `xml:"id,attr"`
+	Units []unit20 `xml:"unit"`
+}
+
+type unit20 struct {
+	ID       string      `xml:"id,attr"`
+	Notes    *notes20    `xml:"notes"`
+	Segments []segment20 `xml:"segment"`
+}
+
+type notes20 struct {
+	Notes []string `xml:"note"`
+}
+
+type segment20 struct {
+	State  string    `xml:"state,attr,omitempty"`
+	Source innerXML  `xml:"source"`
+	Target *innerXML `xml:"target"`
+}
+
+// SaveXliff writes one xliff file per culture except default one, default culture is used as source language.
+func SaveXliff(logger *logrus.Logger, params Params, arbData *arb.Data) error {
+	defaultCulture, err := locale.Canonical(params.DefaultCulture)
+	if err != nil {
+		return fmt.Errorf("default culture: %w", err)
+	}
+	if _, err := ParseVersion(string(params.Version)); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(params.FolderPath, 0777); err != nil {
+		return err
+	}
+
+	keys := arbData.OrderedKeys(params.Order)
+	for _, cn := range arbData.Cultures {
+		if cn == defaultCulture {
+			continue
+		}
+
+		var doc interface{}
+		if params.Version == Version20 {
+			doc, err = createXliff20(logger, arbData, keys, defaultCulture, cn)
+		} else {
+			doc, err = createXliff12(logger, arbData, keys, defaultCulture, cn)
+		}
+		if err != nil {
+			return err
+		}
+
+		buf, err := xml.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fileName := strings.ReplaceAll(params.FileTemplate, cultureTemplate, locale.Format(cn, params.LocaleStyle))
+		filePath := filepath.Join(params.FolderPath, filepath.FromSlash(fileName))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+			return err
+		}
+		if err := arb.WriteFileIfChanged(logger, filePath, append([]byte(xml.Header), append(buf, '\n')...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createXliff12(logger *logrus.Logger, arbData *arb.Data, keys []string, defaultCulture, culture string) (*xliff12, error) {
+	doc := &xliff12{
+		Version: string(Version12),
+		File: file12{
+			Original:       "arb",
+			SourceLanguage: defaultCulture,
+			TargetLanguage: culture,
+			Datatype:       "plaintext",
+		},
+	}
+
+	for _, key := range keys {
+		item := arbData.Items[key]
+		source, target, err := unitContent(item, key, defaultCulture, culture, Version12)
+		if err != nil {
+			return nil, err
+		}
+		if source == "" {
+			logger.Debugf("skip key %s with empty source", key)
+			continue
+		}
+
+		u := unit12{
+			ID:     key,
+			Source: innerXML{Content: source},
+			Target: &target12{State: state12New},
+		}
+		if target != "" {
+			u.Target = &target12{State: state12Translated, Content: target}
+		}
+		if item.Description != "" {
+			u.Notes = []string{item.Description}
+		}
+		doc.File.Units = append(doc.File.Units, u)
+	}
+	return doc, nil
+}
+
+func createXliff20(logger *logrus.Logger, arbData *arb.Data, keys []string, defaultCulture, culture string) (*xliff20, error) {
+	doc := &xliff20{
+		Version: string(Version20),
+		SrcLang: defaultCulture,
+		TrgLang: culture,
+		File:    file20{ID: "arb"},
+	}
+
+	for _, key := range keys {
+		item := arbData.Items[key]
+		source, target, err := unitContent(item, key, defaultCulture, culture, Version20)
+		if err != nil {
+			return nil, err
+		}
+		if source == "" {
+			logger.Debugf("skip key %s with empty source", key)
+			continue
+		}
+
+		segment := segment20{State: state20Initial, Source: innerXML{Content: source}}
+		if target != "" {
+			segment.State = state20Translated
+			segment.Target = &innerXML{Content: target}
+		}
+		u := unit20{ID: key, Segments: []segment20{segment}}
+		if item.Description != "" {
+			u.Notes = &notes20{Notes: []string{item.Description}}
+		}
+		doc.File.Units = append(doc.File.Units, u)
+	}
+	return doc, nil
+}
+
+func unitContent(item *arb.Item, key, defaultCulture, culture string, version Version) (string, string, error) {
+	source, err := toInline(item.Cultures[defaultCulture], version)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid message %s for culture %s (%v): %w", key, defaultCulture, err, arb.ErrArbFile)
+	}
+	target, err := toInline(item.Cultures[culture], version)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid message %s for culture %s (%v): %w", key, culture, err, arb.ErrArbFile)
+	}
+	return source, target, nil
+}
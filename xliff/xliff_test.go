@@ -0,0 +1,159 @@
+package xliff
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/evg1605/csv_arb/arb"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func createData() *arb.Data {
+	return &arb.Data{
+		Cultures: []string{"en", "ru"},
+		Items: map[string]*arb.Item{
+			"hello": {
+				Description: "Greeting <b>",
+				Cultures:    map[string]string{"en": "Hello, {name}! {name}?", "ru": "Привет, {name}! {name}?"},
+				Parameters:  map[string]*arb.Placeholder{"name": {}},
+			},
+			"items": {Cultures: map[string]string{"en": "{count, plural, one{# item in {place}} other{# items in {place}}}", "ru": ""}},
+			"price": {Cultures: map[string]string{"en": "Price: {value, number, compactCurrency}"}},
+		},
+		Keys: []string{"hello", "items", "price"},
+	}
+}
+
+func TestToInline(t *testing.T) {
+	s, err := toInline("A & {name} {n, number}", Version12)
+	require.NoError(t, err)
+	require.Equal(t, `A &amp; <x id="name" equiv-text="{name}"/> <x id="n" equiv-text="{n, number}"/>`, s)
+
+	s, err = toInline("{name}{name}", Version20)
+	require.NoError(t, err)
+	require.Equal(t, `<ph id="name" equiv="{name}" disp="{name}"/><ph id="name-2" equiv="{name}" disp="{name}"/>`, s)
+
+	msg, err := fromInline(`<g id="1">Hi</g> <x id="name-2"/> <ph id="n" equiv="{n, number}"/><bpt id="b">&lt;b&gt;</bpt>!`)
+	require.NoError(t, err)
+	require.Equal(t, "Hi {name} {n, number}!", msg)
+}
+
+func TestSaveAndMergeXliff(t *testing.T) {
+	for _, version := range []Version{Version12, Version20} {
+		t.Run(string(version), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "xliff")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			params := Params{
+				FolderPath:     dir,
+				FileTemplate:   "app_{culture}.xlf",
+				DefaultCulture: "en",
+				Version:        version,
+			}
+			arbData := createData()
+			require.NoError(t, SaveXliff(createLogger(), params, arbData))
+			require.NoFileExists(t, path.Join(dir, "app_en.xlf"))
+
+			doc, err := LoadXliff(createLogger(), path.Join(dir, "app_ru.xlf"))
+			require.NoError(t, err)
+			require.Equal(t, version, doc.Version)
+			require.Equal(t, "en", doc.SourceLanguage)
+			require.Equal(t, "ru", doc.TargetLanguage)
+			require.Equal(t, []*Unit{
+				{ID: "hello", Source: "Hello, {name}! {name}?", Target: "Привет, {name}! {name}?"},
+				{ID: "items", Source: "{count, plural, one{# item in {place}} other{# items in {place}}}"},
+				{ID: "price", Source: "Price: {value, number, compactCurrency}"},
+			}, doc.Units)
+
+			// vendor translates file
+			rawData, err := ioutil.ReadFile(path.Join(dir, "app_ru.xlf"))
+			require.NoError(t, err)
+			require.Contains(t, string(rawData), "Greeting &lt;b&gt;")
+			require.NoError(t, ioutil.WriteFile(path.Join(dir, "app_de.xlf"), []byte(translatedDe[version]), 0644))
+			require.NoError(t, os.Remove(path.Join(dir, "app_ru.xlf")))
+
+			require.NoError(t, MergeXliff(createLogger(), arbData, params))
+			require.Equal(t, []string{"en", "de", "ru"}, arbData.Cultures)
+			require.Equal(t, "Hallo, {name}! {name}?", arbData.Items["hello"].Cultures["de"])
+			require.Equal(t, "Preis: {value, number, compactCurrency}", arbData.Items["price"].Cultures["de"])
+			require.NotContains(t, arbData.Items["items"].Cultures, "de")
+
+			require.NoError(t, ioutil.WriteFile(path.Join(dir, "app_de.xlf"), []byte(invalidDe[version]), 0644))
+			err = MergeXliff(createLogger(), createData(), params)
+			require.ErrorIs(t, err, ErrInvalidXliff)
+			require.Contains(t, err.Error(), "unknown id missing")
+			require.Contains(t, err.Error(), "target misses placeholders {name}")
+		})
+	}
+}
+
+var translatedDe = map[Version]string{
+	Version12: `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">
+  <file original="arb" source-language="en" target-language="de" datatype="plaintext">
+    <body>
+      <trans-unit id="hello">
+        <source>Hello, <x id="name" equiv-text="{name}"/>! <x id="name-2" equiv-text="{name}"/>?</source>
+        <target state="translated">Hallo, <x id="name" equiv-text="{name}"/>! <x id="name-2"/>?</target>
+      </trans-unit>
+      <trans-unit id="items">
+        <source>{count, plural, one{# item in <x id="place" equiv-text="{place}"/>} other{# items in <x id="place-2" equiv-text="{place}"/>}}</source>
+        <target state="new"></target>
+      </trans-unit>
+      <trans-unit id="price">
+        <source>Price: <x id="value" equiv-text="{value, number, compactCurrency}"/></source>
+        <target>Preis: <x id="value" equiv-text="{value, number, compactCurrency}"/></target>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>`,
+	Version20: `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="de">
+  <file id="arb">
+    <unit id="hello">
+      <segment state="translated">
+        <source>Hello, <ph id="name" equiv="{name}" disp="{name}"/>! <ph id="name-2" equiv="{name}" disp="{name}"/>?</source>
+        <target>Hallo, <ph id="name"/>! <ph id="name-2"/>?</target>
+      </segment>
+    </unit>
+    <unit id="items">
+      <segment state="initial">
+        <source>{count, plural, one{# item} other{# items}}</source>
+      </segment>
+    </unit>
+    <unit id="price">
+      <segment>
+        <source>Price: <ph id="value" equiv="{value, number, compactCurrency}"/></source>
+        <target>Preis: <ph id="value" equiv="{value, number, compactCurrency}"/></target>
+      </segment>
+    </unit>
+  </file>
+</xliff>`,
+}
+
+var invalidDe = map[Version]string{
+	Version12: `<xliff version="1.2">
+  <file source-language="en" target-language="de">
+    <body>
+      <trans-unit id="hello"><source>Hello</source><target>Hallo</target></trans-unit>
+      <trans-unit id="missing"><source>Hello</source><target>Hallo</target></trans-unit>
+    </body>
+  </file>
+</xliff>`,
+	Version20: `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="de">
+  <file id="arb">
+    <unit id="hello"><segment><source>Hello</source><target>Hallo</target></segment></unit>
+    <unit id="missing"><segment><source>Hello</source><target>Hallo</target></segment></unit>
+  </file>
+</xliff>`,
+}
+
+func createLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.TraceLevel)
+	return logger
+}